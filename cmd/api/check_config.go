@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database/migration"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+)
+
+// checkConfig validates the environment without serving anything, so a
+// deployment can be checked before it is rolled out. It prints the result of
+// every check and returns the exit code
+func checkConfig(ctx context.Context, local bool) int {
+	failed := false
+
+	check := func(name string, err error) bool {
+		if err != nil {
+			failed = true
+			fmt.Printf("✗ %s: %v\n", name, err)
+			return false
+		}
+
+		fmt.Printf("✓ %s\n", name)
+		return true
+	}
+
+	config, err := loadEnvironment(ctx, local)
+	if !check("environment", err) {
+		return 1
+	}
+
+	check("money format", common.SetMoneyFormat(config.ApiConfig.MoneyFormat))
+
+	_, err = process.ParsePaymentRules(config.OrderConfig.PaymentRules)
+	check("payment rules", err)
+
+	if !config.BrokerConfig.IsKnownDriver() {
+		err = fmt.Errorf("unknown broker driver: %s", config.BrokerConfig.Driver)
+	}
+	check("broker driver", err)
+
+	_, err = cloud.ParseSubscriptions(config.BrokerConfig.Subscriptions)
+	check("broker subscriptions", err)
+
+	err = nil
+	if !config.TracingConfig.IsKnownExporter() {
+		err = fmt.Errorf("unknown tracing exporter: %s", config.TracingConfig.Exporter)
+	}
+	check("tracing exporter", err)
+
+	secret, err := newSecretService(ctx, config)
+	if check("secret providers", err) {
+		config.DbConfig.Url, err = secret.GetSecret(ctx, config.DbConfig.UrlSecretName)
+		check("database url secret", err)
+	}
+
+	if config.DbConfig.Url != "" {
+		db := database.NewDatabase(config)
+		defer db.GetInstance().Close()
+
+		err = nil
+		if status := db.Health(); status.HasError() {
+			err = errors.New(status.Err)
+		}
+
+		if check("database connection", err) {
+			pending, err := pendingMigrations(ctx, db)
+			check("migrations", err)
+
+			if pending > 0 {
+				fmt.Printf("  %d migration(s) pending\n", pending)
+			}
+		}
+	}
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+func pendingMigrations(ctx context.Context, db database.DatabaseService) (int, error) {
+	migrator, err := migration.NewMigrator(db.GetInstance())
+	if err != nil {
+		return 0, err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
@@ -2,24 +2,40 @@ package main
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/environment/loader"
-	"github.com/jfelipearaujo-org/ms-order-management/internal/server"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/logger"
 )
 
+const usage = `usage: api [local] <command>
+
+commands:
+  serve-all     serve the api and run the worker (default)
+  serve-api     serve the api only
+  serve-worker  run the worker only: queue consumer, outbox relay, webhooks and purges
+  migrate       up | down [steps] | status | create <name>
+  check-config  validate the environment and the connection to the database
+
+local loads the environment from the .env file`
+
+const (
+	commandServeAll    = "serve-all"
+	commandServeApi    = "serve-api"
+	commandServeWorker = "serve-worker"
+	commandMigrate     = "migrate"
+	commandCheckConfig = "check-config"
+)
+
 func init() {
 	var err error
 	time.Local, err = time.LoadLocation("America/Sao_Paulo")
@@ -31,18 +47,35 @@ func init() {
 func main() {
 	ctx := context.Background()
 
-	loader := loader.NewLoader()
+	args := os.Args[1:]
+	local := len(args) > 0 && args[0] == "local"
+	if local {
+		args = args[1:]
+	}
 
-	var config *environment.Config
-	var err error
+	command := commandServeAll
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
 
-	if len(os.Args) > 1 && os.Args[1] == "local" {
-		slog.Info("loading environment from .env file")
-		config, err = loader.GetEnvironmentFromFile(ctx, ".env")
-	} else {
-		config, err = loader.GetEnvironment(ctx)
+	switch command {
+	case commandServeAll, commandServeApi, commandServeWorker, commandMigrate:
+	case commandCheckConfig:
+		os.Exit(checkConfig(ctx, local))
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
 	}
 
+	if command == commandMigrate && len(args) > 0 && args[0] == "create" {
+		if err := createMigration(args[1:]); err != nil {
+			slog.Error("error creating migration", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	config, err := loadEnvironment(ctx, local)
 	if err != nil {
 		slog.Error("error loading environment", "error", err)
 		panic(err)
@@ -50,17 +83,17 @@ func main() {
 
 	logger.SetupLog(config)
 
-	cloudConfig, err := awsConfig.LoadDefaultConfig(ctx)
-	if err != nil {
+	if err := common.SetMoneyFormat(config.ApiConfig.MoneyFormat); err != nil {
+		slog.Error("error setting the money format", "error", err)
 		panic(err)
 	}
 
-	if config.CloudConfig.IsBaseEndpointSet() {
-		cloudConfig.BaseEndpoint = aws.String(config.CloudConfig.BaseEndpoint)
+	secret, err := newSecretService(ctx, config)
+	if err != nil {
+		slog.Error("error creating the secret providers", "error", err)
+		panic(err)
 	}
 
-	secret := cloud.NewSecretService(cloudConfig)
-
 	dbUrl, err := secret.GetSecret(ctx, config.DbConfig.UrlSecretName)
 	if err != nil {
 		slog.Error("error getting secret", "secret_name", config.DbConfig.UrlSecretName, "error", err)
@@ -69,44 +102,45 @@ func main() {
 
 	config.DbConfig.Url = dbUrl
 
-	server := server.NewServer(config)
-
-	if err := server.TopicService.UpdateTopicArn(ctx); err != nil {
-		slog.Error("error updating topic arn", "error", err)
-		panic(err)
-	}
-
-	if err := server.QueueService.UpdateQueueUrl(ctx); err != nil {
-		slog.Error("error updating queue url", "error", err)
-		panic(err)
-	}
-
-	go func(ctx context.Context) {
-		for {
-			server.QueueService.ConsumeMessages(ctx)
+	switch command {
+	case commandMigrate:
+		if err := migrate(ctx, config, args); err != nil {
+			slog.Error("error running migrations", "error", err)
+			os.Exit(1)
 		}
-	}(ctx)
+	case commandServeApi:
+		serve(config, secret, true, false)
+	case commandServeWorker:
+		serve(config, secret, false, true)
+	default:
+		serve(config, secret, true, true)
+	}
+}
 
-	httpServer := server.GetHttpServer()
+func loadEnvironment(ctx context.Context, local bool) (*environment.Config, error) {
+	loader := loader.NewLoader()
 
-	go func() {
-		slog.Info("🚀 Server started", "address", httpServer.Addr)
-		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("http server error", "error", err)
-			panic(err)
-		}
-		slog.Info("http server stopped serving requests")
-	}()
+	if local {
+		slog.Info("loading environment from .env file")
+		return loader.GetEnvironmentFromFile(ctx, ".env")
+	}
 
-	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	<-sc
+	return loader.GetEnvironment(ctx)
+}
 
-	ctx, shutdown := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdown()
+func newSecretService(ctx context.Context, config *environment.Config) (cloud.SecretService, error) {
+	cloudConfig, err := awsConfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := httpServer.Shutdown(ctx); err != nil {
-		slog.Error("error while trying to shutdown the server", "error", err)
+	if config.CloudConfig.IsBaseEndpointSet() {
+		cloudConfig.BaseEndpoint = aws.String(config.CloudConfig.BaseEndpoint)
 	}
-	slog.Info("graceful shutdown completed ✅")
+
+	return cloud.NewSecretProviderChain(
+		config.SecretConfig.Providers,
+		map[string]string{config.DbConfig.UrlSecretName: config.DbConfig.Url},
+		config.SecretConfig.Directory,
+		cloudConfig)
 }
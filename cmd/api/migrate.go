@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database/migration"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | create <name>"
+
+// createMigration handles `migrate create <name>`, it only writes files so it
+// runs before the environment is loaded
+func createMigration(args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	paths, err := migration.Create(migration.Directory, args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		fmt.Println("created", path)
+	}
+
+	return nil
+}
+
+// migrate handles `migrate up | down [steps] | status` against the database
+// of the environment
+func migrate(ctx context.Context, config *environment.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db := database.NewDatabase(config).GetInstance()
+	defer db.Close()
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d migration(s) applied\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d migration(s) reverted\n", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(writer, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return writer.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database/migration"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/server"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/lifecycle"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracing"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+)
+
+// serve runs the api, the worker or both until a termination signal, each one
+// with its own health check endpoint. The shutdown goes in phases: stop
+// serving requests, stop consuming and drain the messages in flight, stop the
+// background loops, publish what is left in the outbox, close the database and
+// export the spans left. The drain has its own timeout, the other phases share
+// the shutdown one
+func serve(config *environment.Config, secret cloud.SecretService, api bool, worker bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, config)
+	if err != nil {
+		slog.Error("error setting up tracing", "error", err)
+		panic(err)
+	}
+
+	server := server.NewServer(config)
+
+	if config.DbConfig.AutoMigrate {
+		migrator, err := migration.NewMigrator(server.DatabaseService.GetInstance())
+		if err != nil {
+			slog.Error("error loading migrations", "error", err)
+			panic(err)
+		}
+
+		if _, err := migrator.Up(ctx); err != nil {
+			slog.Error("error applying migrations", "error", err)
+			panic(err)
+		}
+	}
+
+	manager := lifecycle.NewManager(config.ApiConfig.ShutdownTimeout)
+	loops := lifecycle.NewGroup(context.Background())
+
+	if config.SecretConfig.RefreshInterval > 0 {
+		loops.Loop("secret refresh", config.SecretConfig.RefreshInterval, func(ctx context.Context) bool {
+			dbUrl, err := secret.GetSecret(ctx, config.DbConfig.UrlSecretName)
+			if err != nil {
+				slog.ErrorContext(ctx, "error refreshing secret", "secret_name", config.DbConfig.UrlSecretName, "error", err)
+				return false
+			}
+
+			server.DatabaseService.UpdateUrl(dbUrl)
+
+			return false
+		})
+	}
+
+	var httpServers []*http.Server
+
+	if api {
+		startApi(config, server, loops)
+
+		// the tracking streams only end when their subscription is closed
+		httpServer := server.GetHttpServer()
+		httpServer.RegisterOnShutdown(server.TrackingHub.Close)
+
+		httpServers = append(httpServers, httpServer)
+	}
+
+	var consumer *lifecycle.Group
+
+	if worker {
+		consumer = startWorker(config, server, loops)
+		httpServers = append(httpServers, server.GetWorkerHttpServer())
+	}
+
+	for _, httpServer := range httpServers {
+		go func(httpServer *http.Server) {
+			slog.Info("🚀 Server started", "address", httpServer.Addr)
+			if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("http server error", "error", err)
+				panic(err)
+			}
+			slog.Info("http server stopped serving requests", "address", httpServer.Addr)
+		}(httpServer)
+	}
+
+	manager.OnShutdown("http servers", func(ctx context.Context) error {
+		var errs []error
+		for _, httpServer := range httpServers {
+			errs = append(errs, httpServer.Shutdown(ctx))
+		}
+
+		return errors.Join(errs...)
+	})
+
+	if worker {
+		manager.OnShutdownWithin("queue consumer", config.WorkerConfig.DrainTimeout, func(ctx context.Context) error {
+			if err := consumer.Stop(ctx); err != nil {
+				return err
+			}
+
+			return lifecycle.Wait(ctx, server.QueueService.Wait)
+		})
+	}
+
+	manager.OnShutdown("background loops", loops.Stop)
+
+	if worker {
+		manager.OnShutdown("outbox relay flush", func(ctx context.Context) error {
+			return flushOutbox(ctx, config, server)
+		})
+	}
+
+	manager.OnShutdown("database", func(ctx context.Context) error {
+		return server.DatabaseService.GetInstance().Close()
+	})
+
+	manager.OnShutdown("tracing", shutdownTracing)
+
+	<-ctx.Done()
+
+	// a second signal stops the process right away
+	stop()
+
+	if err := manager.Shutdown(context.Background()); err != nil {
+		slog.Error("graceful shutdown completed with errors", "error", err)
+		return
+	}
+
+	slog.Info("graceful shutdown completed ✅")
+}
+
+// startApi starts what the api needs besides the http server: the listener
+// feeding the tracking subscribers
+func startApi(config *environment.Config, server *server.Server, loops *lifecycle.Group) {
+	if !config.TrackingConfig.NotifyEnabled {
+		return
+	}
+
+	listener := tracking.NewPostgresListener(server.DatabaseService.GetUrl, config.TrackingConfig.Channel, server.TrackingHub)
+
+	loops.Go("tracking listener", func(ctx context.Context) {
+		if err := listener.Run(ctx); err != nil {
+			slog.ErrorContext(ctx, "error listening to tracking events", "error", err)
+		}
+	})
+}
+
+// startWorker starts the queue consumer in its own group, returned so it can
+// be stopped before the background loops, which are added to loops
+func startWorker(config *environment.Config, server *server.Server, loops *lifecycle.Group) *lifecycle.Group {
+	ctx := context.Background()
+
+	if err := server.TopicService.UpdateTopicArn(ctx); err != nil {
+		slog.Error("error updating topic arn", "error", err)
+		panic(err)
+	}
+
+	if err := server.EventTopicService.UpdateTopicArn(ctx); err != nil {
+		slog.Error("error updating events topic arn", "error", err)
+		panic(err)
+	}
+
+	if err := server.QueueService.UpdateQueueUrl(ctx); err != nil {
+		slog.Error("error updating queue url", "error", err)
+		panic(err)
+	}
+
+	// without an allowlist only the payment responses are trusted
+	if topic := server.PaymentResponseTopic; topic != nil {
+		if err := topic.UpdateTopicArn(ctx); err != nil {
+			slog.Error("error updating payment response topic arn", "error", err)
+			panic(err)
+		}
+
+		server.NotificationVerifier.AllowTopicArn(topic.TopicArn)
+	}
+
+	consumer := lifecycle.NewGroup(ctx)
+
+	// the long poll is cancelled by the stop, the messages already received
+	// are still handled to the end by the worker pool
+	consumer.Go("queue consumer", func(ctx context.Context) {
+		for ctx.Err() == nil {
+			server.QueueService.ConsumeMessages(ctx)
+		}
+	})
+
+	loops.Loop("outbox relay", config.OutboxConfig.PollInterval, func(ctx context.Context) bool {
+		sent, err := server.Dependency.RelayOutboxService.Handle(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "error relaying outbox messages", "error", err)
+		}
+
+		return err == nil && sent >= config.OutboxConfig.BatchSize
+	})
+
+	loops.Loop("webhook dispatcher", config.WebhookConfig.PollInterval, func(ctx context.Context) bool {
+		sent, err := server.Dependency.DispatchWebhooksService.Handle(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "error dispatching webhooks", "error", err)
+		}
+
+		return err == nil && sent >= config.WebhookConfig.BatchSize
+	})
+
+	loops.Loop("inbox purge", config.InboxConfig.PurgeInterval, func(ctx context.Context) bool {
+		if _, err := server.Dependency.PurgeInboxService.Handle(ctx); err != nil {
+			slog.ErrorContext(ctx, "error purging inbox", "error", err)
+		}
+
+		return false
+	})
+
+	loops.Loop("idempotency keys purge", config.IdempotencyConfig.PurgeInterval, func(ctx context.Context) bool {
+		if _, err := server.Dependency.PurgeIdempotencyKeysService.Handle(ctx); err != nil {
+			slog.ErrorContext(ctx, "error purging idempotency keys", "error", err)
+		}
+
+		return false
+	})
+
+	return consumer
+}
+
+// flushOutbox publishes the outbox messages stored by the last messages
+// handled, until a batch comes out short
+func flushOutbox(ctx context.Context, config *environment.Config, server *server.Server) error {
+	for {
+		sent, err := server.Dependency.RelayOutboxService.Handle(ctx)
+		if err != nil {
+			return err
+		}
+
+		slog.InfoContext(ctx, "outbox messages flushed", "sent", sent)
+
+		if sent < config.OutboxConfig.BatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	CloudEventsContentType = "application/json"
+)
+
+// CloudEvent is the structured content mode of a CloudEvents 1.0 event, with
+// the attributes and the JSON data in the same document
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	Id              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+type CloudEventsOptions struct {
+	Enabled bool
+	Source  string
+}
+
+// Event is a message with the attributes used to build its envelope, the
+// topics publish only the data when the envelope is disabled
+type Event struct {
+	Id      string
+	Type    string
+	Subject string
+	Time    time.Time
+	Data    interface{}
+}
+
+func NewCloudEvent(source string, event Event) (CloudEvent, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+
+	return CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		Id:              event.Id,
+		Source:          source,
+		Type:            event.Type,
+		Subject:         event.Subject,
+		Time:            event.Time.UTC(),
+		DataContentType: CloudEventsContentType,
+		Data:            data,
+	}, nil
+}
+
+// ParseCloudEvent decodes a structured mode event, only JSON data is supported
+func ParseCloudEvent(body []byte) (CloudEvent, error) {
+	var event CloudEvent
+
+	if err := json.Unmarshal(body, &event); err != nil {
+		return event, err
+	}
+
+	if event.SpecVersion != CloudEventsSpecVersion {
+		return event, fmt.Errorf("cloudevents spec version %q is not supported", event.SpecVersion)
+	}
+
+	if event.Id == "" || event.Source == "" || event.Type == "" {
+		return event, fmt.Errorf("cloudevent is missing a required attribute")
+	}
+
+	if event.DataContentType != "" && event.DataContentType != CloudEventsContentType {
+		return event, fmt.Errorf("cloudevent data content type %q is not supported", event.DataContentType)
+	}
+
+	if len(event.Data) == 0 {
+		return event, fmt.Errorf("cloudevent has no data")
+	}
+
+	return event, nil
+}
@@ -0,0 +1,115 @@
+package cloud
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	t.Run("Should build the envelope with the event attributes", func(t *testing.T) {
+		// Arrange
+		now := time.Date(2024, 5, 19, 2, 1, 36, 0, time.FixedZone("BRT", -3*60*60))
+
+		event := Event{
+			Id:      "event-id",
+			Type:    "OrderCreated",
+			Subject: "order-id",
+			Time:    now,
+			Data:    json.RawMessage(`{"order_id":"order-id"}`),
+		}
+
+		// Act
+		res, err := NewCloudEvent("ms-order-management", event)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, CloudEvent{
+			SpecVersion:     "1.0",
+			Id:              "event-id",
+			Source:          "ms-order-management",
+			Type:            "OrderCreated",
+			Subject:         "order-id",
+			Time:            now.UTC(),
+			DataContentType: "application/json",
+			Data:            json.RawMessage(`{"order_id":"order-id"}`),
+		}, res)
+	})
+
+	t.Run("Should return an error when the data can not be marshalled", func(t *testing.T) {
+		// Arrange
+		event := Event{
+			Id:   "event-id",
+			Type: "OrderCreated",
+			Data: make(chan int),
+		}
+
+		// Act
+		_, err := NewCloudEvent("ms-order-management", event)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestParseCloudEvent(t *testing.T) {
+	t.Run("Should parse a structured mode event", func(t *testing.T) {
+		// Arrange
+		body := `{"specversion":"1.0","id":"event-id","source":"ms-payment","type":"PaymentApproved","subject":"order-id","time":"2024-05-19T02:01:36Z","datacontenttype":"application/json","data":{"order_id":"order-id"}}`
+
+		// Act
+		res, err := ParseCloudEvent([]byte(body))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "event-id", res.Id)
+		assert.Equal(t, "order-id", res.Subject)
+		assert.JSONEq(t, `{"order_id":"order-id"}`, string(res.Data))
+	})
+
+	t.Run("Should return an error when the spec version is not supported", func(t *testing.T) {
+		// Arrange
+		body := `{"specversion":"0.3","id":"event-id","source":"ms-payment","type":"PaymentApproved","data":{}}`
+
+		// Act
+		_, err := ParseCloudEvent([]byte(body))
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return an error when a required attribute is missing", func(t *testing.T) {
+		// Arrange
+		body := `{"specversion":"1.0","id":"event-id","type":"PaymentApproved","data":{}}`
+
+		// Act
+		_, err := ParseCloudEvent([]byte(body))
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return an error when the data is not json", func(t *testing.T) {
+		// Arrange
+		body := `{"specversion":"1.0","id":"event-id","source":"ms-payment","type":"PaymentApproved","datacontenttype":"application/xml","data":"<order/>"}`
+
+		// Act
+		_, err := ParseCloudEvent([]byte(body))
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return an error when there is no data", func(t *testing.T) {
+		// Arrange
+		body := `{"specversion":"1.0","id":"event-id","source":"ms-payment","type":"PaymentApproved"}`
+
+		// Act
+		_, err := ParseCloudEvent([]byte(body))
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,265 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/audit"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracing"
+)
+
+// errNoDeadLetterQueue is returned by a settler that has nowhere to move the
+// failed messages to, they are discarded instead
+var errNoDeadLetterQueue = errors.New("no dead-letter queue configured")
+
+type QueueStats struct {
+	Processed    int64
+	Retried      int64
+	DeadLettered int64
+}
+
+// Delivery is a message received from a queue, the handle identifies it on
+// the broker when it is settled (e.g. the SQS receipt handle)
+type Delivery struct {
+	Id           string
+	Body         string
+	Attributes   map[string]string
+	ReceiveCount int
+	Handle       string
+}
+
+// settler applies the outcome of a delivery on the broker it came from
+type settler interface {
+	ack(ctx context.Context, delivery Delivery) error
+	retry(ctx context.Context, delivery Delivery, delay time.Duration) error
+	deadLetter(ctx context.Context, delivery Delivery, failure Failure) error
+}
+
+// Consumer holds what every queue implementation shares: decoding, signature
+// verification, processing and the retry or dead-letter decision
+type Consumer struct {
+	System              string
+	QueueName           string
+	DeadLetterQueueName string
+
+	RetryPolicy      RetryPolicy
+	Verifier         NotificationVerifier
+	MessageProcessor service.ProcessMessageService[process.ProcessMessageDto]
+
+	WorkerPool *WorkerPool
+
+	settler settler
+
+	processed    atomic.Int64
+	retried      atomic.Int64
+	deadLettered atomic.Int64
+}
+
+func newConsumer(
+	system string,
+	queueName string,
+	deadLetterQueueName string,
+	retryPolicy RetryPolicy,
+	concurrency int,
+	verifier NotificationVerifier,
+	messageProcessor service.ProcessMessageService[process.ProcessMessageDto],
+	settler settler,
+) *Consumer {
+	return &Consumer{
+		System:              system,
+		QueueName:           queueName,
+		DeadLetterQueueName: deadLetterQueueName,
+
+		RetryPolicy:      retryPolicy,
+		Verifier:         verifier,
+		MessageProcessor: messageProcessor,
+
+		WorkerPool: NewWorkerPool(concurrency),
+
+		settler: settler,
+	}
+}
+
+func (c *Consumer) GetQueueName() string {
+	return c.QueueName
+}
+
+func (c *Consumer) GetStats() QueueStats {
+	return QueueStats{
+		Processed:    c.processed.Load(),
+		Retried:      c.retried.Load(),
+		DeadLettered: c.deadLettered.Load(),
+	}
+}
+
+// Wait blocks until every message already received has been handled
+func (c *Consumer) Wait() {
+	c.WorkerPool.Wait()
+}
+
+// dispatch hands the deliveries to the worker pool, a received message is
+// handled to the end even when ctx is cancelled to stop the consumer
+func (c *Consumer) dispatch(ctx context.Context, deliveries []Delivery) {
+	ctx = context.WithoutCancel(ctx)
+
+	for _, delivery := range deliveries {
+		envelope, notification, request, err := decodeMessage(delivery)
+
+		// messages of the same order are handled in the order they were received,
+		// the ones that can not be decoded have no order to wait for
+		key := request.OrderId
+		if err != nil || key == "" {
+			key = delivery.Id
+		}
+
+		c.WorkerPool.Submit(key, func() {
+			c.processMessage(ctx, delivery, envelope, notification, request, err)
+		})
+	}
+}
+
+func (c *Consumer) processMessage(
+	ctx context.Context,
+	delivery Delivery,
+	envelope Envelope,
+	notification TopicNotification,
+	request process.ProcessMessageDto,
+	decodeErr error,
+) {
+	// the span continues the trace of the publisher, found in the attributes
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, traceAttributes(delivery, notification)), c.QueueName+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(c.System),
+			semconv.MessagingDestinationName(c.QueueName),
+			semconv.MessagingOperationDeliver,
+			semconv.MessagingMessageID(delivery.Id),
+		))
+	defer span.End()
+
+	ctx = audit.WithOrigin(ctx, audit.Origin{
+		Actor:    c.QueueName,
+		Source:   audit.SourceQueue,
+		SourceId: delivery.Id,
+	})
+
+	slog.InfoContext(ctx, "message received", "message_id", delivery.Id, "receive_count", delivery.ReceiveCount)
+
+	if decodeErr != nil {
+		c.handleFailure(ctx, delivery, decodeErr)
+		return
+	}
+
+	if c.Verifier != nil {
+		// with the signatures verified, the CloudEvents and raw deliveries are not
+		// trusted since they carry none, they must arrive wrapped by SNS instead
+		if !envelope.Signed() {
+			c.handleFailure(ctx, delivery, NewPermanentFailure("unsigned message", fmt.Errorf("%w: %s messages carry no signature", custom_error.ErrQueueMessageNotSigned, envelope)))
+			return
+		}
+
+		if err := c.Verifier.Verify(ctx, notification); err != nil {
+			// only a rejected message is permanent, a signing certificate that can
+			// not be fetched right now is retried as any other failure
+			switch {
+			case errors.Is(err, custom_error.ErrQueueMessageTopicNotAllowed):
+				err = NewPermanentFailure("topic not allowed", err)
+			case errors.Is(err, custom_error.ErrQueueMessageSignatureNotValid):
+				err = NewPermanentFailure("invalid signature", err)
+			}
+
+			c.handleFailure(ctx, delivery, err)
+			return
+		}
+	}
+
+	slog.InfoContext(ctx, "message unmarshalled", "request", request)
+
+	if err := c.MessageProcessor.Handle(ctx, request); err != nil {
+		c.handleFailure(ctx, delivery, err)
+		return
+	}
+
+	c.processed.Add(1)
+
+	if err := c.settler.ack(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "error acknowledging message", "message_id", delivery.Id, "error", err)
+	}
+}
+
+// handleFailure leaves a transient failure on the queue to be redelivered after
+// a backoff, and moves the message to the dead-letter queue otherwise
+func (c *Consumer) handleFailure(ctx context.Context, delivery Delivery, err error) {
+	failure := ClassifyFailure(err)
+
+	tracing.RecordError(trace.SpanFromContext(ctx), err)
+
+	if failure.Class == FailureTransient && c.RetryPolicy.ShouldRetry(delivery.ReceiveCount) {
+		delay := c.RetryPolicy.Backoff(delivery.ReceiveCount)
+
+		slog.WarnContext(ctx, "error processing message, it will be retried",
+			"message_id", delivery.Id,
+			"failure_class", failure.Class,
+			"reason", failure.Reason,
+			"receive_count", delivery.ReceiveCount,
+			"retry_in", delay,
+			"error", err)
+
+		c.retried.Add(1)
+
+		if err := c.settler.retry(ctx, delivery, delay); err != nil {
+			slog.ErrorContext(ctx, "error scheduling message retry", "message_id", delivery.Id, "error", err)
+		}
+
+		return
+	}
+
+	if failure.Class == FailureTransient {
+		failure.Reason = "max receives exceeded"
+	}
+
+	if errDeadLetter := c.settler.deadLetter(ctx, delivery, failure); errDeadLetter != nil {
+		if !errors.Is(errDeadLetter, errNoDeadLetterQueue) {
+			slog.ErrorContext(ctx, "error sending message to the dead-letter queue", "message_id", delivery.Id, "error", errDeadLetter)
+			return
+		}
+
+		slog.ErrorContext(ctx, "error processing message, it was discarded as no dead-letter queue is configured",
+			"message_id", delivery.Id,
+			"failure_class", failure.Class,
+			"reason", failure.Reason,
+			"receive_count", delivery.ReceiveCount,
+			"error", err)
+
+		if err := c.settler.ack(ctx, delivery); err != nil {
+			slog.ErrorContext(ctx, "error acknowledging message", "message_id", delivery.Id, "error", err)
+		}
+
+		return
+	}
+
+	deadLettered := c.deadLettered.Add(1)
+
+	slog.ErrorContext(ctx, "error processing message, it was dead-lettered",
+		"message_id", delivery.Id,
+		"failure_class", failure.Class,
+		"reason", failure.Reason,
+		"receive_count", delivery.ReceiveCount,
+		"dead_letter_queue", c.DeadLetterQueueName,
+		"dead_lettered_total", deadLettered,
+		"error", err)
+
+	if err := c.settler.ack(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "error acknowledging message", "message_id", delivery.Id, "error", err)
+	}
+}
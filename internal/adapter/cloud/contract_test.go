@@ -0,0 +1,309 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// topicFixture adapts a TopicService implementation to the contract below, the
+// expectations are set before acting so the AWS one can stub its calls
+type topicFixture interface {
+	service(t *testing.T) TopicService
+	expectPublish(t *testing.T, body string)
+	verify(t *testing.T)
+}
+
+// queueFixture adapts a QueueService implementation to the contract below, the
+// expectations are set before acting so the AWS one can stub its calls
+type queueFixture interface {
+	service(t *testing.T, processor *mocks.MockProcessMessageService[process.ProcessMessageDto]) QueueService
+	receive(t *testing.T, id string, body string, receiveCount int)
+	expectAck(t *testing.T, id string)
+	expectRetry(t *testing.T, id string, delay time.Duration)
+	expectDeadLetter(t *testing.T, id string, body string, attributes map[string]string)
+	verify(t *testing.T)
+}
+
+const (
+	contractTopicName = "test-topic"
+	contractQueueName = "test-queue"
+	contractDLQName   = "test-dlq"
+	contractMessageId = "fc8e9ffd-6122-5c52-8fb9-c13e3ee2629a"
+	contractBody      = `{"order_id":"be6293ff-4ec0-4ed8-95c9-b36ce99aa105","payment":{"id":"a5c81ac9-a549-44c5-bb09-c330116b929f","state":"Approved"}}`
+)
+
+func runTopicContract(t *testing.T, newFixture func(t *testing.T, topicName string) topicFixture) {
+	t.Run("Should return the topic name", func(t *testing.T) {
+		// Arrange
+		fixture := newFixture(t, contractTopicName)
+
+		service := fixture.service(t)
+
+		// Act
+		topicName := service.GetTopicName()
+
+		// Assert
+		assert.Equal(t, contractTopicName, topicName)
+	})
+
+	t.Run("Should publish the message as json", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		fixture := newFixture(t, contractTopicName)
+		fixture.expectPublish(t, `{"message":"test"}`)
+
+		service := fixture.service(t)
+
+		err := service.UpdateTopicArn(ctx)
+		assert.NoError(t, err)
+
+		// Act
+		messageId, err := service.PublishMessage(ctx, map[string]string{"message": "test"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEmpty(t, *messageId)
+		fixture.verify(t)
+	})
+
+	t.Run("Should publish only the data of an event", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		fixture := newFixture(t, contractTopicName)
+		fixture.expectPublish(t, `{"order_id":"order-id"}`)
+
+		service := fixture.service(t)
+
+		err := service.UpdateTopicArn(ctx)
+		assert.NoError(t, err)
+
+		message := Event{
+			Id:      "event-id",
+			Type:    "OrderCreated",
+			Subject: "order-id",
+			Time:    time.Date(2024, 5, 19, 2, 1, 36, 0, time.UTC),
+			Data:    json.RawMessage(`{"order_id":"order-id"}`),
+		}
+
+		// Act
+		messageId, err := service.PublishMessage(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEmpty(t, *messageId)
+		fixture.verify(t)
+	})
+}
+
+func runQueueContract(t *testing.T, newFixture func(t *testing.T, queueName string, deadLetterQueueName string) queueFixture) {
+	t.Run("Should return the queue name", func(t *testing.T) {
+		// Arrange
+		fixture := newFixture(t, contractQueueName, contractDLQName)
+
+		service := fixture.service(t, mocks.NewMockProcessMessageService[process.ProcessMessageDto](t))
+
+		// Act
+		queueName := service.GetQueueName()
+
+		// Assert
+		assert.Equal(t, contractQueueName, queueName)
+	})
+
+	t.Run("Should acknowledge a processed message", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.Anything, mock.MatchedBy(func(request process.ProcessMessageDto) bool {
+			return request.MessageId == contractMessageId && request.OrderId == "be6293ff-4ec0-4ed8-95c9-b36ce99aa105"
+		})).
+			Return(nil).
+			Once()
+
+		fixture := newFixture(t, contractQueueName, contractDLQName)
+
+		service := fixture.service(t, processor)
+
+		fixture.receive(t, contractMessageId, contractBody, 1)
+		fixture.expectAck(t, contractMessageId)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		fixture.verify(t)
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{Processed: 1}, stats(service))
+	})
+
+	t.Run("Should retry a transient failure after a backoff", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		fixture := newFixture(t, contractQueueName, contractDLQName)
+
+		service := fixture.service(t, processor)
+
+		fixture.receive(t, contractMessageId, contractBody, 2)
+		fixture.expectRetry(t, contractMessageId, 2*time.Second)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		fixture.verify(t)
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{Retried: 1}, stats(service))
+	})
+
+	t.Run("Should dead-letter a transient failure after the max receives", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		fixture := newFixture(t, contractQueueName, contractDLQName)
+
+		service := fixture.service(t, processor)
+
+		fixture.receive(t, contractMessageId, contractBody, 3)
+		fixture.expectDeadLetter(t, contractMessageId, contractBody, map[string]string{
+			"SourceQueue":       contractQueueName,
+			"OriginalMessageId": contractMessageId,
+			"FailureClass":      "transient",
+			"FailureReason":     "max receives exceeded",
+			"FailureError":      assert.AnError.Error(),
+			"ReceiveCount":      "3",
+		})
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		fixture.verify(t)
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, stats(service))
+	})
+
+	t.Run("Should dead-letter a permanent failure", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.Anything, mock.Anything).
+			Return(custom_error.ErrPaymentInvalidStateTransition).
+			Once()
+
+		fixture := newFixture(t, contractQueueName, contractDLQName)
+
+		service := fixture.service(t, processor)
+
+		fixture.receive(t, contractMessageId, contractBody, 1)
+		fixture.expectDeadLetter(t, contractMessageId, contractBody, map[string]string{
+			"SourceQueue":       contractQueueName,
+			"OriginalMessageId": contractMessageId,
+			"FailureClass":      "permanent",
+			"FailureReason":     "invalid state transition",
+			"FailureError":      "invalid state transition",
+			"ReceiveCount":      "1",
+		})
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		fixture.verify(t)
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, stats(service))
+	})
+
+	t.Run("Should dead-letter a message that can not be decoded", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		var attributes map[string]json.RawMessage
+		decodeErr := json.Unmarshal([]byte(`"order-id"`), &attributes)
+
+		fixture := newFixture(t, contractQueueName, contractDLQName)
+
+		service := fixture.service(t, processor)
+
+		fixture.receive(t, contractMessageId, `"order-id"`, 1)
+		fixture.expectDeadLetter(t, contractMessageId, `"order-id"`, map[string]string{
+			"SourceQueue":       contractQueueName,
+			"OriginalMessageId": contractMessageId,
+			"FailureClass":      "permanent",
+			"FailureReason":     "invalid message",
+			"FailureError":      decodeErr.Error(),
+			"ReceiveCount":      "1",
+		})
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		fixture.verify(t)
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, stats(service))
+	})
+
+	t.Run("Should discard a failed message when there is no dead-letter queue", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.Anything, mock.Anything).
+			Return(custom_error.ErrPaymentInvalidStateTransition).
+			Once()
+
+		fixture := newFixture(t, contractQueueName, "")
+
+		service := fixture.service(t, processor)
+
+		fixture.receive(t, contractMessageId, contractBody, 1)
+		fixture.expectAck(t, contractMessageId)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		fixture.verify(t)
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{}, stats(service))
+	})
+}
+
+func stats(service QueueService) QueueStats {
+	return service.(interface{ GetStats() QueueStats }).GetStats()
+}
@@ -0,0 +1,328 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+)
+
+const (
+	fileInFlightDir = "inflight"
+	fileAckedDir    = "acked"
+	// filePollInterval is how long a consumer waits before looking for new
+	// files when the queue is empty
+	filePollInterval = time.Second
+)
+
+// fileMessage is the content of each file. A file that is not an envelope is
+// read as the body itself, so a message can be dropped in the queue by hand
+type fileMessage struct {
+	Id           string            `json:"id"`
+	Body         string            `json:"body"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	ReceiveCount int               `json:"receive_count"`
+	VisibleAt    time.Time         `json:"visible_at"`
+}
+
+// FileBroker keeps each topic and queue as a directory holding one JSON file
+// per message. A receive claims the file by renaming it to the in-flight
+// directory and an ack renames it to the acked one, so only one consumer ever
+// gets a message. Meant for offline development
+type FileBroker struct {
+	Directory string
+}
+
+func NewFileBroker(directory string) *FileBroker {
+	return &FileBroker{
+		Directory: directory,
+	}
+}
+
+// prepare creates the directories of the queue and puts back the messages
+// left in flight by a consumer that stopped before settling them
+func (b *FileBroker) prepare(name string) error {
+	for _, dir := range []string{fileInFlightDir, fileAckedDir} {
+		if err := os.MkdirAll(b.path(name, dir), 0o755); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(b.path(name, fileInFlightDir))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !isMessageFile(entry) {
+			continue
+		}
+
+		if err := os.Rename(b.path(name, fileInFlightDir, entry.Name()), b.path(name, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send writes the message to a temporary file first, the rename makes it
+// visible to the consumers only once it is complete
+func (b *FileBroker) send(name string, message fileMessage) error {
+	if err := os.MkdirAll(b.path(name), 0o755); err != nil {
+		return err
+	}
+
+	// the time prefix keeps the files in the order they were sent
+	fileName := fmt.Sprintf("%020d-%s.json", time.Now().UnixNano(), message.Id)
+
+	return b.write(b.path(name, fileName), message)
+}
+
+func (b *FileBroker) receive(name string, max int) ([]Delivery, error) {
+	entries, err := os.ReadDir(b.path(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []Delivery
+
+	for _, entry := range entries {
+		if len(deliveries) == max {
+			break
+		}
+
+		if !isMessageFile(entry) {
+			continue
+		}
+
+		message, err := b.read(b.path(name, entry.Name()))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return deliveries, err
+		}
+
+		if message.VisibleAt.After(time.Now()) {
+			continue
+		}
+
+		// another consumer claimed the file first
+		if err := os.Rename(b.path(name, entry.Name()), b.path(name, fileInFlightDir, entry.Name())); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return deliveries, err
+		}
+
+		message.ReceiveCount++
+
+		if err := b.write(b.path(name, fileInFlightDir, entry.Name()), message); err != nil {
+			return deliveries, err
+		}
+
+		deliveries = append(deliveries, Delivery{
+			Id:           message.Id,
+			Body:         message.Body,
+			Attributes:   message.Attributes,
+			ReceiveCount: message.ReceiveCount,
+			Handle:       entry.Name(),
+		})
+	}
+
+	return deliveries, nil
+}
+
+func (b *FileBroker) ack(name string, delivery Delivery) error {
+	return os.Rename(b.path(name, fileInFlightDir, delivery.Handle), b.path(name, fileAckedDir, delivery.Handle))
+}
+
+func (b *FileBroker) redeliver(name string, delivery Delivery, delay time.Duration) error {
+	inFlight := b.path(name, fileInFlightDir, delivery.Handle)
+
+	err := b.write(inFlight, fileMessage{
+		Id:           delivery.Id,
+		Body:         delivery.Body,
+		Attributes:   delivery.Attributes,
+		ReceiveCount: delivery.ReceiveCount,
+		VisibleAt:    time.Now().Add(delay),
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(inFlight, b.path(name, delivery.Handle))
+}
+
+func (b *FileBroker) read(path string) (fileMessage, error) {
+	var message fileMessage
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return message, err
+	}
+
+	if err := json.Unmarshal(content, &message); err != nil || message.Body == "" {
+		message = fileMessage{
+			Id:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Body: string(content),
+		}
+	}
+
+	return message, nil
+}
+
+func (b *FileBroker) write(path string, message fileMessage) error {
+	content, err := json.MarshalIndent(message, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (b *FileBroker) path(name string, elem ...string) string {
+	return filepath.Join(append([]string{b.Directory, name}, elem...)...)
+}
+
+func isMessageFile(entry fs.DirEntry) bool {
+	return !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && strings.HasSuffix(entry.Name(), ".json")
+}
+
+type FileTopicService struct {
+	TopicName   string
+	CloudEvents CloudEventsOptions
+	Broker      *FileBroker
+}
+
+func NewFileTopicService(topicName string, cloudEvents CloudEventsOptions, broker *FileBroker) TopicService {
+	return &FileTopicService{
+		TopicName:   topicName,
+		CloudEvents: cloudEvents,
+		Broker:      broker,
+	}
+}
+
+func (s *FileTopicService) GetTopicName() string {
+	return s.TopicName
+}
+
+func (s *FileTopicService) UpdateTopicArn(ctx context.Context) error {
+	return os.MkdirAll(s.Broker.path(s.TopicName), 0o755)
+}
+
+func (s *FileTopicService) PublishMessage(ctx context.Context, message interface{}) (*string, error) {
+	body, err := encodeMessage(s.TopicName, s.CloudEvents, message)
+	if err != nil {
+		return nil, err
+	}
+
+	messageId, err := tracePublish(ctx, messagingSystemFile, s.TopicName, func(ctx context.Context, attributes map[string]string) (string, error) {
+		messageId := uuid.NewString()
+
+		return messageId, s.Broker.send(s.TopicName, fileMessage{
+			Id:         messageId,
+			Body:       string(body),
+			Attributes: attributes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "message published", "topic", s.TopicName, "message_id", messageId, "message", string(body))
+
+	return &messageId, nil
+}
+
+type FileQueueService struct {
+	*Consumer
+
+	Broker *FileBroker
+}
+
+func NewFileQueueService(
+	broker *FileBroker,
+	queueName string,
+	deadLetterQueueName string,
+	retryPolicy RetryPolicy,
+	concurrency int,
+	verifier NotificationVerifier,
+	messageProcessor service.ProcessMessageService[process.ProcessMessageDto],
+) QueueService {
+	queue := &FileQueueService{
+		Broker: broker,
+	}
+
+	queue.Consumer = newConsumer(messagingSystemFile, queueName, deadLetterQueueName, retryPolicy, concurrency, verifier, messageProcessor, queue)
+
+	return queue
+}
+
+func (s *FileQueueService) UpdateQueueUrl(ctx context.Context) error {
+	return s.Broker.prepare(s.QueueName)
+}
+
+func (s *FileQueueService) ConsumeMessages(ctx context.Context) {
+	deliveries, err := s.Broker.receive(s.QueueName, 10)
+	if err != nil {
+		slog.ErrorContext(ctx, "error receiving message from queue", "queue_dir", s.Broker.path(s.QueueName), "error", err)
+	}
+
+	if len(deliveries) == 0 {
+		select {
+		case <-time.After(filePollInterval):
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	s.dispatch(ctx, deliveries)
+}
+
+func (s *FileQueueService) ack(ctx context.Context, delivery Delivery) error {
+	return s.Broker.ack(s.QueueName, delivery)
+}
+
+func (s *FileQueueService) retry(ctx context.Context, delivery Delivery, delay time.Duration) error {
+	return s.Broker.redeliver(s.QueueName, delivery, delay)
+}
+
+func (s *FileQueueService) deadLetter(ctx context.Context, delivery Delivery, failure Failure) error {
+	if s.DeadLetterQueueName == "" {
+		return errNoDeadLetterQueue
+	}
+
+	return s.Broker.send(s.DeadLetterQueueName, fileMessage{
+		Id:         delivery.Id,
+		Body:       delivery.Body,
+		Attributes: deadLetterAttributes(s.QueueName, delivery, failure),
+	})
+}
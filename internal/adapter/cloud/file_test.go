@@ -0,0 +1,248 @@
+package cloud
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// readMessages returns every message file of the directory, in order
+func readMessages(t *testing.T, broker *FileBroker, elem ...string) []fileMessage {
+	entries, err := os.ReadDir(filepath.Join(append([]string{broker.Directory}, elem...)...))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	var messages []fileMessage
+	for _, entry := range entries {
+		if !isMessageFile(entry) {
+			continue
+		}
+
+		message, err := broker.read(filepath.Join(append(append([]string{broker.Directory}, elem...), entry.Name())...))
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+type fileTopicFixture struct {
+	topicName string
+	broker    *FileBroker
+	expected  []string
+}
+
+func newFileTopicFixture(t *testing.T, topicName string) topicFixture {
+	return &fileTopicFixture{
+		topicName: topicName,
+		broker:    NewFileBroker(t.TempDir()),
+	}
+}
+
+func (f *fileTopicFixture) service(t *testing.T) TopicService {
+	return NewFileTopicService(f.topicName, CloudEventsOptions{}, f.broker)
+}
+
+func (f *fileTopicFixture) expectPublish(t *testing.T, body string) {
+	f.expected = append(f.expected, body)
+}
+
+func (f *fileTopicFixture) verify(t *testing.T) {
+	var published []string
+	for _, message := range readMessages(t, f.broker, f.topicName) {
+		published = append(published, message.Body)
+	}
+
+	assert.Equal(t, f.expected, published)
+}
+
+type fileQueueFixture struct {
+	queueName           string
+	deadLetterQueueName string
+	broker              *FileBroker
+
+	acks        []string
+	retries     map[string]time.Time
+	deadLetters []fileMessage
+}
+
+func newFileQueueFixture(t *testing.T, queueName string, deadLetterQueueName string) queueFixture {
+	return &fileQueueFixture{
+		queueName:           queueName,
+		deadLetterQueueName: deadLetterQueueName,
+		broker:              NewFileBroker(t.TempDir()),
+		retries:             make(map[string]time.Time),
+	}
+}
+
+func (f *fileQueueFixture) service(t *testing.T, processor *mocks.MockProcessMessageService[process.ProcessMessageDto]) QueueService {
+	service := NewFileQueueService(f.broker, f.queueName, f.deadLetterQueueName, testRetryPolicy, 2, nil, processor)
+	assert.NoError(t, service.UpdateQueueUrl(context.Background()))
+
+	return service
+}
+
+func (f *fileQueueFixture) receive(t *testing.T, id string, body string, receiveCount int) {
+	err := f.broker.send(f.queueName, fileMessage{
+		Id:           id,
+		Body:         body,
+		ReceiveCount: receiveCount - 1,
+	})
+	assert.NoError(t, err)
+}
+
+func (f *fileQueueFixture) expectAck(t *testing.T, id string) {
+	f.acks = append(f.acks, id)
+}
+
+func (f *fileQueueFixture) expectRetry(t *testing.T, id string, delay time.Duration) {
+	f.retries[id] = time.Now().Add(delay)
+}
+
+func (f *fileQueueFixture) expectDeadLetter(t *testing.T, id string, body string, attributes map[string]string) {
+	f.acks = append(f.acks, id)
+	f.deadLetters = append(f.deadLetters, fileMessage{
+		Id:         id,
+		Body:       body,
+		Attributes: attributes,
+	})
+}
+
+func (f *fileQueueFixture) verify(t *testing.T) {
+	assert.Empty(t, readMessages(t, f.broker, f.queueName, fileInFlightDir))
+
+	var acks []string
+	for _, message := range readMessages(t, f.broker, f.queueName, fileAckedDir) {
+		acks = append(acks, message.Id)
+	}
+	assert.Equal(t, f.acks, acks)
+
+	pending := readMessages(t, f.broker, f.queueName)
+	assert.Len(t, pending, len(f.retries))
+	for _, message := range pending {
+		assert.WithinDuration(t, f.retries[message.Id], message.VisibleAt, time.Second)
+	}
+
+	if f.deadLetterQueueName == "" {
+		return
+	}
+
+	assert.Equal(t, f.deadLetters, readMessages(t, f.broker, f.deadLetterQueueName))
+}
+
+func TestFileTopicContract(t *testing.T) {
+	runTopicContract(t, newFileTopicFixture)
+}
+
+func TestFileQueueContract(t *testing.T) {
+	runQueueContract(t, newFileQueueFixture)
+}
+
+func TestFileBroker(t *testing.T) {
+	t.Run("Should read a file dropped by hand as the body", func(t *testing.T) {
+		// Arrange
+		broker := NewFileBroker(t.TempDir())
+		assert.NoError(t, broker.prepare("test-queue"))
+
+		err := os.WriteFile(broker.path("test-queue", "payment-approved.json"), []byte(`{"order_id":"order-id"}`), 0o644)
+		assert.NoError(t, err)
+
+		// Act
+		deliveries, err := broker.receive("test-queue", 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []Delivery{
+			{
+				Id:           "payment-approved",
+				Body:         `{"order_id":"order-id"}`,
+				ReceiveCount: 1,
+				Handle:       "payment-approved.json",
+			},
+		}, deliveries)
+	})
+
+	t.Run("Should skip the messages that are not visible yet", func(t *testing.T) {
+		// Arrange
+		broker := NewFileBroker(t.TempDir())
+
+		err := broker.send("test-queue", fileMessage{
+			Id:        "message-id",
+			Body:      `{"order_id":"order-id"}`,
+			VisibleAt: time.Now().Add(time.Minute),
+		})
+		assert.NoError(t, err)
+
+		// Act
+		deliveries, err := broker.receive("test-queue", 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+
+	t.Run("Should receive the messages in the order they were sent", func(t *testing.T) {
+		// Arrange
+		broker := NewFileBroker(t.TempDir())
+		assert.NoError(t, broker.prepare("test-queue"))
+
+		for _, id := range []string{"first", "second", "third"} {
+			assert.NoError(t, broker.send("test-queue", fileMessage{Id: id, Body: "{}"}))
+		}
+
+		// Act
+		deliveries, err := broker.receive("test-queue", 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, "first", deliveries[0].Id)
+		assert.Equal(t, "second", deliveries[1].Id)
+	})
+
+	t.Run("Should put back the messages left in flight", func(t *testing.T) {
+		// Arrange
+		broker := NewFileBroker(t.TempDir())
+		assert.NoError(t, broker.prepare("test-queue"))
+		assert.NoError(t, broker.send("test-queue", fileMessage{Id: "message-id", Body: "{}"}))
+
+		_, err := broker.receive("test-queue", 10)
+		assert.NoError(t, err)
+
+		// Act
+		err = broker.prepare("test-queue")
+
+		// Assert
+		assert.NoError(t, err)
+
+		deliveries, err := broker.receive("test-queue", 10)
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, 2, deliveries[0].ReceiveCount)
+	})
+
+	t.Run("Should return an error when the queue does not exist", func(t *testing.T) {
+		// Arrange
+		broker := NewFileBroker(t.TempDir())
+
+		// Act
+		deliveries, err := broker.receive("test-queue", 10)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Empty(t, deliveries)
+	})
+}
@@ -0,0 +1,239 @@
+package cloud
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+)
+
+const (
+	memoryQueueSize = 1024
+	// memoryWaitTime bounds how long a receive waits for the first message,
+	// as the SQS long polling does
+	memoryWaitTime = time.Second
+)
+
+// MemoryBroker keeps each queue as a buffered channel, a message published to
+// a topic is delivered to every queue subscribed to it and blocks while one of
+// them is full. It is meant for tests and local runs, nothing survives a restart
+type MemoryBroker struct {
+	mu            sync.Mutex
+	queues        map[string]chan Delivery
+	subscriptions map[string][]string
+	scheduled     map[string]map[string]time.Time
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		queues:        make(map[string]chan Delivery),
+		subscriptions: make(map[string][]string),
+		scheduled:     make(map[string]map[string]time.Time),
+	}
+}
+
+// Subscribe delivers the messages published to the topic to the queue
+func (b *MemoryBroker) Subscribe(topicName string, queueName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions[topicName] = append(b.subscriptions[topicName], queueName)
+}
+
+// Send puts the body straight on the queue, as another service would
+func (b *MemoryBroker) Send(queueName string, body string) string {
+	id := uuid.NewString()
+
+	b.queue(queueName) <- Delivery{
+		Id:   id,
+		Body: body,
+	}
+
+	return id
+}
+
+func (b *MemoryBroker) publish(topicName string, body string, attributes map[string]string) string {
+	id := uuid.NewString()
+
+	b.mu.Lock()
+	queueNames := b.subscriptions[topicName]
+	b.mu.Unlock()
+
+	for _, queueName := range queueNames {
+		b.queue(queueName) <- Delivery{
+			Id:         id,
+			Body:       body,
+			Attributes: attributes,
+		}
+	}
+
+	return id
+}
+
+// receive waits for the first message up to the wait time and then takes
+// whatever else is already on the queue, counting a new receive on each one
+func (b *MemoryBroker) receive(ctx context.Context, queueName string, max int, wait time.Duration) []Delivery {
+	queue := b.queue(queueName)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	var deliveries []Delivery
+
+	select {
+	case delivery := <-queue:
+		deliveries = append(deliveries, delivery)
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return nil
+	}
+
+drain:
+	for len(deliveries) < max {
+		select {
+		case delivery := <-queue:
+			deliveries = append(deliveries, delivery)
+		default:
+			break drain
+		}
+	}
+
+	for i := range deliveries {
+		deliveries[i].ReceiveCount++
+	}
+
+	return deliveries
+}
+
+// redeliver puts the delivery back on the queue once the delay is over
+func (b *MemoryBroker) redeliver(queueName string, delivery Delivery, delay time.Duration) {
+	b.mu.Lock()
+	if b.scheduled[queueName] == nil {
+		b.scheduled[queueName] = make(map[string]time.Time)
+	}
+	b.scheduled[queueName][delivery.Id] = time.Now().Add(delay)
+	b.mu.Unlock()
+
+	time.AfterFunc(delay, func() {
+		b.mu.Lock()
+		delete(b.scheduled[queueName], delivery.Id)
+		b.mu.Unlock()
+
+		b.queue(queueName) <- delivery
+	})
+}
+
+func (b *MemoryBroker) queue(queueName string) chan Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue, ok := b.queues[queueName]
+	if !ok {
+		queue = make(chan Delivery, memoryQueueSize)
+		b.queues[queueName] = queue
+	}
+
+	return queue
+}
+
+type MemoryTopicService struct {
+	TopicName   string
+	CloudEvents CloudEventsOptions
+	Broker      *MemoryBroker
+}
+
+func NewMemoryTopicService(topicName string, cloudEvents CloudEventsOptions, broker *MemoryBroker) TopicService {
+	return &MemoryTopicService{
+		TopicName:   topicName,
+		CloudEvents: cloudEvents,
+		Broker:      broker,
+	}
+}
+
+func (s *MemoryTopicService) GetTopicName() string {
+	return s.TopicName
+}
+
+func (s *MemoryTopicService) UpdateTopicArn(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryTopicService) PublishMessage(ctx context.Context, message interface{}) (*string, error) {
+	body, err := encodeMessage(s.TopicName, s.CloudEvents, message)
+	if err != nil {
+		return nil, err
+	}
+
+	messageId, err := tracePublish(ctx, messagingSystemMemory, s.TopicName, func(ctx context.Context, attributes map[string]string) (string, error) {
+		return s.Broker.publish(s.TopicName, string(body), attributes), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "message published", "topic", s.TopicName, "message_id", messageId, "message", string(body))
+
+	return &messageId, nil
+}
+
+type MemoryQueueService struct {
+	*Consumer
+
+	Broker *MemoryBroker
+}
+
+func NewMemoryQueueService(
+	broker *MemoryBroker,
+	queueName string,
+	deadLetterQueueName string,
+	retryPolicy RetryPolicy,
+	concurrency int,
+	verifier NotificationVerifier,
+	messageProcessor service.ProcessMessageService[process.ProcessMessageDto],
+) QueueService {
+	queue := &MemoryQueueService{
+		Broker: broker,
+	}
+
+	queue.Consumer = newConsumer(messagingSystemMemory, queueName, deadLetterQueueName, retryPolicy, concurrency, verifier, messageProcessor, queue)
+
+	return queue
+}
+
+func (s *MemoryQueueService) UpdateQueueUrl(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryQueueService) ConsumeMessages(ctx context.Context) {
+	s.dispatch(ctx, s.Broker.receive(ctx, s.QueueName, 10, memoryWaitTime))
+}
+
+func (s *MemoryQueueService) ack(ctx context.Context, delivery Delivery) error {
+	// the delivery left the channel when it was received
+	return nil
+}
+
+func (s *MemoryQueueService) retry(ctx context.Context, delivery Delivery, delay time.Duration) error {
+	s.Broker.redeliver(s.QueueName, delivery, delay)
+
+	return nil
+}
+
+func (s *MemoryQueueService) deadLetter(ctx context.Context, delivery Delivery, failure Failure) error {
+	if s.DeadLetterQueueName == "" {
+		return errNoDeadLetterQueue
+	}
+
+	s.Broker.queue(s.DeadLetterQueueName) <- Delivery{
+		Id:         delivery.Id,
+		Body:       delivery.Body,
+		Attributes: deadLetterAttributes(s.QueueName, delivery, failure),
+	}
+
+	return nil
+}
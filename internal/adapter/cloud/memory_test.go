@@ -0,0 +1,391 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type memoryTopicFixture struct {
+	topicName string
+	broker    *MemoryBroker
+	expected  []string
+}
+
+func newMemoryTopicFixture(t *testing.T, topicName string) topicFixture {
+	broker := NewMemoryBroker()
+	broker.Subscribe(topicName, "probe")
+
+	return &memoryTopicFixture{
+		topicName: topicName,
+		broker:    broker,
+	}
+}
+
+func (f *memoryTopicFixture) service(t *testing.T) TopicService {
+	return NewMemoryTopicService(f.topicName, CloudEventsOptions{}, f.broker)
+}
+
+func (f *memoryTopicFixture) expectPublish(t *testing.T, body string) {
+	f.expected = append(f.expected, body)
+}
+
+func (f *memoryTopicFixture) verify(t *testing.T) {
+	var published []string
+	for _, delivery := range f.broker.receive(context.Background(), "probe", memoryQueueSize, time.Millisecond) {
+		published = append(published, delivery.Body)
+	}
+
+	assert.Equal(t, f.expected, published)
+}
+
+type memoryQueueFixture struct {
+	queueName           string
+	deadLetterQueueName string
+	broker              *MemoryBroker
+
+	retries     map[string]time.Time
+	deadLetters []Delivery
+}
+
+func newMemoryQueueFixture(t *testing.T, queueName string, deadLetterQueueName string) queueFixture {
+	return &memoryQueueFixture{
+		queueName:           queueName,
+		deadLetterQueueName: deadLetterQueueName,
+		broker:              NewMemoryBroker(),
+		retries:             make(map[string]time.Time),
+	}
+}
+
+func (f *memoryQueueFixture) service(t *testing.T, processor *mocks.MockProcessMessageService[process.ProcessMessageDto]) QueueService {
+	return NewMemoryQueueService(f.broker, f.queueName, f.deadLetterQueueName, testRetryPolicy, 2, nil, processor)
+}
+
+func (f *memoryQueueFixture) receive(t *testing.T, id string, body string, receiveCount int) {
+	f.broker.queue(f.queueName) <- Delivery{
+		Id:           id,
+		Body:         body,
+		ReceiveCount: receiveCount - 1,
+	}
+}
+
+func (f *memoryQueueFixture) expectAck(t *testing.T, id string) {
+	// an acknowledged delivery is nowhere to be found
+}
+
+func (f *memoryQueueFixture) expectRetry(t *testing.T, id string, delay time.Duration) {
+	f.retries[id] = time.Now().Add(delay)
+}
+
+func (f *memoryQueueFixture) expectDeadLetter(t *testing.T, id string, body string, attributes map[string]string) {
+	f.deadLetters = append(f.deadLetters, Delivery{
+		Id:           id,
+		Body:         body,
+		Attributes:   attributes,
+		ReceiveCount: 1,
+	})
+}
+
+func (f *memoryQueueFixture) verify(t *testing.T) {
+	assert.Empty(t, f.broker.queue(f.queueName))
+
+	f.broker.mu.Lock()
+	scheduled := f.broker.scheduled[f.queueName]
+	assert.Len(t, scheduled, len(f.retries))
+	for id, visibleAt := range f.retries {
+		assert.WithinDuration(t, visibleAt, scheduled[id], time.Second)
+	}
+	f.broker.mu.Unlock()
+
+	if f.deadLetterQueueName == "" {
+		return
+	}
+
+	deadLetters := f.broker.receive(context.Background(), f.deadLetterQueueName, memoryQueueSize, time.Millisecond)
+	assert.Equal(t, f.deadLetters, deadLetters)
+}
+
+func TestMemoryTopicContract(t *testing.T) {
+	runTopicContract(t, newMemoryTopicFixture)
+}
+
+func TestMemoryQueueContract(t *testing.T) {
+	runQueueContract(t, newMemoryQueueFixture)
+}
+
+func TestMemoryBroker(t *testing.T) {
+	t.Run("Should deliver a published message to every subscribed queue", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		broker := NewMemoryBroker()
+		broker.Subscribe("test-topic", "queue-a")
+		broker.Subscribe("test-topic", "queue-b")
+
+		// Act
+		id := broker.publish("test-topic", `{"message":"test"}`, nil)
+
+		// Assert
+		for _, queueName := range []string{"queue-a", "queue-b"} {
+			deliveries := broker.receive(ctx, queueName, 10, time.Millisecond)
+
+			assert.Len(t, deliveries, 1)
+			assert.Equal(t, id, deliveries[0].Id)
+			assert.Equal(t, `{"message":"test"}`, deliveries[0].Body)
+			assert.Equal(t, 1, deliveries[0].ReceiveCount)
+		}
+	})
+
+	t.Run("Should drop a published message when there is no subscriber", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		broker := NewMemoryBroker()
+
+		// Act
+		broker.publish("test-topic", `{"message":"test"}`, nil)
+
+		// Assert
+		assert.Empty(t, broker.receive(ctx, "test-topic", 10, time.Millisecond))
+	})
+
+	t.Run("Should return nothing when the queue stays empty", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		broker := NewMemoryBroker()
+
+		// Act
+		deliveries := broker.receive(ctx, "test-queue", 10, time.Minute)
+
+		// Assert
+		assert.Empty(t, deliveries)
+	})
+
+	t.Run("Should redeliver a retried message once the delay is over", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		broker := NewMemoryBroker()
+		broker.Send("test-queue", `{"message":"test"}`)
+
+		deliveries := broker.receive(ctx, "test-queue", 10, time.Millisecond)
+
+		// Act
+		broker.redeliver("test-queue", deliveries[0], 10*time.Millisecond)
+
+		// Assert
+		assert.Empty(t, broker.receive(ctx, "test-queue", 10, time.Millisecond))
+
+		redelivered := broker.receive(ctx, "test-queue", 10, time.Second)
+		assert.Len(t, redelivered, 1)
+		assert.Equal(t, deliveries[0].Id, redelivered[0].Id)
+		assert.Equal(t, 2, redelivered[0].ReceiveCount)
+	})
+}
+
+func TestMemoryRoundTrip(t *testing.T) {
+	t.Run("Should process a message published to a subscribed topic", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		broker := NewMemoryBroker()
+		broker.Subscribe("test-topic", "test-queue")
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.Anything, mock.MatchedBy(func(request process.ProcessMessageDto) bool {
+			return request.OrderId == "order-id" && request.MessageId == "event-id"
+		})).
+			Return(nil).
+			Once()
+
+		topic := NewMemoryTopicService("test-topic", CloudEventsOptions{Enabled: true, Source: "ms-payment"}, broker)
+		queue := NewMemoryQueueService(broker, "test-queue", "", testRetryPolicy, 2, nil, processor)
+
+		_, err := topic.PublishMessage(ctx, Event{
+			Id:   "event-id",
+			Type: "PaymentApproved",
+			Time: time.Now(),
+			Data: map[string]string{"order_id": "order-id"},
+		})
+		assert.NoError(t, err)
+
+		// Act
+		queue.ConsumeMessages(ctx)
+		queue.Wait()
+
+		// Assert
+		processor.AssertExpectations(t)
+	})
+}
+
+func TestConsumerDispatch(t *testing.T) {
+	t.Run("Should finish a received message after the consumer is stopped", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.MatchedBy(func(ctx context.Context) bool {
+			return ctx.Err() == nil
+		}), mock.Anything).
+			Return(nil).
+			Once()
+
+		broker := NewMemoryBroker()
+		service := NewMemoryQueueService(broker, "test-queue", "", testRetryPolicy, 2, nil, processor).(*MemoryQueueService)
+
+		cancel()
+
+		// Act
+		service.dispatch(ctx, []Delivery{
+			{
+				Id:           "message-id",
+				Body:         `{"order_id":"order-id"}`,
+				ReceiveCount: 1,
+			},
+		})
+		service.Wait()
+
+		// Assert
+		processor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{Processed: 1}, service.GetStats())
+	})
+	t.Run("Should dead-letter an unsigned cloudevent when the signature is verified", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+		verifier := &stubVerifier{}
+
+		broker := NewMemoryBroker()
+		service := NewMemoryQueueService(broker, "test-queue", "test-dlq", testRetryPolicy, 2, verifier, processor).(*MemoryQueueService)
+
+		// Act
+		service.dispatch(ctx, []Delivery{
+			{
+				Id:           "message-id",
+				Body:         `{"specversion":"1.0","id":"event-id","source":"ms-payment","type":"PaymentApproved","data":{"order_id":"order-id"}}`,
+				ReceiveCount: 1,
+			},
+		})
+		service.Wait()
+
+		// Assert
+		deadLetters := broker.receive(ctx, "test-dlq", memoryQueueSize, time.Millisecond)
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "unsigned message", deadLetters[0].Attributes["FailureReason"])
+		assert.Empty(t, verifier.verified)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, service.GetStats())
+	})
+
+	t.Run("Should verify the notification wrapping a cloudevent", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+		verifier := &stubVerifier{}
+
+		processor.On("Handle", mock.Anything, mock.MatchedBy(func(request process.ProcessMessageDto) bool {
+			return request.OrderId == "order-id" && request.MessageId == "event-id"
+		})).
+			Return(nil).
+			Once()
+
+		broker := NewMemoryBroker()
+		service := NewMemoryQueueService(broker, "test-queue", "test-dlq", testRetryPolicy, 2, verifier, processor).(*MemoryQueueService)
+
+		// Act
+		service.dispatch(ctx, []Delivery{
+			{
+				Id: "message-id",
+				Body: `{
+					"Type": "Notification",
+					"MessageId": "sns-message-id",
+					"TopicArn": "arn:aws:sns:us-east-1:000000000000:OrderEventsTopic",
+					"Message": "{\"specversion\":\"1.0\",\"id\":\"event-id\",\"source\":\"ms-payment\",\"type\":\"PaymentApproved\",\"data\":{\"order_id\":\"order-id\"}}"
+				}`,
+				ReceiveCount: 1,
+			},
+		})
+		service.Wait()
+
+		// Assert
+		processor.AssertExpectations(t)
+		assert.Equal(t, []string{"sns-message-id"}, verifier.verified)
+		assert.Equal(t, QueueStats{Processed: 1}, service.GetStats())
+	})
+
+	t.Run("Should retry a notification whose signing certificate can not be fetched", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+		verifier := &stubVerifier{err: assert.AnError}
+
+		broker := NewMemoryBroker()
+		service := NewMemoryQueueService(broker, "test-queue", "test-dlq", testRetryPolicy, 2, verifier, processor).(*MemoryQueueService)
+
+		// Act
+		service.dispatch(ctx, []Delivery{newNotificationDelivery()})
+		service.Wait()
+
+		// Assert
+		assert.Empty(t, broker.receive(ctx, "test-dlq", memoryQueueSize, time.Millisecond))
+		assert.Equal(t, QueueStats{Retried: 1}, service.GetStats())
+	})
+
+	t.Run("Should dead-letter a notification with an invalid signature", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+		verifier := &stubVerifier{err: fmt.Errorf("%w: bad signature", custom_error.ErrQueueMessageSignatureNotValid)}
+
+		broker := NewMemoryBroker()
+		service := NewMemoryQueueService(broker, "test-queue", "test-dlq", testRetryPolicy, 2, verifier, processor).(*MemoryQueueService)
+
+		// Act
+		service.dispatch(ctx, []Delivery{newNotificationDelivery()})
+		service.Wait()
+
+		// Assert
+		deadLetters := broker.receive(ctx, "test-dlq", memoryQueueSize, time.Millisecond)
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "invalid signature", deadLetters[0].Attributes["FailureReason"])
+		assert.Equal(t, QueueStats{DeadLettered: 1}, service.GetStats())
+	})
+}
+
+func newNotificationDelivery() Delivery {
+	return Delivery{
+		Id: "message-id",
+		Body: `{
+			"Type": "Notification",
+			"MessageId": "sns-message-id",
+			"TopicArn": "arn:aws:sns:us-east-1:000000000000:OrderEventsTopic",
+			"Message": "{\"order_id\":\"order-id\"}"
+		}`,
+		ReceiveCount: 1,
+	}
+}
+
+// stubVerifier returns err for every notification, keeping the ids it verified
+type stubVerifier struct {
+	err      error
+	verified []string
+}
+
+func (v *stubVerifier) Verify(ctx context.Context, notification TopicNotification) error {
+	v.verified = append(v.verified, notification.MessageId)
+	return v.err
+}
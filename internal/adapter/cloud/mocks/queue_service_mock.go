@@ -54,6 +54,11 @@ func (_m *MockQueueService) UpdateQueueUrl(ctx context.Context) error {
 	return r0
 }
 
+// Wait provides a mock function with given fields:
+func (_m *MockQueueService) Wait() {
+	_m.Called()
+}
+
 // NewMockQueueService creates a new instance of MockQueueService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockQueueService(t interface {
@@ -0,0 +1,367 @@
+package cloud
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/lib/pq"
+)
+
+var (
+	errStaleReceipt      = errors.New("the receipt handle is no longer valid")
+	errNoSubscribedQueue = errors.New("no queue subscribed to the topic")
+)
+
+// PostgresBroker keeps the queues in the broker_messages table, a message
+// published to a topic is copied to every queue subscribed to it, either
+// through Subscribe or listed in the broker_subscriptions table. A receive locks the rows with SKIP LOCKED and
+// hides them for the visibility timeout, as SQS does, so concurrent consumers
+// never get the same message while it is being handled
+type PostgresBroker struct {
+	conn *sql.DB
+
+	mu            sync.Mutex
+	subscriptions map[string][]string
+
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration
+}
+
+func NewPostgresBroker(conn *sql.DB, visibilityTimeout time.Duration, pollInterval time.Duration) *PostgresBroker {
+	return &PostgresBroker{
+		conn:          conn,
+		subscriptions: make(map[string][]string),
+
+		VisibilityTimeout: visibilityTimeout,
+		PollInterval:      pollInterval,
+	}
+}
+
+// Subscribe copies the messages published to the topic to the queue, on top
+// of the subscriptions stored in the database
+func (b *PostgresBroker) Subscribe(topicName string, queueName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions[topicName] = append(b.subscriptions[topicName], queueName)
+}
+
+// ParseSubscriptions reads the topic:queue entries of the broker subscriptions
+func ParseSubscriptions(entries []string) (map[string][]string, error) {
+	subscriptions := make(map[string][]string)
+
+	for _, entry := range entries {
+		topicName, queueName, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || topicName == "" || queueName == "" {
+			return nil, fmt.Errorf("invalid subscription %q, expected topic:queue", entry)
+		}
+
+		subscriptions[topicName] = append(subscriptions[topicName], queueName)
+	}
+
+	return subscriptions, nil
+}
+
+// publish fails when no queue is subscribed to the topic, as the message would
+// be lost otherwise
+func (b *PostgresBroker) publish(ctx context.Context, topicName string, body string, attributes map[string]string) (string, error) {
+	query := `
+		INSERT INTO broker_messages (id, queue, body, attributes, receive_count, receipt_handle, visible_at, created_at)
+		SELECT $1, queue, $2, $3, 0, '', NOW(), NOW()
+		FROM (
+			SELECT queue FROM broker_subscriptions WHERE topic = $4
+			UNION
+			SELECT unnest($5::varchar[])
+		) AS subscribed (queue);
+	`
+
+	id := uuid.NewString()
+
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	queueNames := b.subscriptions[topicName]
+	b.mu.Unlock()
+
+	res, err := b.conn.ExecContext(ctx,
+		query,
+		id,
+		body,
+		string(encoded),
+		topicName,
+		pq.Array(queueNames))
+	if err != nil {
+		return "", err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+
+	if rowsAffected == 0 {
+		return "", fmt.Errorf("%w: %s", errNoSubscribedQueue, topicName)
+	}
+
+	return id, nil
+}
+
+func (b *PostgresBroker) send(ctx context.Context, queueName string, id string, body string, attributes map[string]string) error {
+	query := `
+		INSERT INTO broker_messages (id, queue, body, attributes, receive_count, receipt_handle, visible_at, created_at)
+		VALUES ($1, $2, $3, $4, 0, '', NOW(), NOW())
+		ON CONFLICT (queue, id) DO NOTHING;
+	`
+
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.conn.ExecContext(ctx,
+		query,
+		id,
+		queueName,
+		body,
+		string(encoded))
+
+	return err
+}
+
+// receive claims up to limit visible messages, each claim gets a new receipt
+// handle so a consumer whose visibility timed out can no longer settle it
+func (b *PostgresBroker) receive(ctx context.Context, queueName string, limit int) ([]Delivery, error) {
+	query := `
+		UPDATE broker_messages
+		SET receive_count = receive_count + 1,
+			receipt_handle = md5(random()::text || id),
+			visible_at = NOW() + ($1 * INTERVAL '1 millisecond')
+		WHERE queue = $2 AND id IN (
+			SELECT id FROM broker_messages
+			WHERE queue = $2 AND visible_at <= NOW()
+			ORDER BY created_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, body, attributes, receive_count, receipt_handle, created_at;
+	`
+
+	statement, err := b.conn.QueryContext(ctx,
+		query,
+		b.VisibilityTimeout.Milliseconds(),
+		queueName,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	type claimed struct {
+		delivery  Delivery
+		createdAt time.Time
+	}
+
+	messages := []claimed{}
+
+	for statement.Next() {
+		var message claimed
+		var attributes string
+
+		err = statement.Scan(
+			&message.delivery.Id,
+			&message.delivery.Body,
+			&attributes,
+			&message.delivery.ReceiveCount,
+			&message.delivery.Handle,
+			&message.createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(attributes), &message.delivery.Attributes); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	// RETURNING does not follow the ORDER BY of the subquery
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].createdAt.Before(messages[j].createdAt)
+	})
+
+	deliveries := make([]Delivery, 0, len(messages))
+	for _, message := range messages {
+		deliveries = append(deliveries, message.delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (b *PostgresBroker) ack(ctx context.Context, queueName string, delivery Delivery) error {
+	query := `
+		DELETE FROM broker_messages
+		WHERE queue = $1 AND id = $2 AND receipt_handle = $3;
+	`
+
+	result, err := b.conn.ExecContext(ctx,
+		query,
+		queueName,
+		delivery.Id,
+		delivery.Handle)
+	if err != nil {
+		return err
+	}
+
+	return checkReceipt(result)
+}
+
+func (b *PostgresBroker) redeliver(ctx context.Context, queueName string, delivery Delivery, delay time.Duration) error {
+	query := `
+		UPDATE broker_messages
+		SET visible_at = NOW() + ($1 * INTERVAL '1 millisecond')
+		WHERE queue = $2 AND id = $3 AND receipt_handle = $4;
+	`
+
+	result, err := b.conn.ExecContext(ctx,
+		query,
+		delay.Milliseconds(),
+		queueName,
+		delivery.Id,
+		delivery.Handle)
+	if err != nil {
+		return err
+	}
+
+	return checkReceipt(result)
+}
+
+// checkReceipt reports a message that was received again by another consumer
+// once its visibility timed out, only the latest receipt handle settles it
+func checkReceipt(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errStaleReceipt
+	}
+
+	return nil
+}
+
+type PostgresTopicService struct {
+	TopicName   string
+	CloudEvents CloudEventsOptions
+	Broker      *PostgresBroker
+}
+
+func NewPostgresTopicService(topicName string, cloudEvents CloudEventsOptions, broker *PostgresBroker) TopicService {
+	return &PostgresTopicService{
+		TopicName:   topicName,
+		CloudEvents: cloudEvents,
+		Broker:      broker,
+	}
+}
+
+func (s *PostgresTopicService) GetTopicName() string {
+	return s.TopicName
+}
+
+func (s *PostgresTopicService) UpdateTopicArn(ctx context.Context) error {
+	return nil
+}
+
+func (s *PostgresTopicService) PublishMessage(ctx context.Context, message interface{}) (*string, error) {
+	body, err := encodeMessage(s.TopicName, s.CloudEvents, message)
+	if err != nil {
+		return nil, err
+	}
+
+	messageId, err := tracePublish(ctx, messagingSystemPostgres, s.TopicName, func(ctx context.Context, attributes map[string]string) (string, error) {
+		return s.Broker.publish(ctx, s.TopicName, string(body), attributes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "message published", "topic", s.TopicName, "message_id", messageId, "message", string(body))
+
+	return &messageId, nil
+}
+
+type PostgresQueueService struct {
+	*Consumer
+
+	Broker *PostgresBroker
+}
+
+func NewPostgresQueueService(
+	broker *PostgresBroker,
+	queueName string,
+	deadLetterQueueName string,
+	retryPolicy RetryPolicy,
+	concurrency int,
+	verifier NotificationVerifier,
+	messageProcessor service.ProcessMessageService[process.ProcessMessageDto],
+) QueueService {
+	queue := &PostgresQueueService{
+		Broker: broker,
+	}
+
+	queue.Consumer = newConsumer(messagingSystemPostgres, queueName, deadLetterQueueName, retryPolicy, concurrency, verifier, messageProcessor, queue)
+
+	return queue
+}
+
+func (s *PostgresQueueService) UpdateQueueUrl(ctx context.Context) error {
+	return nil
+}
+
+func (s *PostgresQueueService) ConsumeMessages(ctx context.Context) {
+	deliveries, err := s.Broker.receive(ctx, s.QueueName, 10)
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "error receiving message from queue", "queue", s.QueueName, "error", err)
+	}
+
+	if len(deliveries) == 0 {
+		select {
+		case <-time.After(s.Broker.PollInterval):
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	s.dispatch(ctx, deliveries)
+}
+
+func (s *PostgresQueueService) ack(ctx context.Context, delivery Delivery) error {
+	return s.Broker.ack(ctx, s.QueueName, delivery)
+}
+
+func (s *PostgresQueueService) retry(ctx context.Context, delivery Delivery, delay time.Duration) error {
+	return s.Broker.redeliver(ctx, s.QueueName, delivery, delay)
+}
+
+func (s *PostgresQueueService) deadLetter(ctx context.Context, delivery Delivery, failure Failure) error {
+	if s.DeadLetterQueueName == "" {
+		return errNoDeadLetterQueue
+	}
+
+	return s.Broker.send(ctx, s.DeadLetterQueueName, delivery.Id, delivery.Body, deadLetterAttributes(s.QueueName, delivery, failure))
+}
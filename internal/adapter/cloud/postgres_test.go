@@ -0,0 +1,310 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/stretchr/testify/assert"
+)
+
+var brokerMessageColumns = []string{"id", "body", "attributes", "receive_count", "receipt_handle", "created_at"}
+
+type postgresTopicFixture struct {
+	topicName string
+	mock      sqlmock.Sqlmock
+	broker    *PostgresBroker
+}
+
+func newPostgresTopicFixture(t *testing.T, topicName string) topicFixture {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return &postgresTopicFixture{
+		topicName: topicName,
+		mock:      mock,
+		broker:    NewPostgresBroker(db, 30*time.Second, time.Millisecond),
+	}
+}
+
+func (f *postgresTopicFixture) service(t *testing.T) TopicService {
+	return NewPostgresTopicService(f.topicName, CloudEventsOptions{}, f.broker)
+}
+
+func (f *postgresTopicFixture) expectPublish(t *testing.T, body string) {
+	f.mock.ExpectExec("INSERT INTO broker_messages").
+		WithArgs(sqlmock.AnyArg(), body, "{}", f.topicName, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func (f *postgresTopicFixture) verify(t *testing.T) {
+	assert.Nil(t, f.mock.ExpectationsWereMet())
+}
+
+type postgresQueueFixture struct {
+	queueName           string
+	deadLetterQueueName string
+	mock                sqlmock.Sqlmock
+	broker              *PostgresBroker
+}
+
+func newPostgresQueueFixture(t *testing.T, queueName string, deadLetterQueueName string) queueFixture {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return &postgresQueueFixture{
+		queueName:           queueName,
+		deadLetterQueueName: deadLetterQueueName,
+		mock:                mock,
+		broker:              NewPostgresBroker(db, 30*time.Second, time.Millisecond),
+	}
+}
+
+func (f *postgresQueueFixture) service(t *testing.T, processor *mocks.MockProcessMessageService[process.ProcessMessageDto]) QueueService {
+	return NewPostgresQueueService(f.broker, f.queueName, f.deadLetterQueueName, testRetryPolicy, 2, nil, processor)
+}
+
+func (f *postgresQueueFixture) receive(t *testing.T, id string, body string, receiveCount int) {
+	f.mock.ExpectQuery("UPDATE broker_messages").
+		WithArgs(int64(30000), f.queueName, 10).
+		WillReturnRows(sqlmock.NewRows(brokerMessageColumns).
+			AddRow(id, body, "{}", receiveCount, "receipt-"+id, time.Now()))
+}
+
+func (f *postgresQueueFixture) expectAck(t *testing.T, id string) {
+	f.mock.ExpectExec("DELETE FROM broker_messages").
+		WithArgs(f.queueName, id, "receipt-"+id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func (f *postgresQueueFixture) expectRetry(t *testing.T, id string, delay time.Duration) {
+	f.mock.ExpectExec("UPDATE broker_messages").
+		WithArgs(delay.Milliseconds(), f.queueName, id, "receipt-"+id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func (f *postgresQueueFixture) expectDeadLetter(t *testing.T, id string, body string, attributes map[string]string) {
+	encoded, err := json.Marshal(attributes)
+	assert.NoError(t, err)
+
+	f.mock.ExpectExec("INSERT INTO broker_messages").
+		WithArgs(id, f.deadLetterQueueName, body, string(encoded)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	f.expectAck(t, id)
+}
+
+func (f *postgresQueueFixture) verify(t *testing.T) {
+	assert.Nil(t, f.mock.ExpectationsWereMet())
+}
+
+func TestPostgresTopicContract(t *testing.T) {
+	runTopicContract(t, newPostgresTopicFixture)
+}
+
+func TestPostgresQueueContract(t *testing.T) {
+	runQueueContract(t, newPostgresQueueFixture)
+}
+
+func TestPostgresBroker(t *testing.T) {
+	t.Run("Should return the claimed messages ordered by creation", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("UPDATE broker_messages").
+			WithArgs(int64(30000), "test-queue", 10).
+			WillReturnRows(sqlmock.NewRows(brokerMessageColumns).
+				AddRow("2", "{}", "{}", 1, "receipt-2", now).
+				AddRow("1", "{}", `{"FailureClass":"permanent"}`, 3, "receipt-1", now.Add(-time.Second)))
+
+		broker := NewPostgresBroker(db, 30*time.Second, time.Second)
+
+		// Act
+		deliveries, err := broker.receive(ctx, "test-queue", 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, "1", deliveries[0].Id)
+		assert.Equal(t, 3, deliveries[0].ReceiveCount)
+		assert.Equal(t, map[string]string{"FailureClass": "permanent"}, deliveries[0].Attributes)
+		assert.Equal(t, "2", deliveries[1].Id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should publish to the subscribed queues", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		broker := NewPostgresBroker(db, 30*time.Second, time.Millisecond)
+		broker.Subscribe("test-topic", "test-queue")
+
+		mock.ExpectExec("INSERT INTO broker_messages (.+) FROM broker_subscriptions WHERE topic = \\$4 UNION SELECT unnest\\(\\$5::varchar\\[\\]\\)").
+			WithArgs(sqlmock.AnyArg(), "body", "{}", "test-topic", "{\"test-queue\"}").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		id, err := broker.publish(ctx, "test-topic", "body", map[string]string{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEmpty(t, id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when no queue is subscribed to the topic", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		broker := NewPostgresBroker(db, 30*time.Second, time.Millisecond)
+
+		mock.ExpectExec("INSERT INTO broker_messages").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		id, err := broker.publish(ctx, "test-topic", "body", map[string]string{})
+
+		// Assert
+		assert.ErrorIs(t, err, errNoSubscribedQueue)
+		assert.Empty(t, id)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the receive fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("UPDATE broker_messages").
+			WillReturnError(assert.AnError)
+
+		broker := NewPostgresBroker(db, 30*time.Second, time.Second)
+
+		// Act
+		deliveries, err := broker.receive(ctx, "test-queue", 10)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, deliveries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should not acknowledge a message received again by another consumer", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("DELETE FROM broker_messages").
+			WithArgs("test-queue", "1", "receipt-1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		broker := NewPostgresBroker(db, 30*time.Second, time.Second)
+
+		// Act
+		err = broker.ack(ctx, "test-queue", Delivery{Id: "1", Handle: "receipt-1"})
+
+		// Assert
+		assert.ErrorIs(t, err, errStaleReceipt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should not retry a message received again by another consumer", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("UPDATE broker_messages").
+			WithArgs(int64(2000), "test-queue", "1", "receipt-1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		broker := NewPostgresBroker(db, 30*time.Second, time.Second)
+
+		// Act
+		err = broker.redeliver(ctx, "test-queue", Delivery{Id: "1", Handle: "receipt-1"}, 2*time.Second)
+
+		// Assert
+		assert.ErrorIs(t, err, errStaleReceipt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostgresConsumeMessages(t *testing.T) {
+	t.Run("Should wait for the poll interval when the queue is empty", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("UPDATE broker_messages").
+			WithArgs(int64(30000), "test-queue", 10).
+			WillReturnRows(sqlmock.NewRows(brokerMessageColumns))
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		broker := NewPostgresBroker(db, 30*time.Second, 10*time.Millisecond)
+		service := NewPostgresQueueService(broker, "test-queue", "", testRetryPolicy, 2, nil, processor)
+
+		started := time.Now()
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		assert.GreaterOrEqual(t, time.Since(started), 10*time.Millisecond)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestParseSubscriptions(t *testing.T) {
+	t.Run("Should group the queues by topic", func(t *testing.T) {
+		// Act
+		subscriptions, err := ParseSubscriptions([]string{"topic:first-queue", " topic:second-queue", "other-topic:first-queue"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"topic":       {"first-queue", "second-queue"},
+			"other-topic": {"first-queue"},
+		}, subscriptions)
+	})
+
+	t.Run("Should return error when an entry has no queue", func(t *testing.T) {
+		// Act
+		_, err := ParseSubscriptions([]string{"topic"})
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
@@ -3,8 +3,10 @@ package cloud
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
-	"sync"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -17,39 +19,35 @@ type QueueService interface {
 	GetQueueName() string
 	UpdateQueueUrl(ctx context.Context) error
 	ConsumeMessages(ctx context.Context)
+	Wait()
 }
 
 type AwsSqsService struct {
-	QueueName string
-	QueueUrl  string
-	Client    *sqs.Client
+	*Consumer
 
-	MessageProcessor service.ProcessMessageService[process.ProcessMessageDto]
-
-	ChanMessage chan types.Message
-
-	Mutex     sync.Mutex
-	WaitGroup sync.WaitGroup
+	QueueUrl           string
+	DeadLetterQueueUrl string
+	Client             *sqs.Client
 }
 
-func NewQueueService(queueName string, config aws.Config, messageProcessor service.ProcessMessageService[process.ProcessMessageDto]) QueueService {
+func NewQueueService(
+	queueName string,
+	deadLetterQueueName string,
+	retryPolicy RetryPolicy,
+	concurrency int,
+	verifier NotificationVerifier,
+	config aws.Config,
+	messageProcessor service.ProcessMessageService[process.ProcessMessageDto],
+) QueueService {
 	client := sqs.NewFromConfig(config)
 
-	return &AwsSqsService{
-		QueueName: queueName,
-		Client:    client,
-
-		MessageProcessor: messageProcessor,
-
-		ChanMessage: make(chan types.Message, 10),
-
-		Mutex:     sync.Mutex{},
-		WaitGroup: sync.WaitGroup{},
+	queue := &AwsSqsService{
+		Client: client,
 	}
-}
 
-func (s *AwsSqsService) GetQueueName() string {
-	return s.QueueName
+	queue.Consumer = newConsumer(messagingSystemSqs, queueName, deadLetterQueueName, retryPolicy, concurrency, verifier, messageProcessor, queue)
+
+	return queue
 }
 
 func (s *AwsSqsService) UpdateQueueUrl(ctx context.Context) error {
@@ -62,6 +60,19 @@ func (s *AwsSqsService) UpdateQueueUrl(ctx context.Context) error {
 
 	s.QueueUrl = *output.QueueUrl
 
+	if s.DeadLetterQueueName == "" {
+		return nil
+	}
+
+	output, err = s.Client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
+		QueueName: &s.DeadLetterQueueName,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.DeadLetterQueueUrl = *output.QueueUrl
+
 	return nil
 }
 
@@ -70,63 +81,225 @@ func (s *AwsSqsService) ConsumeMessages(ctx context.Context) {
 		QueueUrl:            &s.QueueUrl,
 		MaxNumberOfMessages: 10,
 		WaitTimeSeconds:     20,
+		AttributeNames: []types.QueueAttributeName{
+			types.QueueAttributeName(types.MessageSystemAttributeNameApproximateReceiveCount),
+		},
+		MessageAttributeNames: []string{"All"},
 	})
 	if err != nil {
-		slog.ErrorContext(ctx, "error receiving message from queue", "queue_url", s.QueueUrl, "error", err)
+		// a cancelled long poll is the consumer being stopped
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "error receiving message from queue", "queue_url", s.QueueUrl, "error", err)
+		}
 		return
 	}
 
-	s.WaitGroup.Add(len(output.Messages))
+	deliveries := make([]Delivery, 0, len(output.Messages))
 
 	for _, message := range output.Messages {
-		go s.processMessage(ctx, message)
+		deliveries = append(deliveries, Delivery{
+			Id:           *message.MessageId,
+			Body:         *message.Body,
+			Attributes:   getMessageAttributes(message),
+			ReceiveCount: getReceiveCount(message),
+			Handle:       *message.ReceiptHandle,
+		})
 	}
 
-	s.WaitGroup.Wait()
+	s.dispatch(ctx, deliveries)
 }
 
-func (s *AwsSqsService) processMessage(ctx context.Context, message types.Message) {
-	defer s.WaitGroup.Done()
-	s.Mutex.Lock()
+func (s *AwsSqsService) ack(ctx context.Context, delivery Delivery) error {
+	_, err := s.Client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      &s.QueueUrl,
+		ReceiptHandle: aws.String(delivery.Handle),
+	})
+	if err != nil {
+		return err
+	}
 
-	slog.InfoContext(ctx, "message received", "message_id", *message.MessageId)
+	return nil
+}
 
+func (s *AwsSqsService) retry(ctx context.Context, delivery Delivery, delay time.Duration) error {
+	_, err := s.Client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &s.QueueUrl,
+		ReceiptHandle:     aws.String(delivery.Handle),
+		VisibilityTimeout: int32(delay.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *AwsSqsService) deadLetter(ctx context.Context, delivery Delivery, failure Failure) error {
+	if s.DeadLetterQueueUrl == "" {
+		return errNoDeadLetterQueue
+	}
+
+	_, err := s.Client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          &s.DeadLetterQueueUrl,
+		MessageBody:       aws.String(delivery.Body),
+		MessageAttributes: messageAttributes(deadLetterAttributes(s.QueueName, delivery, failure)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Envelope is how a message was wrapped on the queue
+type Envelope string
+
+const (
+	EnvelopeNotification Envelope = "notification"
+	EnvelopeCloudEvent   Envelope = "cloudevent"
+	EnvelopeRaw          Envelope = "raw"
+)
+
+// Signed reports whether the envelope carries a signature that can be verified,
+// only the SNS notifications do
+func (e Envelope) Signed() bool {
+	return e == EnvelopeNotification
+}
+
+// decodeMessage accepts SNS notifications, CloudEvents in structured mode and
+// raw deliveries, reporting which one it found
+func decodeMessage(delivery Delivery) (Envelope, TopicNotification, process.ProcessMessageDto, error) {
 	var notification TopicNotification
+	var request process.ProcessMessageDto
+
+	body := []byte(delivery.Body)
+
+	var attributes map[string]json.RawMessage
 
-	if err := json.Unmarshal([]byte(*message.Body), &notification); err != nil {
-		slog.ErrorContext(ctx, "error unmarshalling message", "error", err)
-	} else {
-		if notification.Type != "Notification" {
-			slog.ErrorContext(ctx, "message is not a notification", "message_id", *message.MessageId)
-		} else {
-			var request process.ProcessMessageDto
-
-			if err := json.Unmarshal([]byte(notification.Message), &request); err != nil {
-				slog.ErrorContext(ctx, "error unmarshalling message", "message_id", *message.MessageId, "error", err)
-			} else {
-				slog.InfoContext(ctx, "message unmarshalled", "request", request)
-				if err := s.MessageProcessor.Handle(ctx, request); err != nil {
-					slog.ErrorContext(ctx, "error processing message", "message_id", *message.MessageId, "error", err)
-				}
-			}
+	if err := json.Unmarshal(body, &attributes); err != nil {
+		return EnvelopeRaw, notification, request, NewPermanentFailure("invalid message", err)
+	}
+
+	_, hasType := attributes["Type"]
+	_, hasTopicArn := attributes["TopicArn"]
+	_, hasSpecVersion := attributes["specversion"]
+
+	if hasSpecVersion {
+		request, err := decodePayload(body, delivery.Id)
+		return EnvelopeCloudEvent, notification, request, err
+	}
+
+	if !hasType && !hasTopicArn {
+		request, err := decodePayload(body, delivery.Id)
+		return EnvelopeRaw, notification, request, err
+	}
+
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return EnvelopeNotification, notification, request, NewPermanentFailure("invalid notification", err)
+	}
+
+	if notification.Type != "Notification" {
+		return EnvelopeNotification, notification, request, NewPermanentFailure("unexpected notification type", fmt.Errorf("notification type %q is not supported", notification.Type))
+	}
+
+	request, err := decodePayload([]byte(notification.Message), notification.MessageId)
+
+	return EnvelopeNotification, notification, request, err
+}
+
+// decodePayload reads the request either from the data of a CloudEvent, using
+// its id to detect redeliveries, or from the payload itself
+func decodePayload(payload []byte, messageId string) (process.ProcessMessageDto, error) {
+	var request process.ProcessMessageDto
+
+	var probe struct {
+		SpecVersion string `json:"specversion"`
+	}
+
+	// the payload may not be an object, it is reported by the unmarshal below
+	_ = json.Unmarshal(payload, &probe)
+
+	if probe.SpecVersion != "" {
+		event, err := ParseCloudEvent(payload)
+		if err != nil {
+			return request, NewPermanentFailure("invalid cloudevent", err)
 		}
+
+		payload = event.Data
+		messageId = event.Id
 	}
 
-	if err := s.deleteMessage(ctx, message); err != nil {
-		slog.ErrorContext(ctx, "error deleting message", "message_id", *message.MessageId, "error", err)
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return request, NewPermanentFailure("invalid payload", err)
 	}
 
-	s.Mutex.Unlock()
+	request.MessageId = messageId
+
+	return request, nil
 }
 
-func (s *AwsSqsService) deleteMessage(ctx context.Context, message types.Message) error {
-	_, err := s.Client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
-		QueueUrl:      &s.QueueUrl,
-		ReceiptHandle: message.ReceiptHandle,
-	})
-	if err != nil {
-		return err
+func getReceiveCount(message types.Message) int {
+	value, ok := message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]
+	if !ok {
+		return 1
 	}
 
-	return nil
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 {
+		return 1
+	}
+
+	return count
+}
+
+// getMessageAttributes returns the string attributes sent with the message,
+// such as the trace context of a message sent straight to the queue
+func getMessageAttributes(message types.Message) map[string]string {
+	attributes := make(map[string]string, len(message.MessageAttributes))
+
+	for name, value := range message.MessageAttributes {
+		if value.StringValue != nil {
+			attributes[name] = *value.StringValue
+		}
+	}
+
+	return attributes
+}
+
+// deadLetterAttributes describes why a message was dead-lettered, they are
+// kept along with the original body
+func deadLetterAttributes(queueName string, delivery Delivery, failure Failure) map[string]string {
+	return map[string]string{
+		"SourceQueue":       queueName,
+		"OriginalMessageId": delivery.Id,
+		"FailureClass":      string(failure.Class),
+		"FailureReason":     failure.Reason,
+		"FailureError":      failure.Error(),
+		"ReceiveCount":      strconv.Itoa(delivery.ReceiveCount),
+	}
+}
+
+func messageAttributes(attributes map[string]string) map[string]types.MessageAttributeValue {
+	values := make(map[string]types.MessageAttributeValue, len(attributes))
+
+	for name, value := range attributes {
+		values[name] = stringAttribute(value)
+	}
+
+	// the receive count keeps the numeric type it always had
+	if value, ok := attributes["ReceiveCount"]; ok {
+		values["ReceiveCount"] = types.MessageAttributeValue{
+			DataType:    aws.String("Number"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	return values
+}
+
+func stringAttribute(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
 }
@@ -3,7 +3,9 @@ package cloud
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -11,16 +13,28 @@ import (
 	"github.com/awsdocs/aws-doc-sdk-examples/gov2/testtools"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/audit"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+var testRetryPolicy = RetryPolicy{
+	MaxReceives: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    time.Minute,
+}
+
+var receiveAttributeNames = []types.QueueAttributeName{
+	types.QueueAttributeName(types.MessageSystemAttributeNameApproximateReceiveCount),
+}
+
 func TestGetQueueName(t *testing.T) {
 	t.Run("Should return queue name", func(t *testing.T) {
 		// Arrange
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		service := NewQueueService("test-queue", aws.Config{}, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, aws.Config{}, fakeProcessor)
 
 		// Act
 		queueName := service.GetQueueName()
@@ -48,7 +62,7 @@ func TestUpdateQueueUrl(t *testing.T) {
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
 		// Act
 		err := service.UpdateQueueUrl(ctx)
@@ -72,7 +86,7 @@ func TestUpdateQueueUrl(t *testing.T) {
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
 		// Act
 		err := service.UpdateQueueUrl(ctx)
@@ -114,9 +128,11 @@ func TestStartConsuming(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "ReceiveMessage",
 			Input: &sqs.ReceiveMessageInput{
-				QueueUrl:            aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
-				MaxNumberOfMessages: 10,
-				WaitTimeSeconds:     20,
+				QueueUrl:              aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				MaxNumberOfMessages:   10,
+				WaitTimeSeconds:       20,
+				AttributeNames:        receiveAttributeNames,
+				MessageAttributeNames: []string{"All"},
 			},
 			Output: &sqs.ReceiveMessageOutput{
 				Messages: []types.Message{
@@ -154,17 +170,23 @@ func TestStartConsuming(t *testing.T) {
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		fakeProcessor.On("Handle", ctx, mock.Anything).
+		fakeProcessor.On("Handle", mock.MatchedBy(func(ctx context.Context) bool {
+			origin := audit.FromContext(ctx)
+			return origin.Source == audit.SourceQueue && origin.Actor == "test-queue" && origin.SourceId != ""
+		}), mock.MatchedBy(func(request process.ProcessMessageDto) bool {
+			return request.MessageId == "fc8e9ffd-6122-5c52-8fb9-c13e3ee2629a"
+		})).
 			Return(nil).
 			Times(2)
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
 		err := service.UpdateQueueUrl(ctx)
 		assert.NoError(t, err)
 
 		// Act
 		service.ConsumeMessages(ctx)
+		service.Wait()
 
 		// Assert
 		testtools.ExitTest(stubber, t)
@@ -201,9 +223,11 @@ func TestStartConsuming(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "ReceiveMessage",
 			Input: &sqs.ReceiveMessageInput{
-				QueueUrl:            aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
-				MaxNumberOfMessages: 10,
-				WaitTimeSeconds:     20,
+				QueueUrl:              aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				MaxNumberOfMessages:   10,
+				WaitTimeSeconds:       20,
+				AttributeNames:        receiveAttributeNames,
+				MessageAttributeNames: []string{"All"},
 			},
 			Output: &sqs.ReceiveMessageOutput{
 				Messages: []types.Message{
@@ -227,13 +251,14 @@ func TestStartConsuming(t *testing.T) {
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
 		err := service.UpdateQueueUrl(ctx)
 		assert.NoError(t, err)
 
 		// Act
 		service.ConsumeMessages(ctx)
+		service.Wait()
 
 		// Assert
 		testtools.ExitTest(stubber, t)
@@ -270,9 +295,11 @@ func TestStartConsuming(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "ReceiveMessage",
 			Input: &sqs.ReceiveMessageInput{
-				QueueUrl:            aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
-				MaxNumberOfMessages: 10,
-				WaitTimeSeconds:     20,
+				QueueUrl:              aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				MaxNumberOfMessages:   10,
+				WaitTimeSeconds:       20,
+				AttributeNames:        receiveAttributeNames,
+				MessageAttributeNames: []string{"All"},
 			},
 			Output: &sqs.ReceiveMessageOutput{
 				Messages: []types.Message{
@@ -296,13 +323,14 @@ func TestStartConsuming(t *testing.T) {
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
 		err := service.UpdateQueueUrl(ctx)
 		assert.NoError(t, err)
 
 		// Act
 		service.ConsumeMessages(ctx)
+		service.Wait()
 
 		// Assert
 		testtools.ExitTest(stubber, t)
@@ -339,9 +367,11 @@ func TestStartConsuming(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "ReceiveMessage",
 			Input: &sqs.ReceiveMessageInput{
-				QueueUrl:            aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
-				MaxNumberOfMessages: 10,
-				WaitTimeSeconds:     20,
+				QueueUrl:              aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				MaxNumberOfMessages:   10,
+				WaitTimeSeconds:       20,
+				AttributeNames:        receiveAttributeNames,
+				MessageAttributeNames: []string{"All"},
 			},
 			Output: &sqs.ReceiveMessageOutput{
 				Messages: []types.Message{
@@ -365,20 +395,21 @@ func TestStartConsuming(t *testing.T) {
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
 		err := service.UpdateQueueUrl(ctx)
 		assert.NoError(t, err)
 
 		// Act
 		service.ConsumeMessages(ctx)
+		service.Wait()
 
 		// Assert
 		testtools.ExitTest(stubber, t)
 		fakeProcessor.AssertExpectations(t)
 	})
 
-	t.Run("Should log error when something goes wrong when processing the message", func(t *testing.T) {
+	t.Run("Should retry the message when a transient error happens", func(t *testing.T) {
 		// Arrange
 		ctx := context.Background()
 		stubber := testtools.NewStubber()
@@ -408,9 +439,11 @@ func TestStartConsuming(t *testing.T) {
 		stubber.Add(testtools.Stub{
 			OperationName: "ReceiveMessage",
 			Input: &sqs.ReceiveMessageInput{
-				QueueUrl:            aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
-				MaxNumberOfMessages: 10,
-				WaitTimeSeconds:     20,
+				QueueUrl:              aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				MaxNumberOfMessages:   10,
+				WaitTimeSeconds:       20,
+				AttributeNames:        receiveAttributeNames,
+				MessageAttributeNames: []string{"All"},
 			},
 			Output: &sqs.ReceiveMessageOutput{
 				Messages: []types.Message{
@@ -424,30 +457,518 @@ func TestStartConsuming(t *testing.T) {
 		})
 
 		stubber.Add(testtools.Stub{
-			OperationName: "DeleteMessage",
-			Input: &sqs.DeleteMessageInput{
-				QueueUrl:      aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
-				ReceiptHandle: aws.String("1234567890"),
+			OperationName: "ChangeMessageVisibility",
+			Input: &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				ReceiptHandle:     aws.String("1234567890"),
+				VisibilityTimeout: 1,
 			},
-			Output: &sqs.DeleteMessageOutput{},
+			Output: &sqs.ChangeMessageVisibilityOutput{},
 		})
 
 		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
 
-		fakeProcessor.On("Handle", ctx, mock.Anything).
+		fakeProcessor.On("Handle", mock.Anything, mock.Anything).
 			Return(assert.AnError).
 			Once()
 
-		service := NewQueueService("test-queue", *stubber.SdkConfig, fakeProcessor)
+		service := NewQueueService("test-queue", "", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
+
+		err := service.UpdateQueueUrl(ctx)
+		assert.NoError(t, err)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		testtools.ExitTest(stubber, t)
+		fakeProcessor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{Retried: 1}, service.(*AwsSqsService).GetStats())
+	})
+}
+
+func TestUpdateQueueUrlWithDeadLetterQueue(t *testing.T) {
+	t.Run("Should resolve the dead-letter queue url", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetQueueUrl",
+			Input: &sqs.GetQueueUrlInput{
+				QueueName: aws.String("test-queue"),
+			},
+			Output: &sqs.GetQueueUrlOutput{
+				QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+			},
+		})
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetQueueUrl",
+			Input: &sqs.GetQueueUrlInput{
+				QueueName: aws.String("test-dlq"),
+			},
+			Output: &sqs.GetQueueUrlOutput{
+				QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-dlq"),
+			},
+		})
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		service := NewQueueService("test-queue", "test-dlq", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
 
+		// Act
 		err := service.UpdateQueueUrl(ctx)
+
+		// Assert
 		assert.NoError(t, err)
+		assert.Equal(t, "https://sqs.us-east-1.amazonaws.com/123456789012/test-dlq", service.(*AwsSqsService).DeadLetterQueueUrl)
+		testtools.ExitTest(stubber, t)
+	})
+
+	t.Run("Should return error when the dead-letter queue is not found", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		raiseErr := &testtools.StubError{Err: errors.New("ClientError")}
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetQueueUrl",
+			Input: &sqs.GetQueueUrlInput{
+				QueueName: aws.String("test-queue"),
+			},
+			Output: &sqs.GetQueueUrlOutput{
+				QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+			},
+		})
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetQueueUrl",
+			Error:         raiseErr,
+		})
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		service := NewQueueService("test-queue", "test-dlq", testRetryPolicy, 2, nil, *stubber.SdkConfig, fakeProcessor)
+
+		// Act
+		err := service.UpdateQueueUrl(ctx)
+
+		// Assert
+		testtools.VerifyError(err, raiseErr, t)
+		testtools.ExitTest(stubber, t)
+	})
+}
+
+func TestDeadLetter(t *testing.T) {
+	response := `{
+		"Type" : "Notification",
+		"MessageId" : "fc8e9ffd-6122-5c52-8fb9-c13e3ee2629a",
+		"TopicArn" : "arn:aws:sns:us-east-1:000000000000:OrderPaymentTopic",
+		"Message" : "{\"order_id\":\"be6293ff-4ec0-4ed8-95c9-b36ce99aa105\",\"payment_response\":{\"payment_id\":\"a5c81ac9-a549-44c5-bb09-c330116b929f\",\"state\":\"Approved\"}}",
+		"Timestamp" : "2024-05-19T02:01:36.927Z"
+	}`
+
+	newService := func(stubber *testtools.AwsmStubber, processor *mocks.MockProcessMessageService[process.ProcessMessageDto]) *AwsSqsService {
+		service := NewQueueService("test-queue", "test-dlq", testRetryPolicy, 2, nil, *stubber.SdkConfig, processor).(*AwsSqsService)
+		service.QueueUrl = "https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"
+		service.DeadLetterQueueUrl = "https://sqs.us-east-1.amazonaws.com/123456789012/test-dlq"
+
+		return service
+	}
+
+	receive := func(receiveCount string) testtools.Stub {
+		return testtools.Stub{
+			OperationName: "ReceiveMessage",
+			Input: &sqs.ReceiveMessageInput{
+				QueueUrl:              aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				MaxNumberOfMessages:   10,
+				WaitTimeSeconds:       20,
+				AttributeNames:        receiveAttributeNames,
+				MessageAttributeNames: []string{"All"},
+			},
+			Output: &sqs.ReceiveMessageOutput{
+				Messages: []types.Message{
+					{
+						MessageId:     aws.String("fc8e9ffd-6122-5c52-8fb9-c13e3ee2629a"),
+						Body:          aws.String(response),
+						ReceiptHandle: aws.String("1234567890"),
+						Attributes: map[string]string{
+							"ApproximateReceiveCount": receiveCount,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	sendToDeadLetterQueue := func(failureClass, reason, failureError, receiveCount string) testtools.Stub {
+		return testtools.Stub{
+			OperationName: "SendMessage",
+			Input: &sqs.SendMessageInput{
+				QueueUrl:    aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-dlq"),
+				MessageBody: aws.String(response),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"SourceQueue":       stringAttribute("test-queue"),
+					"OriginalMessageId": stringAttribute("fc8e9ffd-6122-5c52-8fb9-c13e3ee2629a"),
+					"FailureClass":      stringAttribute(failureClass),
+					"FailureReason":     stringAttribute(reason),
+					"FailureError":      stringAttribute(failureError),
+					"ReceiveCount": {
+						DataType:    aws.String("Number"),
+						StringValue: aws.String(receiveCount),
+					},
+				},
+			},
+			Output: &sqs.SendMessageOutput{},
+		}
+	}
+
+	deleteMessage := testtools.Stub{
+		OperationName: "DeleteMessage",
+		Input: &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+			ReceiptHandle: aws.String("1234567890"),
+		},
+		Output: &sqs.DeleteMessageOutput{},
+	}
+
+	t.Run("Should send a permanent failure to the dead-letter queue", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(receive("1"))
+		stubber.Add(sendToDeadLetterQueue("permanent", "invalid state transition", "invalid state transition", "1"))
+		stubber.Add(deleteMessage)
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		fakeProcessor.On("Handle", mock.Anything, mock.Anything).
+			Return(custom_error.ErrPaymentInvalidStateTransition).
+			Once()
+
+		service := newService(stubber, fakeProcessor)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		testtools.ExitTest(stubber, t)
+		fakeProcessor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, service.GetStats())
+	})
+
+	t.Run("Should send a transient failure to the dead-letter queue after the max receives", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(receive("3"))
+		stubber.Add(sendToDeadLetterQueue("transient", "max receives exceeded", assert.AnError.Error(), "3"))
+		stubber.Add(deleteMessage)
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		fakeProcessor.On("Handle", mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		service := newService(stubber, fakeProcessor)
 
 		// Act
 		service.ConsumeMessages(ctx)
+		service.Wait()
 
 		// Assert
 		testtools.ExitTest(stubber, t)
 		fakeProcessor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, service.GetStats())
 	})
+
+	t.Run("Should back off based on the receive count", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(receive("2"))
+		stubber.Add(testtools.Stub{
+			OperationName: "ChangeMessageVisibility",
+			Input: &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/test-queue"),
+				ReceiptHandle:     aws.String("1234567890"),
+				VisibilityTimeout: 2,
+			},
+			Output: &sqs.ChangeMessageVisibilityOutput{},
+		})
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		fakeProcessor.On("Handle", mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		service := newService(stubber, fakeProcessor)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		testtools.ExitTest(stubber, t)
+		fakeProcessor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{Retried: 1}, service.GetStats())
+	})
+
+	t.Run("Should send an unsigned message to the dead-letter queue", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(receive("1"))
+		stubber.Add(sendToDeadLetterQueue("permanent", "invalid signature", `message signature not valid: unsupported signature version ""`, "1"))
+		stubber.Add(deleteMessage)
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		service := newService(stubber, fakeProcessor)
+		service.Verifier = NewSnsSignatureVerifier(&staticCertificateFetcher{}, []string{testTopicArn})
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		testtools.ExitTest(stubber, t)
+		fakeProcessor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{DeadLettered: 1}, service.GetStats())
+	})
+
+	t.Run("Should keep the message when the dead-letter queue can not be reached", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(receive("1"))
+		stubber.Add(testtools.Stub{
+			OperationName: "SendMessage",
+			Error:         &testtools.StubError{Err: errors.New("ClientError")},
+		})
+
+		fakeProcessor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		fakeProcessor.On("Handle", mock.Anything, mock.Anything).
+			Return(custom_error.ErrPaymentInvalidStateTransition).
+			Once()
+
+		service := newService(stubber, fakeProcessor)
+
+		// Act
+		service.ConsumeMessages(ctx)
+		service.Wait()
+
+		// Assert
+		testtools.ExitTest(stubber, t)
+		fakeProcessor.AssertExpectations(t)
+		assert.Equal(t, QueueStats{}, service.GetStats())
+	})
+}
+
+func TestDecodeMessage(t *testing.T) {
+	t.Run("Should decode a raw delivery with the sqs message id", func(t *testing.T) {
+		// Arrange
+		delivery := Delivery{
+			Id:   "sqs-message-id",
+			Body: `{"order_id":"order-id","payment":{"id":"payment-id","state":"Approved"}}`,
+		}
+
+		// Act
+		envelope, notification, request, err := decodeMessage(delivery)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, EnvelopeRaw, envelope)
+		assert.Empty(t, notification.Type)
+		assert.Equal(t, process.ProcessMessageDto{
+			MessageId: "sqs-message-id",
+			OrderId:   "order-id",
+			PaymentResponse: &process.PaymentResponse{
+				PaymentId: "payment-id",
+				State:     "Approved",
+			},
+		}, request)
+	})
+
+	t.Run("Should decode a cloudevent with the event id", func(t *testing.T) {
+		// Arrange
+		delivery := Delivery{
+			Id:   "sqs-message-id",
+			Body: `{"specversion":"1.0","id":"event-id","source":"ms-payment","type":"PaymentApproved","subject":"order-id","data":{"order_id":"order-id","payment":{"id":"payment-id","state":"Approved"}}}`,
+		}
+
+		// Act
+		envelope, _, request, err := decodeMessage(delivery)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, EnvelopeCloudEvent, envelope)
+		assert.Equal(t, "event-id", request.MessageId)
+		assert.Equal(t, "order-id", request.OrderId)
+		assert.Equal(t, "Approved", request.PaymentResponse.State)
+	})
+
+	t.Run("Should decode a cloudevent delivered through a notification", func(t *testing.T) {
+		// Arrange
+		delivery := Delivery{
+			Id: "sqs-message-id",
+			Body: `{
+				"Type": "Notification",
+				"MessageId": "sns-message-id",
+				"TopicArn": "arn:aws:sns:us-east-1:000000000000:OrderEventsTopic",
+				"Message": "{\"specversion\":\"1.0\",\"id\":\"event-id\",\"source\":\"ms-payment\",\"type\":\"PaymentApproved\",\"data\":{\"order_id\":\"order-id\",\"order\":{\"state\":\"Received\"}}}"
+			}`,
+		}
+
+		// Act
+		envelope, notification, request, err := decodeMessage(delivery)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, EnvelopeNotification, envelope)
+		assert.Equal(t, "sns-message-id", notification.MessageId)
+		assert.Equal(t, "event-id", request.MessageId)
+		assert.Equal(t, "Received", request.OrderResponse.State)
+	})
+
+	t.Run("Should return a permanent failure when the cloudevent is not valid", func(t *testing.T) {
+		// Arrange
+		delivery := Delivery{
+			Id:   "sqs-message-id",
+			Body: `{"specversion":"1.0","id":"event-id","type":"PaymentApproved","data":{}}`,
+		}
+
+		// Act
+		_, _, _, err := decodeMessage(delivery)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, FailurePermanent, ClassifyFailure(err).Class)
+	})
+
+	t.Run("Should return a permanent failure when the body is not an object", func(t *testing.T) {
+		// Arrange
+		delivery := Delivery{
+			Id:   "sqs-message-id",
+			Body: `"order-id"`,
+		}
+
+		// Act
+		_, _, _, err := decodeMessage(delivery)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, FailurePermanent, ClassifyFailure(err).Class)
+	})
+}
+
+type awsQueueFixture struct {
+	queueName           string
+	deadLetterQueueName string
+	stubber             *testtools.AwsmStubber
+}
+
+func newAwsQueueFixture(t *testing.T, queueName string, deadLetterQueueName string) queueFixture {
+	return &awsQueueFixture{
+		queueName:           queueName,
+		deadLetterQueueName: deadLetterQueueName,
+		stubber:             testtools.NewStubber(),
+	}
+}
+
+func (f *awsQueueFixture) service(t *testing.T, processor *mocks.MockProcessMessageService[process.ProcessMessageDto]) QueueService {
+	service := NewQueueService(f.queueName, f.deadLetterQueueName, testRetryPolicy, 2, nil, *f.stubber.SdkConfig, processor).(*AwsSqsService)
+	service.QueueUrl = queueUrl(f.queueName)
+
+	if f.deadLetterQueueName != "" {
+		service.DeadLetterQueueUrl = queueUrl(f.deadLetterQueueName)
+	}
+
+	return service
+}
+
+func (f *awsQueueFixture) receive(t *testing.T, id string, body string, receiveCount int) {
+	f.stubber.Add(testtools.Stub{
+		OperationName: "ReceiveMessage",
+		Input: &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(queueUrl(f.queueName)),
+			MaxNumberOfMessages:   10,
+			WaitTimeSeconds:       20,
+			AttributeNames:        receiveAttributeNames,
+			MessageAttributeNames: []string{"All"},
+		},
+		Output: &sqs.ReceiveMessageOutput{
+			Messages: []types.Message{
+				{
+					MessageId:     aws.String(id),
+					Body:          aws.String(body),
+					ReceiptHandle: aws.String("receipt-" + id),
+					Attributes: map[string]string{
+						"ApproximateReceiveCount": strconv.Itoa(receiveCount),
+					},
+				},
+			},
+		},
+	})
+}
+
+func (f *awsQueueFixture) expectAck(t *testing.T, id string) {
+	f.stubber.Add(testtools.Stub{
+		OperationName: "DeleteMessage",
+		Input: &sqs.DeleteMessageInput{
+			QueueUrl:      aws.String(queueUrl(f.queueName)),
+			ReceiptHandle: aws.String("receipt-" + id),
+		},
+		Output: &sqs.DeleteMessageOutput{},
+	})
+}
+
+func (f *awsQueueFixture) expectRetry(t *testing.T, id string, delay time.Duration) {
+	f.stubber.Add(testtools.Stub{
+		OperationName: "ChangeMessageVisibility",
+		Input: &sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(queueUrl(f.queueName)),
+			ReceiptHandle:     aws.String("receipt-" + id),
+			VisibilityTimeout: int32(delay.Seconds()),
+		},
+		Output: &sqs.ChangeMessageVisibilityOutput{},
+	})
+}
+
+func (f *awsQueueFixture) expectDeadLetter(t *testing.T, id string, body string, attributes map[string]string) {
+	f.stubber.Add(testtools.Stub{
+		OperationName: "SendMessage",
+		Input: &sqs.SendMessageInput{
+			QueueUrl:          aws.String(queueUrl(f.deadLetterQueueName)),
+			MessageBody:       aws.String(body),
+			MessageAttributes: messageAttributes(attributes),
+		},
+		Output: &sqs.SendMessageOutput{},
+	})
+
+	f.expectAck(t, id)
+}
+
+func (f *awsQueueFixture) verify(t *testing.T) {
+	testtools.ExitTest(f.stubber, t)
+}
+
+func queueUrl(queueName string) string {
+	return "https://sqs.us-east-1.amazonaws.com/123456789012/" + queueName
+}
+
+func TestAwsQueueContract(t *testing.T) {
+	runQueueContract(t, newAwsQueueFixture)
 }
@@ -0,0 +1,112 @@
+package cloud
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+// SQS does not accept visibility timeouts longer than 12 hours
+const maxVisibilityTimeout = 12 * time.Hour
+
+type FailureClass string
+
+const (
+	// FailurePermanent marks a message that will never succeed, no matter how
+	// many times it is delivered (e.g. invalid payload, invalid transition)
+	FailurePermanent FailureClass = "permanent"
+	// FailureTransient marks a message that may succeed on a later delivery
+	// (e.g. database or network errors)
+	FailureTransient FailureClass = "transient"
+)
+
+type Failure struct {
+	Class  FailureClass
+	Reason string
+	Err    error
+}
+
+func (f *Failure) Error() string {
+	return f.Err.Error()
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+func NewPermanentFailure(reason string, err error) error {
+	return &Failure{
+		Class:  FailurePermanent,
+		Reason: reason,
+		Err:    err,
+	}
+}
+
+// ClassifyFailure treats business errors as permanent, since they describe a
+// message that can not be applied, and everything else as transient. A version
+// conflict is transient as the order is read again on the next delivery
+func ClassifyFailure(err error) Failure {
+	var failure *Failure
+	if errors.As(err, &failure) {
+		return *failure
+	}
+
+	if errors.Is(err, custom_error.ErrOrderVersionConflict) {
+		return Failure{
+			Class:  FailureTransient,
+			Reason: "version conflict",
+			Err:    err,
+		}
+	}
+
+	var businessErr custom_error.BusinessError
+	if errors.As(err, &businessErr) && businessErr.Code() < 500 {
+		return Failure{
+			Class:  FailurePermanent,
+			Reason: businessErr.Error(),
+			Err:    err,
+		}
+	}
+
+	return Failure{
+		Class:  FailureTransient,
+		Reason: "processing failed",
+		Err:    err,
+	}
+}
+
+type RetryPolicy struct {
+	MaxReceives int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// ShouldRetry reports whether a transient failure can still be redelivered
+func (p RetryPolicy) ShouldRetry(receiveCount int) bool {
+	return receiveCount < p.MaxReceives
+}
+
+// Backoff returns the visibility timeout to apply before the next delivery,
+// doubling the base delay for each receive already done
+func (p RetryPolicy) Backoff(receiveCount int) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 || maxDelay > maxVisibilityTimeout {
+		maxDelay = maxVisibilityTimeout
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < receiveCount; i++ {
+		delay *= 2
+
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,110 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	t.Run("Should keep the class of a failure", func(t *testing.T) {
+		// Arrange
+		err := NewPermanentFailure("invalid payload", assert.AnError)
+
+		// Act
+		failure := ClassifyFailure(err)
+
+		// Assert
+		assert.Equal(t, FailurePermanent, failure.Class)
+		assert.Equal(t, "invalid payload", failure.Reason)
+		assert.ErrorIs(t, failure.Err, assert.AnError)
+	})
+
+	t.Run("Should classify a business error as permanent", func(t *testing.T) {
+		// Arrange
+		err := fmt.Errorf("wrapped: %w", custom_error.ErrOrderInvalidStateTransition)
+
+		// Act
+		failure := ClassifyFailure(err)
+
+		// Assert
+		assert.Equal(t, FailurePermanent, failure.Class)
+		assert.Equal(t, "invalid state transition", failure.Reason)
+	})
+
+	t.Run("Should classify a version conflict as transient", func(t *testing.T) {
+		// Arrange
+		err := fmt.Errorf("wrapped: %w", custom_error.ErrOrderVersionConflict)
+
+		// Act
+		failure := ClassifyFailure(err)
+
+		// Assert
+		assert.Equal(t, FailureTransient, failure.Class)
+		assert.Equal(t, "version conflict", failure.Reason)
+	})
+
+	t.Run("Should classify a server business error as transient", func(t *testing.T) {
+		// Arrange
+		err := custom_error.New(500, "title", "message")
+
+		// Act
+		failure := ClassifyFailure(err)
+
+		// Assert
+		assert.Equal(t, FailureTransient, failure.Class)
+	})
+
+	t.Run("Should classify any other error as transient", func(t *testing.T) {
+		// Arrange
+		err := errors.New("connection refused")
+
+		// Act
+		failure := ClassifyFailure(err)
+
+		// Assert
+		assert.Equal(t, FailureTransient, failure.Class)
+		assert.Equal(t, "processing failed", failure.Reason)
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{
+		MaxReceives: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    5 * time.Second,
+	}
+
+	t.Run("Should retry while below the max receives", func(t *testing.T) {
+		// Act & Assert
+		assert.True(t, policy.ShouldRetry(2))
+		assert.False(t, policy.ShouldRetry(3))
+	})
+
+	t.Run("Should double the delay on each receive", func(t *testing.T) {
+		// Act & Assert
+		assert.Equal(t, time.Second, policy.Backoff(1))
+		assert.Equal(t, 2*time.Second, policy.Backoff(2))
+		assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	})
+
+	t.Run("Should cap the delay", func(t *testing.T) {
+		// Act & Assert
+		assert.Equal(t, 5*time.Second, policy.Backoff(4))
+		assert.Equal(t, 5*time.Second, policy.Backoff(100))
+	})
+
+	t.Run("Should cap the delay to the SQS visibility limit", func(t *testing.T) {
+		// Arrange
+		policy := RetryPolicy{
+			BaseDelay: time.Hour,
+		}
+
+		// Act & Assert
+		assert.Equal(t, 12*time.Hour, policy.Backoff(10))
+	})
+}
@@ -0,0 +1,116 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+const (
+	SecretProviderEnv  = "env"
+	SecretProviderFile = "file"
+	SecretProviderAws  = "aws"
+)
+
+// EnvSecretService returns the secrets already loaded with the environment,
+// keyed by the secret name, so a local run does not need Secrets Manager
+type EnvSecretService struct {
+	Values map[string]string
+}
+
+func NewEnvSecretService(values map[string]string) SecretService {
+	return &EnvSecretService{
+		Values: values,
+	}
+}
+
+func (s *EnvSecretService) GetSecret(ctx context.Context, secretName string) (string, error) {
+	return s.Values[secretName], nil
+}
+
+// FileSecretService reads each secret from a file named after it, as a
+// Kubernetes secret or the secrets store CSI driver mounts them. The file is
+// read on every call so a rotated secret is seen once the mount is updated
+type FileSecretService struct {
+	Directory string
+}
+
+func NewFileSecretService(directory string) SecretService {
+	return &FileSecretService{
+		Directory: directory,
+	}
+}
+
+func (s *FileSecretService) GetSecret(ctx context.Context, secretName string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(s.Directory, secretName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// ChainSecretService asks each provider in turn and returns the first secret
+// found, a provider that does not know the secret is skipped
+type ChainSecretService struct {
+	Providers []SecretService
+}
+
+func NewChainSecretService(providers ...SecretService) SecretService {
+	return &ChainSecretService{
+		Providers: providers,
+	}
+}
+
+func (s *ChainSecretService) GetSecret(ctx context.Context, secretName string) (string, error) {
+	for _, provider := range s.Providers {
+		secret, err := provider.GetSecret(ctx, secretName)
+
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			slog.DebugContext(ctx, "secret not found in provider", "secret_name", secretName, "error", err)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if secret != "" {
+			return secret, nil
+		}
+	}
+
+	return "", custom_error.ErrSecretNotFound
+}
+
+// NewSecretProviderChain builds the chain from the provider names, in the
+// order they are given
+func NewSecretProviderChain(providers []string, values map[string]string, directory string, config aws.Config) (SecretService, error) {
+	chain := make([]SecretService, 0, len(providers))
+
+	for _, provider := range providers {
+		switch strings.TrimSpace(provider) {
+		case SecretProviderEnv:
+			chain = append(chain, NewEnvSecretService(values))
+		case SecretProviderFile:
+			chain = append(chain, NewFileSecretService(directory))
+		case SecretProviderAws:
+			chain = append(chain, NewSecretService(config))
+		default:
+			return nil, fmt.Errorf("unknown secret provider: %s", provider)
+		}
+	}
+
+	return NewChainSecretService(chain...), nil
+}
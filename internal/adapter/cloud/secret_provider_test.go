@@ -0,0 +1,215 @@
+package cloud
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/awsdocs/aws-doc-sdk-examples/gov2/testtools"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvSecretService(t *testing.T) {
+	t.Run("Should return the secret set in the environment", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		service := NewEnvSecretService(map[string]string{"my-secret": "my-secret-value"})
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "my-secret-value", res)
+	})
+
+	t.Run("Should return empty string if secret not set", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		service := NewEnvSecretService(map[string]string{})
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
+
+func TestFileSecretService(t *testing.T) {
+	t.Run("Should return the content of the mounted file", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		directory := t.TempDir()
+		err := os.WriteFile(filepath.Join(directory, "my-secret"), []byte("my-secret-value\n"), 0o600)
+		assert.NoError(t, err)
+
+		service := NewFileSecretService(directory)
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "my-secret-value", res)
+	})
+
+	t.Run("Should return empty string if the file does not exist", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		service := NewFileSecretService(t.TempDir())
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("Should return error if the file can not be read", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		directory := t.TempDir()
+		err := os.Mkdir(filepath.Join(directory, "my-secret"), 0o700)
+		assert.NoError(t, err)
+
+		service := NewFileSecretService(directory)
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Empty(t, res)
+	})
+}
+
+func TestChainSecretService(t *testing.T) {
+	t.Run("Should return the secret of the first provider that has it", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		service := NewChainSecretService(
+			NewEnvSecretService(map[string]string{}),
+			NewEnvSecretService(map[string]string{"my-secret": "second-value"}),
+			NewEnvSecretService(map[string]string{"my-secret": "third-value"}),
+		)
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "second-value", res)
+	})
+
+	t.Run("Should skip a provider that does not find the secret", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		raiseErr := &testtools.StubError{Err: &types.ResourceNotFoundException{}}
+
+		stubber.Add(testtools.Stub{
+			OperationName: "GetSecretValue",
+			Input: &secretsmanager.GetSecretValueInput{
+				SecretId: aws.String("my-secret"),
+			},
+			Error: raiseErr,
+		})
+
+		service := NewChainSecretService(
+			NewSecretService(*stubber.SdkConfig),
+			NewEnvSecretService(map[string]string{"my-secret": "my-secret-value"}),
+		)
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "my-secret-value", res)
+		testtools.ExitTest(stubber, t)
+	})
+
+	t.Run("Should return error if a provider fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		directory := t.TempDir()
+		err := os.Mkdir(filepath.Join(directory, "my-secret"), 0o700)
+		assert.NoError(t, err)
+
+		service := NewChainSecretService(
+			NewFileSecretService(directory),
+			NewEnvSecretService(map[string]string{"my-secret": "my-secret-value"}),
+		)
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("Should return error if no provider has the secret", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		service := NewChainSecretService(
+			NewEnvSecretService(map[string]string{}),
+			NewFileSecretService(t.TempDir()),
+		)
+
+		// Act
+		res, err := service.GetSecret(ctx, "my-secret")
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrSecretNotFound)
+		assert.Empty(t, res)
+	})
+}
+
+func TestNewSecretProviderChain(t *testing.T) {
+	t.Run("Should build the providers in the given order", func(t *testing.T) {
+		// Arrange
+		stubber := testtools.NewStubber()
+
+		// Act
+		service, err := NewSecretProviderChain([]string{"file", "env", "aws"}, map[string]string{}, "/mnt/secrets", *stubber.SdkConfig)
+
+		// Assert
+		assert.NoError(t, err)
+
+		providers := service.(*ChainSecretService).Providers
+		assert.Len(t, providers, 3)
+		assert.IsType(t, &FileSecretService{}, providers[0])
+		assert.IsType(t, &EnvSecretService{}, providers[1])
+		assert.IsType(t, &AwsDbUrlSecretService{}, providers[2])
+	})
+
+	t.Run("Should return error if a provider is unknown", func(t *testing.T) {
+		// Arrange
+		stubber := testtools.NewStubber()
+
+		// Act
+		service, err := NewSecretProviderChain([]string{"vault"}, map[string]string{}, "", *stubber.SdkConfig)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, service)
+	})
+}
@@ -0,0 +1,261 @@
+package cloud
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+const maxCertificateSize = 64 * 1024
+
+var snsCertificateHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+type NotificationVerifier interface {
+	Verify(ctx context.Context, notification TopicNotification) error
+}
+
+// CertificateFetcher returns the certificates that may have signed a
+// notification, given the SigningCertURL it carries
+type CertificateFetcher interface {
+	Fetch(ctx context.Context, certificateUrl string) ([]*x509.Certificate, error)
+}
+
+// SnsSignatureVerifier trusts the notifications signed by SNS and published
+// to one of the allowed topics, any AWS account can have SNS sign a message
+// so the signature alone does not tell where the message came from
+type SnsSignatureVerifier struct {
+	fetcher          CertificateFetcher
+	allowedTopicArns map[string]bool
+}
+
+func NewSnsSignatureVerifier(fetcher CertificateFetcher, allowedTopicArns []string) *SnsSignatureVerifier {
+	verifier := &SnsSignatureVerifier{
+		fetcher:          fetcher,
+		allowedTopicArns: make(map[string]bool, len(allowedTopicArns)),
+	}
+
+	for _, topicArn := range allowedTopicArns {
+		verifier.AllowTopicArn(topicArn)
+	}
+
+	return verifier
+}
+
+// AllowTopicArn trusts the notifications of one more topic, it must be called
+// before the consumer starts
+func (v *SnsSignatureVerifier) AllowTopicArn(topicArn string) {
+	v.allowedTopicArns[topicArn] = true
+}
+
+// Verify checks the notification signature, version 1 being signed with
+// SHA1withRSA and version 2 with SHA256withRSA, and then the topic it was
+// published to
+func (v *SnsSignatureVerifier) Verify(ctx context.Context, notification TopicNotification) error {
+	if err := v.verifySignature(ctx, notification); err != nil {
+		return err
+	}
+
+	if !v.allowedTopicArns[notification.TopicArn] {
+		return fmt.Errorf("%w: %q", custom_error.ErrQueueMessageTopicNotAllowed, notification.TopicArn)
+	}
+
+	return nil
+}
+
+func (v *SnsSignatureVerifier) verifySignature(ctx context.Context, notification TopicNotification) error {
+	var hash crypto.Hash
+
+	switch notification.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("%w: unsupported signature version %q", custom_error.ErrQueueMessageSignatureNotValid, notification.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(notification.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", custom_error.ErrQueueMessageSignatureNotValid, err)
+	}
+
+	certificates, err := v.fetcher.Fetch(ctx, notification.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	digest := hash.New()
+	digest.Write([]byte(notification.StringToSign()))
+	hashed := digest.Sum(nil)
+
+	for _, certificate := range certificates {
+		publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+
+		if rsa.VerifyPKCS1v15(publicKey, hash, hashed, signature) == nil {
+			return nil
+		}
+	}
+
+	return custom_error.ErrQueueMessageSignatureNotValid
+}
+
+// StringToSign builds the canonical text SNS signs for a notification
+func (n TopicNotification) StringToSign() string {
+	var builder strings.Builder
+
+	write := func(key string, value string) {
+		builder.WriteString(key)
+		builder.WriteString("\n")
+		builder.WriteString(value)
+		builder.WriteString("\n")
+	}
+
+	write("Message", n.Message)
+	write("MessageId", n.MessageId)
+	if n.Subject != "" {
+		write("Subject", n.Subject)
+	}
+	write("Timestamp", n.Timestamp)
+	write("TopicArn", n.TopicArn)
+	write("Type", n.Type)
+
+	return builder.String()
+}
+
+// HttpCertificateFetcher downloads the signing certificate from SNS, only
+// accepting https urls hosted by SNS, and caches it by url
+type HttpCertificateFetcher struct {
+	client *http.Client
+
+	mutex sync.Mutex
+	cache map[string][]*x509.Certificate
+}
+
+func NewHttpCertificateFetcher(client *http.Client) *HttpCertificateFetcher {
+	return &HttpCertificateFetcher{
+		client: client,
+		cache:  make(map[string][]*x509.Certificate),
+	}
+}
+
+func (f *HttpCertificateFetcher) Fetch(ctx context.Context, certificateUrl string) ([]*x509.Certificate, error) {
+	f.mutex.Lock()
+	certificates, ok := f.cache[certificateUrl]
+	f.mutex.Unlock()
+
+	if ok {
+		return certificates, nil
+	}
+
+	parsedUrl, err := url.Parse(certificateUrl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", custom_error.ErrQueueMessageSignatureNotValid, err)
+	}
+
+	if parsedUrl.Scheme != "https" || !snsCertificateHost.MatchString(parsedUrl.Hostname()) || !strings.HasSuffix(parsedUrl.Path, ".pem") {
+		return nil, fmt.Errorf("%w: untrusted signing certificate url %q", custom_error.ErrQueueMessageSignatureNotValid, certificateUrl)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certificateUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch the signing certificate, status code %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxCertificateSize))
+	if err != nil {
+		return nil, err
+	}
+
+	certificates, err = parseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	f.cache[certificateUrl] = certificates
+	f.mutex.Unlock()
+
+	return certificates, nil
+}
+
+// FileCertificateFetcher trusts the certificates of a local PEM bundle,
+// ignoring the url carried by the notification
+type FileCertificateFetcher struct {
+	certificates []*x509.Certificate
+}
+
+func NewFileCertificateFetcher(path string) (*FileCertificateFetcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates, err := parseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileCertificateFetcher{
+		certificates: certificates,
+	}, nil
+}
+
+func (f *FileCertificateFetcher) Fetch(ctx context.Context, certificateUrl string) ([]*x509.Certificate, error) {
+	return f.certificates, nil
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no certificate found in the PEM data")
+	}
+
+	return certificates, nil
+}
@@ -0,0 +1,396 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTopicArn = "arn:aws:sns:us-east-1:000000000000:OrderPaymentTopic"
+
+const testSigningCertURL = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-60eadc530605d63b8e62a523676ef735.pem"
+
+type staticCertificateFetcher struct {
+	certificates []*x509.Certificate
+	err          error
+}
+
+func (f *staticCertificateFetcher) Fetch(ctx context.Context, certificateUrl string) ([]*x509.Certificate, error) {
+	return f.certificates, f.err
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return key, certificate, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func signNotification(t *testing.T, key *rsa.PrivateKey, notification *TopicNotification) {
+	hash := crypto.SHA1
+	if notification.SignatureVersion == "2" {
+		hash = crypto.SHA256
+	}
+
+	digest := hash.New()
+	digest.Write([]byte(notification.StringToSign()))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, hash, digest.Sum(nil))
+	assert.NoError(t, err)
+
+	notification.Signature = base64.StdEncoding.EncodeToString(signature)
+}
+
+func newTestNotification(signatureVersion string) TopicNotification {
+	return TopicNotification{
+		Type:             "Notification",
+		MessageId:        "fc8e9ffd-6122-5c52-8fb9-c13e3ee2629a",
+		TopicArn:         testTopicArn,
+		Message:          `{"order_id":"be6293ff-4ec0-4ed8-95c9-b36ce99aa105"}`,
+		Timestamp:        "2024-05-19T02:01:36.927Z",
+		SignatureVersion: signatureVersion,
+		SigningCertURL:   testSigningCertURL,
+	}
+}
+
+func TestStringToSign(t *testing.T) {
+	t.Run("Should build the string to sign without subject", func(t *testing.T) {
+		// Arrange
+		notification := newTestNotification("1")
+
+		// Act
+		result := notification.StringToSign()
+
+		// Assert
+		assert.Equal(t, "Message\n{\"order_id\":\"be6293ff-4ec0-4ed8-95c9-b36ce99aa105\"}\n"+
+			"MessageId\nfc8e9ffd-6122-5c52-8fb9-c13e3ee2629a\n"+
+			"Timestamp\n2024-05-19T02:01:36.927Z\n"+
+			"TopicArn\narn:aws:sns:us-east-1:000000000000:OrderPaymentTopic\n"+
+			"Type\nNotification\n", result)
+	})
+
+	t.Run("Should include the subject when present", func(t *testing.T) {
+		// Arrange
+		notification := newTestNotification("1")
+		notification.Subject = "subject"
+
+		// Act
+		result := notification.StringToSign()
+
+		// Assert
+		assert.Contains(t, result, "MessageId\nfc8e9ffd-6122-5c52-8fb9-c13e3ee2629a\nSubject\nsubject\nTimestamp\n")
+	})
+}
+
+func TestVerify(t *testing.T) {
+	key, certificate, _ := newTestCertificate(t)
+
+	t.Run("Should accept a version 1 signature", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("1")
+		signNotification(t, key, &notification)
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should accept a version 2 signature", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("2")
+		signNotification(t, key, &notification)
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject a signed notification from a topic that is not allowed", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("2")
+		notification.TopicArn = "arn:aws:sns:us-east-1:999999999999:ForgedTopic"
+		signNotification(t, key, &notification)
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageTopicNotAllowed)
+	})
+
+	t.Run("Should accept a notification from a topic allowed later", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("2")
+		signNotification(t, key, &notification)
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, nil)
+		verifier.AllowTopicArn(testTopicArn)
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject a tampered message", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("2")
+		signNotification(t, key, &notification)
+		notification.Message = `{"order_id":"another-order"}`
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageSignatureNotValid)
+	})
+
+	t.Run("Should reject a signature from another certificate", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		_, otherCertificate, _ := newTestCertificate(t)
+
+		notification := newTestNotification("1")
+		signNotification(t, key, &notification)
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{otherCertificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageSignatureNotValid)
+	})
+
+	t.Run("Should reject an unsupported signature version", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("3")
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageSignatureNotValid)
+	})
+
+	t.Run("Should reject a signature that is not base64", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("1")
+		notification.Signature = "not base64!"
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{certificates: []*x509.Certificate{certificate}}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageSignatureNotValid)
+	})
+
+	t.Run("Should return error when the certificate can not be fetched", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		notification := newTestNotification("1")
+		signNotification(t, key, &notification)
+
+		verifier := NewSnsSignatureVerifier(&staticCertificateFetcher{err: assert.AnError}, []string{testTopicArn})
+
+		// Act
+		err := verifier.Verify(ctx, notification)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestHttpCertificateFetcher(t *testing.T) {
+	_, _, certificatePem := newTestCertificate(t)
+
+	t.Run("Should fetch and cache the certificate", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		calls := 0
+		client := &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(certificatePem)),
+				}, nil
+			}),
+		}
+
+		fetcher := NewHttpCertificateFetcher(client)
+
+		// Act
+		first, err := fetcher.Fetch(ctx, testSigningCertURL)
+		assert.NoError(t, err)
+
+		second, err := fetcher.Fetch(ctx, testSigningCertURL)
+		assert.NoError(t, err)
+
+		// Assert
+		assert.Len(t, first, 1)
+		assert.Equal(t, first, second)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Should reject untrusted urls", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		fetcher := NewHttpCertificateFetcher(&http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				t.Fatal("untrusted url should not be requested")
+				return nil, nil
+			}),
+		})
+
+		urls := []string{
+			"http://sns.us-east-1.amazonaws.com/cert.pem",
+			"https://sns.us-east-1.amazonaws.com.evil.com/cert.pem",
+			"https://evil.com/cert.pem",
+			"https://sns.us-east-1.amazonaws.com/cert.txt",
+		}
+
+		for _, url := range urls {
+			// Act
+			_, err := fetcher.Fetch(ctx, url)
+
+			// Assert
+			assert.ErrorIs(t, err, custom_error.ErrQueueMessageSignatureNotValid, url)
+		}
+	})
+
+	t.Run("Should return error when the certificate can not be downloaded", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		fetcher := NewHttpCertificateFetcher(&http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}),
+		})
+
+		// Act
+		_, err := fetcher.Fetch(ctx, testSigningCertURL)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestFileCertificateFetcher(t *testing.T) {
+	t.Run("Should load every certificate of the bundle", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		_, _, first := newTestCertificate(t)
+		_, _, second := newTestCertificate(t)
+
+		path := filepath.Join(t.TempDir(), "bundle.pem")
+		err := os.WriteFile(path, append(first, second...), 0600)
+		assert.NoError(t, err)
+
+		fetcher, err := NewFileCertificateFetcher(path)
+		assert.NoError(t, err)
+
+		// Act
+		certificates, err := fetcher.Fetch(ctx, "https://anywhere/cert.pem")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, certificates, 2)
+	})
+
+	t.Run("Should return error when the bundle has no certificate", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "bundle.pem")
+		err := os.WriteFile(path, []byte("nothing here"), 0600)
+		assert.NoError(t, err)
+
+		// Act
+		_, err = NewFileCertificateFetcher(path)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return error when the bundle does not exist", func(t *testing.T) {
+		// Act
+		_, err := NewFileCertificateFetcher(filepath.Join(t.TempDir(), "missing.pem"))
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/google/uuid"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 )
 
@@ -21,26 +24,37 @@ type TopicNotification struct {
 	Type             string `json:"Type"`
 	MessageId        string `json:"MessageId"`
 	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject,omitempty"`
 	Message          string `json:"Message"`
 	Timestamp        string `json:"Timestamp"`
 	SignatureVersion string `json:"SignatureVersion"`
 	Signature        string `json:"Signature"`
 	SigningCertURL   string `json:"SigningCertURL"`
 	UnsubscribeURL   string `json:"UnsubscribeURL"`
+
+	MessageAttributes map[string]NotificationAttribute `json:"MessageAttributes,omitempty"`
+}
+
+// NotificationAttribute is a message attribute as SNS puts it in the envelope
+type NotificationAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
 }
 
 type AwsSnsService struct {
-	TopicName string
-	TopicArn  string
-	Client    *sns.Client
+	TopicName   string
+	TopicArn    string
+	CloudEvents CloudEventsOptions
+	Client      *sns.Client
 }
 
-func NewTopicService(topicName string, config aws.Config) TopicService {
+func NewTopicService(topicName string, cloudEvents CloudEventsOptions, config aws.Config) TopicService {
 	client := sns.NewFromConfig(config)
 
 	return &AwsSnsService{
-		TopicName: topicName,
-		Client:    client,
+		TopicName:   topicName,
+		CloudEvents: cloudEvents,
+		Client:      client,
 	}
 }
 
@@ -65,22 +79,74 @@ func (s *AwsSnsService) UpdateTopicArn(ctx context.Context) error {
 }
 
 func (s *AwsSnsService) PublishMessage(ctx context.Context, message interface{}) (*string, error) {
-	body, err := json.Marshal(message)
+	body, err := encodeMessage(s.TopicName, s.CloudEvents, message)
 	if err != nil {
 		return nil, err
 	}
 
-	req := &sns.PublishInput{
-		TopicArn: aws.String(s.TopicArn),
-		Message:  aws.String(string(body)),
-	}
+	messageId, err := tracePublish(ctx, messagingSystemSns, s.TopicName, func(ctx context.Context, attributes map[string]string) (string, error) {
+		req := &sns.PublishInput{
+			TopicArn:          aws.String(s.TopicArn),
+			Message:           aws.String(string(body)),
+			MessageAttributes: snsAttributes(attributes),
+		}
+
+		out, err := s.Client.Publish(ctx, req)
+		if err != nil {
+			return "", err
+		}
 
-	out, err := s.Client.Publish(ctx, req)
+		return *out.MessageId, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	slog.InfoContext(ctx, "message published", "topic", s.TopicName, "message_id", *out.MessageId, "message", string(body))
+	slog.InfoContext(ctx, "message published", "topic", s.TopicName, "message_id", messageId, "message", string(body))
+
+	return &messageId, nil
+}
+
+// snsAttributes sends the attributes as SNS message attributes, delivered to
+// the subscribed queues along with the message
+func snsAttributes(attributes map[string]string) map[string]types.MessageAttributeValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	values := make(map[string]types.MessageAttributeValue, len(attributes))
+
+	for name, value := range attributes {
+		values[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	return values
+}
+
+// encodeMessage wraps the message in a CloudEvents envelope when enabled, the
+// ones that are not an Event get a new id and the topic name as their type
+func encodeMessage(topicName string, cloudEvents CloudEventsOptions, message interface{}) ([]byte, error) {
+	event, ok := message.(Event)
+	if !ok {
+		event = Event{
+			Id:   uuid.NewString(),
+			Type: topicName,
+			Time: time.Now(),
+			Data: message,
+		}
+	}
+
+	if !cloudEvents.Enabled {
+		return json.Marshal(event.Data)
+	}
+
+	cloudEvent, err := NewCloudEvent(cloudEvents.Source, event)
+	if err != nil {
+		return nil, err
+	}
 
-	return out.MessageId, nil
+	return json.Marshal(cloudEvent)
 }
@@ -2,8 +2,10 @@ package cloud
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
@@ -16,7 +18,7 @@ import (
 func TestGetTopicName(t *testing.T) {
 	t.Run("Should return topic name", func(t *testing.T) {
 		// Arrange
-		service := NewTopicService("test-topic", aws.Config{})
+		service := NewTopicService("test-topic", CloudEventsOptions{}, aws.Config{})
 
 		// Act
 		topicName := service.GetTopicName()
@@ -44,7 +46,7 @@ func TestUpdateTopicArn(t *testing.T) {
 			},
 		})
 
-		service := NewTopicService("test-topic", *stubber.SdkConfig)
+		service := NewTopicService("test-topic", CloudEventsOptions{}, *stubber.SdkConfig)
 
 		// Act
 		err := service.UpdateTopicArn(ctx)
@@ -71,7 +73,7 @@ func TestUpdateTopicArn(t *testing.T) {
 			},
 		})
 
-		service := NewTopicService("test-topic", *stubber.SdkConfig)
+		service := NewTopicService("test-topic", CloudEventsOptions{}, *stubber.SdkConfig)
 
 		// Act
 		err := service.UpdateTopicArn(ctx)
@@ -93,7 +95,7 @@ func TestUpdateTopicArn(t *testing.T) {
 			Error:         raiseErr,
 		})
 
-		service := NewTopicService("test-topic", *stubber.SdkConfig)
+		service := NewTopicService("test-topic", CloudEventsOptions{}, *stubber.SdkConfig)
 
 		// Act
 		err := service.UpdateTopicArn(ctx)
@@ -133,7 +135,7 @@ func TestPublishMessage(t *testing.T) {
 			},
 		})
 
-		service := NewTopicService("test-topic", *stubber.SdkConfig)
+		service := NewTopicService("test-topic", CloudEventsOptions{}, *stubber.SdkConfig)
 
 		err := service.UpdateTopicArn(ctx)
 		assert.NoError(t, err)
@@ -177,7 +179,7 @@ func TestPublishMessage(t *testing.T) {
 			Error: raiseErr,
 		})
 
-		service := NewTopicService("test-topic", *stubber.SdkConfig)
+		service := NewTopicService("test-topic", CloudEventsOptions{}, *stubber.SdkConfig)
 
 		err := service.UpdateTopicArn(ctx)
 		assert.NoError(t, err)
@@ -193,4 +195,137 @@ func TestPublishMessage(t *testing.T) {
 		testtools.VerifyError(err, raiseErr, t)
 		testtools.ExitTest(stubber, t)
 	})
+
+	t.Run("Should publish only the data of an event when the envelope is disabled", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(testtools.Stub{
+			OperationName: "Publish",
+			Input: &sns.PublishInput{
+				TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:test-topic"),
+				Message:  aws.String(`{"order_id":"order-id"}`),
+			},
+			Output: &sns.PublishOutput{
+				MessageId: aws.String("1234"),
+			},
+		})
+
+		service := &AwsSnsService{
+			TopicName: "test-topic",
+			TopicArn:  "arn:aws:sns:us-east-1:123456789012:test-topic",
+			Client:    sns.NewFromConfig(*stubber.SdkConfig),
+		}
+
+		message := Event{
+			Id:      "event-id",
+			Type:    "OrderCreated",
+			Subject: "order-id",
+			Time:    time.Date(2024, 5, 19, 2, 1, 36, 0, time.UTC),
+			Data:    json.RawMessage(`{"order_id":"order-id"}`),
+		}
+
+		// Act
+		resp, err := service.PublishMessage(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", *resp)
+		testtools.ExitTest(stubber, t)
+	})
+
+	t.Run("Should wrap the event in a cloudevents envelope", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		stubber := testtools.NewStubber()
+
+		stubber.Add(testtools.Stub{
+			OperationName: "Publish",
+			Input: &sns.PublishInput{
+				TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:test-topic"),
+				Message:  aws.String(`{"specversion":"1.0","id":"event-id","source":"ms-order-management","type":"OrderCreated","subject":"order-id","time":"2024-05-19T02:01:36Z","datacontenttype":"application/json","data":{"order_id":"order-id"}}`),
+			},
+			Output: &sns.PublishOutput{
+				MessageId: aws.String("1234"),
+			},
+		})
+
+		service := &AwsSnsService{
+			TopicName: "test-topic",
+			TopicArn:  "arn:aws:sns:us-east-1:123456789012:test-topic",
+			CloudEvents: CloudEventsOptions{
+				Enabled: true,
+				Source:  "ms-order-management",
+			},
+			Client: sns.NewFromConfig(*stubber.SdkConfig),
+		}
+
+		message := Event{
+			Id:      "event-id",
+			Type:    "OrderCreated",
+			Subject: "order-id",
+			Time:    time.Date(2024, 5, 19, 2, 1, 36, 0, time.UTC),
+			Data:    json.RawMessage(`{"order_id":"order-id"}`),
+		}
+
+		// Act
+		resp, err := service.PublishMessage(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", *resp)
+		testtools.ExitTest(stubber, t)
+	})
+}
+
+type awsTopicFixture struct {
+	topicName string
+	stubber   *testtools.AwsmStubber
+}
+
+func newAwsTopicFixture(t *testing.T, topicName string) topicFixture {
+	stubber := testtools.NewStubber()
+
+	stubber.Add(testtools.Stub{
+		OperationName: "ListTopics",
+		Input:         &sns.ListTopicsInput{},
+		Output: &sns.ListTopicsOutput{
+			Topics: []types.Topic{
+				{
+					TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:" + topicName),
+				},
+			},
+		},
+	})
+
+	return &awsTopicFixture{
+		topicName: topicName,
+		stubber:   stubber,
+	}
+}
+
+func (f *awsTopicFixture) service(t *testing.T) TopicService {
+	return NewTopicService(f.topicName, CloudEventsOptions{}, *f.stubber.SdkConfig)
+}
+
+func (f *awsTopicFixture) expectPublish(t *testing.T, body string) {
+	f.stubber.Add(testtools.Stub{
+		OperationName: "Publish",
+		Input: &sns.PublishInput{
+			TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:" + f.topicName),
+			Message:  aws.String(body),
+		},
+		Output: &sns.PublishOutput{
+			MessageId: aws.String("1234"),
+		},
+	})
+}
+
+func (f *awsTopicFixture) verify(t *testing.T) {
+	testtools.ExitTest(f.stubber, t)
+}
+
+func TestAwsTopicContract(t *testing.T) {
+	runTopicContract(t, newAwsTopicFixture)
 }
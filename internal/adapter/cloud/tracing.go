@@ -0,0 +1,61 @@
+package cloud
+
+import (
+	"context"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracing"
+)
+
+const (
+	messagingSystemSns      = "aws_sns"
+	messagingSystemSqs      = "aws_sqs"
+	messagingSystemMemory   = "memory"
+	messagingSystemFile     = "file"
+	messagingSystemPostgres = "postgresql"
+)
+
+// tracePublish runs publish within a producer span, handing it the attributes
+// to send along with the message so the consumers continue the trace
+func tracePublish(
+	ctx context.Context,
+	system string,
+	topicName string,
+	publish func(ctx context.Context, attributes map[string]string) (string, error),
+) (string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, topicName+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(topicName),
+			semconv.MessagingOperationPublish,
+		))
+	defer span.End()
+
+	messageId, err := publish(ctx, tracing.Inject(ctx))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", err
+	}
+
+	span.SetAttributes(semconv.MessagingMessageID(messageId))
+
+	return messageId, nil
+}
+
+// traceAttributes returns the attributes carrying the trace context of the
+// publisher, taken from the SNS envelope when the message came in one
+func traceAttributes(delivery Delivery, notification TopicNotification) map[string]string {
+	if len(notification.MessageAttributes) == 0 {
+		return delivery.Attributes
+	}
+
+	attributes := make(map[string]string, len(notification.MessageAttributes))
+	for name, attribute := range notification.MessageAttributes {
+		attributes[name] = attribute.Value
+	}
+
+	return attributes
+}
@@ -0,0 +1,152 @@
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func setupTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	return recorder
+}
+
+func TestTracePropagation(t *testing.T) {
+	t.Run("Should continue the trace of the publisher when processing the message", func(t *testing.T) {
+		// Arrange
+		recorder := setupTestTracing(t)
+
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+		traceId := parent.SpanContext().TraceID()
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		processor.On("Handle", mock.MatchedBy(func(ctx context.Context) bool {
+			return trace.SpanContextFromContext(ctx).TraceID() == traceId
+		}), mock.Anything).
+			Return(nil).
+			Once()
+
+		broker := NewMemoryBroker()
+		broker.Subscribe("test-topic", "test-queue")
+
+		topic := NewMemoryTopicService("test-topic", CloudEventsOptions{}, broker)
+		queue := NewMemoryQueueService(broker, "test-queue", "", testRetryPolicy, 1, nil, processor)
+
+		// Act
+		_, err := topic.PublishMessage(ctx, process.ProcessMessageDto{OrderId: "order-id"})
+		parent.End()
+
+		queue.ConsumeMessages(context.Background())
+		queue.Wait()
+
+		// Assert
+		assert.NoError(t, err)
+		processor.AssertExpectations(t)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 3)
+
+		for _, span := range spans {
+			assert.Equal(t, traceId, span.SpanContext().TraceID())
+		}
+
+		assert.Equal(t, "test-topic publish", spans[0].Name())
+		assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+		assert.Equal(t, "test-queue process", spans[2].Name())
+		assert.Equal(t, trace.SpanKindConsumer, spans[2].SpanKind())
+		assert.Equal(t, spans[0].SpanContext().SpanID(), spans[2].Parent().SpanID())
+	})
+
+	t.Run("Should mark the span as failed when the message is dead-lettered", func(t *testing.T) {
+		// Arrange
+		recorder := setupTestTracing(t)
+
+		processor := mocks.NewMockProcessMessageService[process.ProcessMessageDto](t)
+
+		broker := NewMemoryBroker()
+		queue := NewMemoryQueueService(broker, "test-queue", "test-dlq", testRetryPolicy, 1, nil, processor)
+
+		broker.Send("test-queue", "invalid")
+
+		// Act
+		queue.ConsumeMessages(context.Background())
+		queue.Wait()
+
+		// Assert
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Len(t, spans[0].Events(), 1)
+	})
+}
+
+func TestTraceAttributes(t *testing.T) {
+	t.Run("Should return the attributes of the delivery", func(t *testing.T) {
+		// Arrange
+		delivery := Delivery{
+			Attributes: map[string]string{"traceparent": "value"},
+		}
+
+		// Act
+		attributes := traceAttributes(delivery, TopicNotification{})
+
+		// Assert
+		assert.Equal(t, map[string]string{"traceparent": "value"}, attributes)
+	})
+
+	t.Run("Should return the attributes of the notification", func(t *testing.T) {
+		// Arrange
+		notification := TopicNotification{
+			MessageAttributes: map[string]NotificationAttribute{
+				"traceparent": {Type: "String", Value: "value"},
+			},
+		}
+
+		// Act
+		attributes := traceAttributes(Delivery{}, notification)
+
+		// Assert
+		assert.Equal(t, map[string]string{"traceparent": "value"}, attributes)
+	})
+}
+
+func TestSnsAttributes(t *testing.T) {
+	t.Run("Should not send attributes when there is no trace context", func(t *testing.T) {
+		// Act
+		attributes := snsAttributes(map[string]string{})
+
+		// Assert
+		assert.Nil(t, attributes)
+	})
+
+	t.Run("Should send the attributes as strings", func(t *testing.T) {
+		// Act
+		attributes := snsAttributes(map[string]string{"traceparent": "value"})
+
+		// Assert
+		assert.Len(t, attributes, 1)
+		assert.Equal(t, "String", *attributes["traceparent"].DataType)
+		assert.Equal(t, "value", *attributes["traceparent"].StringValue)
+	})
+}
@@ -0,0 +1,64 @@
+package cloud
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+const workerQueueSize = 10
+
+// WorkerPool runs jobs in parallel across a fixed number of workers, while jobs
+// submitted with the same key always land on the same worker and run in order
+type WorkerPool struct {
+	workers []chan func()
+
+	once      sync.Once
+	waitGroup sync.WaitGroup
+}
+
+func NewWorkerPool(concurrency int) *WorkerPool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	workers := make([]chan func(), concurrency)
+	for i := range workers {
+		workers[i] = make(chan func(), workerQueueSize)
+	}
+
+	return &WorkerPool{
+		workers: workers,
+	}
+}
+
+// Submit queues the job on the worker owning the key, blocking while that
+// worker queue is full
+func (p *WorkerPool) Submit(key string, job func()) {
+	p.once.Do(p.start)
+
+	p.waitGroup.Add(1)
+	p.workers[p.workerIndex(key)] <- job
+}
+
+// Wait blocks until every submitted job has finished
+func (p *WorkerPool) Wait() {
+	p.waitGroup.Wait()
+}
+
+func (p *WorkerPool) start() {
+	for _, worker := range p.workers {
+		go func(jobs <-chan func()) {
+			for job := range jobs {
+				job()
+				p.waitGroup.Done()
+			}
+		}(worker)
+	}
+}
+
+func (p *WorkerPool) workerIndex(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+
+	return int(hash.Sum32() % uint32(len(p.workers)))
+}
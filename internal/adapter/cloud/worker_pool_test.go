@@ -0,0 +1,93 @@
+package cloud
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Run("Should run the jobs with the same key in order", func(t *testing.T) {
+		// Arrange
+		pool := NewWorkerPool(4)
+
+		mutex := sync.Mutex{}
+		executed := []int{}
+
+		// Act
+		for i := 0; i < 50; i++ {
+			pool.Submit("order-id", func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				executed = append(executed, i)
+			})
+		}
+
+		pool.Wait()
+
+		// Assert
+		assert.Len(t, executed, 50)
+		for i, value := range executed {
+			assert.Equal(t, i, value)
+		}
+	})
+
+	t.Run("Should run the jobs with different keys in parallel", func(t *testing.T) {
+		// Arrange
+		pool := NewWorkerPool(2)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		keyA, keyB := "", ""
+		for i := 0; keyB == ""; i++ {
+			key := fmt.Sprintf("order-%d", i)
+
+			if keyA == "" {
+				keyA = key
+			} else if pool.workerIndex(key) != pool.workerIndex(keyA) {
+				keyB = key
+			}
+		}
+
+		// Act
+		pool.Submit(keyA, func() {
+			<-release
+		})
+
+		pool.Submit(keyB, func() {
+			close(started)
+		})
+
+		// Assert
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("job was blocked by a job of another key")
+		}
+
+		close(release)
+		pool.Wait()
+	})
+
+	t.Run("Should use at least one worker", func(t *testing.T) {
+		// Arrange
+		pool := NewWorkerPool(0)
+
+		executed := false
+
+		// Act
+		pool.Submit("order-id", func() {
+			executed = true
+		})
+
+		pool.Wait()
+
+		// Assert
+		assert.True(t, executed)
+	})
+}
@@ -3,11 +3,15 @@ package database
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"database/sql/driver"
 	"log/slog"
+	"sync"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/health"
@@ -15,21 +19,140 @@ import (
 
 type DatabaseService interface {
 	GetInstance() *sql.DB
+	GetUrl() string
+	UpdateUrl(url string) bool
 	health.HealthCheck
 }
 
+// connector opens every new connection with the current url, so the pool
+// picks up a rotated credential without being recreated. Each url is a new
+// generation, the connections of a previous one are discarded by the pool
+// before being reused
+type connector struct {
+	mu         sync.RWMutex
+	url        string
+	generation uint64
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.RLock()
+	url := c.url
+	generation := c.generation
+	c.mu.RUnlock()
+
+	pqConnector, err := pq.NewConnector(url)
+	if err != nil {
+		return nil, err
+	}
+
+	driverConn, err := pqConnector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pqConn, ok := driverConn.(postgresConn)
+	if !ok {
+		return driverConn, nil
+	}
+
+	return &conn{
+		postgresConn: pqConn,
+		connector:    c,
+		generation:   generation,
+	}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func (c *connector) getUrl() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.url
+}
+
+func (c *connector) isCurrent(generation uint64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generation == generation
+}
+
+func (c *connector) update(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.url == url {
+		return false
+	}
+
+	c.url = url
+	c.generation++
+
+	return true
+}
+
+// postgresConn is what the lib/pq connections implement
+type postgresConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// conn is a connection opened with the url of a generation, reported as bad
+// to the pool once the url is updated
+type conn struct {
+	postgresConn
+
+	connector  *connector
+	generation uint64
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if !c.connector.isCurrent(c.generation) {
+		return driver.ErrBadConn
+	}
+
+	return c.postgresConn.ResetSession(ctx)
+}
+
+func (c *conn) IsValid() bool {
+	return c.connector.isCurrent(c.generation) && c.postgresConn.IsValid()
+}
+
 type Service struct {
 	Client *sql.DB
+
+	connector *connector
 }
 
 func NewDatabase(config *environment.Config) DatabaseService {
-	client, err := sql.Open("postgres", config.DbConfig.Url)
-	if err != nil {
-		panic(fmt.Errorf("error on connect to database: %v", err))
+	connector := &connector{
+		url: config.DbConfig.Url,
 	}
 
+	// a query gets a span only within a traced operation, the polling of the
+	// background loops would fill the traces with roots of their own
+	client := otelsql.OpenDB(connector,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, method otelsql.Method, query string, args []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}))
+
 	return &Service{
-		Client: client,
+		Client:    client,
+		connector: connector,
 	}
 }
 
@@ -37,6 +160,24 @@ func (s *Service) GetInstance() *sql.DB {
 	return s.Client
 }
 
+// GetUrl returns the url the new connections are opened with
+func (s *Service) GetUrl() string {
+	return s.connector.getUrl()
+}
+
+// UpdateUrl makes the new connections use the url, the ones opened with the
+// previous url are closed as they are returned to or taken from the pool. It
+// reports whether the url changed
+func (s *Service) UpdateUrl(url string) bool {
+	if !s.connector.update(url) {
+		return false
+	}
+
+	slog.Info("database url updated, reconnecting the pool")
+
+	return true
+}
+
 func (s *Service) Health() *health.HealthStatus {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"database/sql/driver"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -84,3 +86,111 @@ func TestHealth(t *testing.T) {
 		assert.Equal(mt, "unhealthy", res.Status)
 	})
 }
+
+func TestUpdateUrl(t *testing.T) {
+	t.Run("Should update the url", func(mt *testing.T) {
+		// Arrange
+		config := &environment.Config{
+			DbConfig: &environment.DatabaseConfig{
+				Url: "postgres://host:1234",
+			},
+		}
+
+		service := NewDatabase(config)
+
+		// Act
+		res := service.UpdateUrl("postgres://other-host:1234")
+
+		// Assert
+		assert.True(mt, res)
+		assert.Equal(mt, "postgres://other-host:1234", service.GetUrl())
+	})
+
+	t.Run("Should not update the url when it did not change", func(mt *testing.T) {
+		// Arrange
+		config := &environment.Config{
+			DbConfig: &environment.DatabaseConfig{
+				Url: "postgres://host:1234",
+			},
+		}
+
+		service := NewDatabase(config)
+
+		// Act
+		res := service.UpdateUrl("postgres://host:1234")
+
+		// Assert
+		assert.False(mt, res)
+	})
+
+	t.Run("Should open the new connections with the updated url", func(mt *testing.T) {
+		// Arrange
+		config := &environment.Config{
+			DbConfig: &environment.DatabaseConfig{
+				Url: "postgres://host:1234",
+			},
+		}
+
+		service := NewDatabase(config)
+		service.UpdateUrl("postgres://host:port")
+
+		// Act
+		res := service.Health()
+
+		// Assert
+		assert.Equal(mt, "unhealthy", res.Status)
+		assert.Contains(mt, res.Err, "invalid port")
+	})
+}
+
+type fakeConn struct {
+	postgresConn
+
+	valid bool
+}
+
+func (c *fakeConn) ResetSession(ctx context.Context) error {
+	return nil
+}
+
+func (c *fakeConn) IsValid() bool {
+	return c.valid
+}
+
+func TestConn(t *testing.T) {
+	t.Run("Should keep the connections opened with the current url", func(mt *testing.T) {
+		// Arrange
+		connector := &connector{url: "postgres://host:1234"}
+
+		conn := &conn{
+			postgresConn: &fakeConn{valid: true},
+			connector:    connector,
+		}
+
+		// Act
+		err := conn.ResetSession(context.Background())
+
+		// Assert
+		assert.NoError(mt, err)
+		assert.True(mt, conn.IsValid())
+	})
+
+	t.Run("Should discard the connections opened with a previous url", func(mt *testing.T) {
+		// Arrange
+		connector := &connector{url: "postgres://host:1234"}
+
+		conn := &conn{
+			postgresConn: &fakeConn{valid: true},
+			connector:    connector,
+		}
+
+		connector.update("postgres://other-host:1234")
+
+		// Act
+		err := conn.ResetSession(context.Background())
+
+		// Assert
+		assert.ErrorIs(mt, err, driver.ErrBadConn)
+		assert.False(mt, conn.IsValid())
+	})
+}
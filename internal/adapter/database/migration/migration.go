@@ -0,0 +1,378 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Directory is where the migrations are kept in the repository, relative to
+// its root, new ones are created there and embedded on the next build
+const Directory = "internal/adapter/database/migration/migrations"
+
+// lockKey identifies the advisory lock held while migrating, so only one of
+// the instances booting at the same time applies the migrations
+const lockKey int64 = 4_174_019_263
+
+//go:embed migrations/*.sql
+var files embed.FS
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^\w+$`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// querier is satisfied by both the pool and a single connection
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type Migrator struct {
+	conn *sql.DB
+
+	Migrations []Migration
+}
+
+// NewMigrator returns a migrator for the migrations embedded in the binary
+func NewMigrator(conn *sql.DB) (*Migrator, error) {
+	migrations, err := Load(files, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		conn:       conn,
+		Migrations: migrations,
+	}, nil
+}
+
+// Load reads the migrations of the directory, each version needs both an up
+// and a down file named <version>_<name>.(up|down).sql
+func Load(fsys fs.FS, directory string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{
+				Version: version,
+				Name:    match[2],
+			}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has more than one name: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns how many were
+// applied, each one runs in its own transaction
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(conn)
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO schema_migrations (version, name, applied_at)
+		VALUES ($1, $2, $3);
+	`
+
+	count := 0
+
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := run(ctx, conn, migration.Up, query, migration.Version, migration.Name, time.Now()); err != nil {
+			return count, fmt.Errorf("error applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		slog.InfoContext(ctx, "migration applied", "version", migration.Version, "name", migration.Name)
+
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the last steps applied migrations, the most recent first, and
+// returns how many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(conn)
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		DELETE FROM schema_migrations
+		WHERE version = $1;
+	`
+
+	count := 0
+
+	for i := len(m.Migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.Migrations[i]
+
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := run(ctx, conn, migration.Down, query, migration.Version); err != nil {
+			return count, fmt.Errorf("error reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		slog.InfoContext(ctx, "migration reverted", "version", migration.Version, "name", migration.Name)
+
+		count++
+	}
+
+	return count, nil
+}
+
+// Status returns every known migration with the time it was applied, nil
+// when it is still pending
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := createTable(ctx, m.conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, m.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.Migrations))
+
+	for _, migration := range m.Migrations {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// lock takes a connection out of the pool and holds the advisory lock on it,
+// the lock is released with the connection by unlock
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.conn.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, lockKey); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := createTable(ctx, conn); err != nil {
+		m.unlock(conn)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (m *Migrator) unlock(conn *sql.Conn) {
+	if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1);`, lockKey); err != nil {
+		slog.Error("error releasing the migration lock", "error", err)
+	}
+
+	conn.Close()
+}
+
+func createTable(ctx context.Context, conn querier) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint,
+			name varchar(255),
+			applied_at TIMESTAMP,
+			PRIMARY KEY (version)
+		);
+	`
+
+	_, err := conn.ExecContext(ctx, query)
+
+	return err
+}
+
+func appliedVersions(ctx context.Context, conn querier) (map[int64]time.Time, error) {
+	query := `
+		SELECT version, applied_at
+		FROM schema_migrations;
+	`
+
+	statement, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	applied := make(map[int64]time.Time)
+
+	for statement.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err := statement.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	return applied, statement.Err()
+}
+
+// run executes the script and records it in schema_migrations atomically
+func run(ctx context.Context, conn *sql.Conn, script string, query string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Create adds the empty up and down files of a new migration to the
+// directory, numbered after the last one, and returns their paths
+func Create(directory string, name string) ([]string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name: %s", name)
+	}
+
+	migrations, err := Load(os.DirFS(directory), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	version := int64(1)
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	paths := make([]string, 0, 2)
+
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(directory, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+
+		if err := os.WriteFile(file, []byte(fmt.Sprintf("-- %s %s\n", name, direction)), 0o644); err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, file)
+	}
+
+	return paths, nil
+}
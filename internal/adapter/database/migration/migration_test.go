@@ -0,0 +1,279 @@
+package migration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_orders", Up: "CREATE TABLE orders", Down: "DROP TABLE orders"},
+	{Version: 2, Name: "add_indexes", Up: "CREATE INDEX idx_orders_state", Down: "DROP INDEX idx_orders_state"},
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("SELECT pg_advisory_lock").
+		WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("SELECT pg_advisory_unlock").
+		WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Should load the embedded migrations in order", func(t *testing.T) {
+		// Act
+		migrations, err := Load(files, "migrations")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+
+		for i, migration := range migrations {
+			assert.Equal(t, int64(i+1), migration.Version)
+			assert.NotEmpty(t, migration.Up)
+			assert.NotEmpty(t, migration.Down)
+		}
+	})
+
+	t.Run("Should return error if a migration has no down file", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"000001_create_orders.up.sql": {Data: []byte("CREATE TABLE orders")},
+		}
+
+		// Act
+		migrations, err := Load(fsys, ".")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, migrations)
+	})
+
+	t.Run("Should return error if a file name is not valid", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"create_orders.sql": {Data: []byte("CREATE TABLE orders")},
+		}
+
+		// Act
+		migrations, err := Load(fsys, ".")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, migrations)
+	})
+
+	t.Run("Should return error if a version has two names", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"000001_create_orders.up.sql":  {Data: []byte("CREATE TABLE orders")},
+			"000001_create_items.down.sql": {Data: []byte("DROP TABLE order_items")},
+		}
+
+		// Act
+		migrations, err := Load(fsys, ".")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, migrations)
+	})
+}
+
+func TestUp(t *testing.T) {
+	t.Run("Should apply only the pending migrations", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version, applied_at").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE INDEX idx_orders_state").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schema_migrations").
+			WithArgs(int64(2), "add_indexes", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		migrator := &Migrator{conn: db, Migrations: testMigrations}
+
+		// Act
+		applied, err := migrator.Up(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should roll back and stop at the migration that fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version, applied_at").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TABLE orders").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+		expectUnlock(mock)
+
+		migrator := &Migrator{conn: db, Migrations: testMigrations}
+
+		// Act
+		applied, err := migrator.Up(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 0, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error if the lock can not be acquired", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("SELECT pg_advisory_lock").
+			WithArgs(lockKey).
+			WillReturnError(assert.AnError)
+
+		migrator := &Migrator{conn: db, Migrations: testMigrations}
+
+		// Act
+		applied, err := migrator.Up(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 0, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDown(t *testing.T) {
+	t.Run("Should revert the last applied migration", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		expectLock(mock)
+		mock.ExpectQuery("SELECT version, applied_at").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, time.Now()).
+				AddRow(2, time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec("DROP INDEX idx_orders_state").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM schema_migrations").
+			WithArgs(int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		migrator := &Migrator{conn: db, Migrations: testMigrations}
+
+		// Act
+		reverted, err := migrator.Down(ctx, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, reverted)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("Should return the applied and pending migrations", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		appliedAt := time.Date(2024, 5, 19, 2, 1, 36, 0, time.UTC)
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT version, applied_at").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+				AddRow(1, appliedAt))
+
+		migrator := &Migrator{conn: db, Migrations: testMigrations}
+
+		// Act
+		statuses, err := migrator.Status(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []MigrationStatus{
+			{Version: 1, Name: "create_orders", AppliedAt: &appliedAt},
+			{Version: 2, Name: "add_indexes"},
+		}, statuses)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("Should create the files numbered after the last migration", func(t *testing.T) {
+		// Arrange
+		directory := t.TempDir()
+
+		for _, name := range []string{"000001_create_orders.up.sql", "000001_create_orders.down.sql"} {
+			err := os.WriteFile(filepath.Join(directory, name), []byte("--"), 0o644)
+			assert.NoError(t, err)
+		}
+
+		// Act
+		paths, err := Create(directory, "Add Customer Email")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(directory, "000002_add_customer_email.up.sql"),
+			filepath.Join(directory, "000002_add_customer_email.down.sql"),
+		}, paths)
+
+		migrations, err := Load(os.DirFS(directory), ".")
+		assert.NoError(t, err)
+		assert.Len(t, migrations, 2)
+	})
+
+	t.Run("Should return error if the name is not valid", func(t *testing.T) {
+		// Act
+		paths, err := Create(t.TempDir(), "add-column")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, paths)
+	})
+}
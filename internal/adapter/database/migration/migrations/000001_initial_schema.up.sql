@@ -27,4 +27,4 @@ CREATE TABLE IF NOT EXISTS order_payments (
     created_at TIMESTAMP,
     updated_at TIMESTAMP,
     PRIMARY KEY (order_id, payment_id)
-);
\ No newline at end of file
+);
@@ -0,0 +1,2 @@
+ALTER TABLE order_payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE order_items DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,2 @@
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE order_payments ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'BRL';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS order_state_history (
+    id SERIAL,
+    order_id varchar(255),
+    from_state int,
+    to_state int,
+    actor varchar(255),
+    source varchar(50),
+    source_id varchar(255),
+    changed_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
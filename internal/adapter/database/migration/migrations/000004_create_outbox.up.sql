@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id varchar(255),
+    topic varchar(255),
+    payload text,
+    state int,
+    message_id varchar(255),
+    attempts int,
+    last_error text,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS inbox (
+    message_id varchar(255),
+    processed_at TIMESTAMP,
+    PRIMARY KEY (message_id)
+);
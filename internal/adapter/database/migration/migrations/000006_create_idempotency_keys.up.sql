@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id varchar(255),
+    key varchar(255),
+    fingerprint varchar(64),
+    state int,
+    status_code int,
+    content_type varchar(255),
+    response bytea,
+    created_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id varchar(255),
+    url text,
+    secret varchar(255),
+    event_types text[],
+    active boolean,
+    consecutive_failures int,
+    disabled_at TIMESTAMP NULL,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id varchar(255),
+    subscription_id varchar(255) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id varchar(255),
+    event_type varchar(255),
+    payload text,
+    state int,
+    attempts int,
+    last_status_code int,
+    last_error text,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id varchar(255),
+    delivery_id varchar(255),
+    subscription_id varchar(255) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id varchar(255),
+    event_type varchar(255),
+    number int,
+    state int,
+    status_code int,
+    error text,
+    duration_ms bigint,
+    attempted_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE outbox DROP COLUMN IF EXISTS subject;
+ALTER TABLE outbox DROP COLUMN IF EXISTS event_type;
@@ -0,0 +1,2 @@
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS event_type varchar(255);
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS subject varchar(255);
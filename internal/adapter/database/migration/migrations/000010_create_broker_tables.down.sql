@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS broker_messages;
+DROP TABLE IF EXISTS broker_subscriptions;
@@ -0,0 +1,19 @@
+-- queues of the postgres broker, used when BROKER_DRIVER=postgres. A message
+-- published to a topic is copied to every queue subscribed to it
+CREATE TABLE IF NOT EXISTS broker_subscriptions (
+    topic varchar(255),
+    queue varchar(255),
+    PRIMARY KEY (topic, queue)
+);
+
+CREATE TABLE IF NOT EXISTS broker_messages (
+    id varchar(255),
+    queue varchar(255),
+    body text,
+    attributes text,
+    receive_count int,
+    receipt_handle varchar(255),
+    visible_at TIMESTAMP,
+    created_at TIMESTAMP,
+    PRIMARY KEY (queue, id)
+);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_broker_messages_visible_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_state;
+DROP INDEX IF EXISTS idx_outbox_state;
+
+DROP INDEX IF EXISTS idx_order_state_history_order_id;
+
+DROP INDEX IF EXISTS idx_order_payments_state;
+
+DROP INDEX IF EXISTS idx_orders_state;
+DROP INDEX IF EXISTS idx_orders_track_id;
+DROP INDEX IF EXISTS idx_orders_customer_id;
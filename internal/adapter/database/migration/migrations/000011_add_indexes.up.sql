@@ -0,0 +1,12 @@
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_track_id ON orders (track_id);
+CREATE INDEX IF NOT EXISTS idx_orders_state ON orders (state);
+
+CREATE INDEX IF NOT EXISTS idx_order_payments_state ON order_payments (state);
+
+CREATE INDEX IF NOT EXISTS idx_order_state_history_order_id ON order_state_history (order_id, changed_at);
+
+-- the relay, dispatcher and broker poll these by state and visibility
+CREATE INDEX IF NOT EXISTS idx_outbox_state ON outbox (state, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_state ON webhook_deliveries (state, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_broker_messages_visible_at ON broker_messages (queue, visible_at);
@@ -0,0 +1,4 @@
+ALTER TABLE webhook_delivery_attempts DROP CONSTRAINT IF EXISTS fk_webhook_delivery_attempts_delivery_id;
+ALTER TABLE order_state_history DROP CONSTRAINT IF EXISTS fk_order_state_history_order_id;
+ALTER TABLE order_payments DROP CONSTRAINT IF EXISTS fk_order_payments_order_id;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS fk_order_items_order_id;
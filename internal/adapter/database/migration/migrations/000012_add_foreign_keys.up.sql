@@ -0,0 +1,11 @@
+ALTER TABLE order_items
+    ADD CONSTRAINT fk_order_items_order_id FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE;
+
+ALTER TABLE order_payments
+    ADD CONSTRAINT fk_order_payments_order_id FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE;
+
+ALTER TABLE order_state_history
+    ADD CONSTRAINT fk_order_state_history_order_id FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE;
+
+ALTER TABLE webhook_delivery_attempts
+    ADD CONSTRAINT fk_webhook_delivery_attempts_delivery_id FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE;
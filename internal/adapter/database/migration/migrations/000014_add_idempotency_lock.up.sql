@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+UPDATE idempotency_keys SET locked_until = created_at WHERE locked_until IS NULL;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS etag varchar(255) NOT NULL DEFAULT '';
@@ -34,6 +34,24 @@ func (_m *MockDatabaseService) GetInstance() *sql.DB {
 	return r0
 }
 
+// GetUrl provides a mock function with given fields:
+func (_m *MockDatabaseService) GetUrl() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUrl")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // Health provides a mock function with given fields:
 func (_m *MockDatabaseService) Health() *health.HealthStatus {
 	ret := _m.Called()
@@ -54,6 +72,24 @@ func (_m *MockDatabaseService) Health() *health.HealthStatus {
 	return r0
 }
 
+// UpdateUrl provides a mock function with given fields: url
+func (_m *MockDatabaseService) UpdateUrl(url string) bool {
+	ret := _m.Called(url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUrl")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(url)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // NewMockDatabaseService creates a new instance of MockDatabaseService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockDatabaseService(t interface {
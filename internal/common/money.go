@@ -0,0 +1,218 @@
+package common
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the ISO 4217 code used when a value is created or
+// received without an explicit currency
+const DefaultCurrency = "BRL"
+
+// minorUnitsPerMajor is the number of minor units (cents) of every currency
+// handled by the service, matching the DECIMAL(10, 2) columns
+const minorUnitsPerMajor = 100
+
+type MoneyFormat string
+
+const (
+	// MoneyFormatDecimal keeps the legacy contract where money is a plain JSON
+	// number in major units (e.g. 10.5)
+	MoneyFormatDecimal MoneyFormat = "decimal"
+	// MoneyFormatMinor serializes money as an object with the amount in minor
+	// units and the currency code (e.g. {"amount": 1050, "currency": "BRL"})
+	MoneyFormatMinor MoneyFormat = "minor"
+)
+
+var moneyFormat = MoneyFormatDecimal
+
+func SetMoneyFormat(format string) error {
+	switch MoneyFormat(format) {
+	case MoneyFormatDecimal, MoneyFormatMinor:
+		moneyFormat = MoneyFormat(format)
+		return nil
+	}
+
+	return fmt.Errorf("invalid money format: %s", format)
+}
+
+// Money is an exact monetary value stored as an integer number of minor units
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+func NewMoneyFromFloat(value float64, currency string) Money {
+	money, err := ParseMoney(strconv.FormatFloat(value, 'f', -1, 64), currency)
+	if err != nil {
+		return NewMoney(0, currency)
+	}
+
+	return money
+}
+
+// ParseMoney converts a decimal text in major units (e.g. "10.50") rounding
+// half away from zero, without going through float arithmetic
+func ParseMoney(value string, currency string) (Money, error) {
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return Money{}, fmt.Errorf("invalid money value: %s", value)
+	}
+
+	rat.Mul(rat, big.NewRat(minorUnitsPerMajor, 1))
+
+	num := new(big.Int).Set(rat.Num())
+	den := rat.Denom()
+
+	half := new(big.Int).Quo(den, big.NewInt(2))
+
+	if num.Sign() < 0 {
+		num.Sub(num, half)
+	} else {
+		num.Add(num, half)
+	}
+
+	num.Quo(num, den)
+
+	if !num.IsInt64() {
+		return Money{}, fmt.Errorf("money value out of range: %s", value)
+	}
+
+	return NewMoney(num.Int64(), currency), nil
+}
+
+func (m Money) Add(other Money) Money {
+	return NewMoney(m.Amount+other.Amount, m.currencyOr(other.Currency))
+}
+
+func (m Money) Multiply(quantity int) Money {
+	return NewMoney(m.Amount*int64(quantity), m.Currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsBetween(min int64, max int64) bool {
+	return m.Amount >= min && m.Amount <= max
+}
+
+func (m Money) SameCurrency(other Money) bool {
+	return m.currencyOr(DefaultCurrency) == other.currencyOr(DefaultCurrency)
+}
+
+func (m Money) String() string {
+	sign := ""
+	amount := m.Amount
+
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/minorUnitsPerMajor, amount%minorUnitsPerMajor)
+}
+
+func (m Money) currencyOr(fallback string) string {
+	if m.Currency == "" {
+		return fallback
+	}
+
+	return m.Currency
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	if moneyFormat == MoneyFormatMinor {
+		type money Money
+		return json.Marshal(money(NewMoney(m.Amount, m.Currency)))
+	}
+
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both the legacy decimal number and the minor units
+// object, regardless of the configured output format
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		type money Money
+
+		var value money
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+
+		*m = NewMoney(value.Amount, value.Currency)
+
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+
+	value, err := ParseMoney(number.String(), m.currencyOr(DefaultCurrency))
+	if err != nil {
+		return err
+	}
+
+	*m = value
+
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	var (
+		value Money
+		err   error
+	)
+
+	switch v := src.(type) {
+	case nil:
+		value = NewMoney(0, m.Currency)
+	case []byte:
+		value, err = ParseMoney(string(v), m.Currency)
+	case string:
+		value, err = ParseMoney(v, m.Currency)
+	case int64:
+		value = NewMoney(v*minorUnitsPerMajor, m.Currency)
+	case float64:
+		value = NewMoneyFromFloat(v, m.Currency)
+	default:
+		err = fmt.Errorf("unable to scan %T into money", src)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*m = value
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Run("Should parse a decimal text into minor units", func(t *testing.T) {
+		// Act
+		money, err := ParseMoney("10.50", "")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, NewMoney(1050, DefaultCurrency), money)
+	})
+
+	t.Run("Should round half away from zero", func(t *testing.T) {
+		// Act
+		up, errUp := ParseMoney("0.125", "USD")
+		down, errDown := ParseMoney("-0.125", "USD")
+		float, errFloat := ParseMoney("59.980000000000004", "USD")
+
+		// Assert
+		assert.NoError(t, errUp)
+		assert.NoError(t, errDown)
+		assert.NoError(t, errFloat)
+		assert.Equal(t, int64(13), up.Amount)
+		assert.Equal(t, int64(-13), down.Amount)
+		assert.Equal(t, int64(5998), float.Amount)
+	})
+
+	t.Run("Should return error when value is not a number", func(t *testing.T) {
+		// Act
+		_, err := ParseMoney("abc", "")
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestMoneyOperations(t *testing.T) {
+	t.Run("Should not accumulate rounding errors", func(t *testing.T) {
+		// Arrange
+		total := NewMoney(0, DefaultCurrency)
+
+		// Act
+		for i := 0; i < 10; i++ {
+			total = total.Add(NewMoneyFromFloat(0.1, DefaultCurrency))
+		}
+
+		// Assert
+		assert.Equal(t, NewMoney(100, DefaultCurrency), total)
+		assert.Equal(t, "1.00", total.String())
+	})
+
+	t.Run("Should multiply by the quantity", func(t *testing.T) {
+		// Act
+		money := NewMoney(333, DefaultCurrency).Multiply(3)
+
+		// Assert
+		assert.Equal(t, NewMoney(999, DefaultCurrency), money)
+	})
+
+	t.Run("Should format negative values", func(t *testing.T) {
+		// Act
+		text := NewMoney(-5, DefaultCurrency).String()
+
+		// Assert
+		assert.Equal(t, "-0.05", text)
+	})
+
+	t.Run("Should compare currencies", func(t *testing.T) {
+		// Assert
+		assert.True(t, NewMoney(1, DefaultCurrency).SameCurrency(Money{Amount: 1}))
+		assert.False(t, NewMoney(1, "USD").SameCurrency(NewMoney(1, "EUR")))
+	})
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Run("Should marshal as a decimal number by default", func(t *testing.T) {
+		// Act
+		data, err := json.Marshal(NewMoney(1050, DefaultCurrency))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "10.50", string(data))
+	})
+
+	t.Run("Should marshal as minor units when configured", func(t *testing.T) {
+		// Arrange
+		err := SetMoneyFormat(string(MoneyFormatMinor))
+		assert.NoError(t, err)
+		defer func() {
+			_ = SetMoneyFormat(string(MoneyFormatDecimal))
+		}()
+
+		// Act
+		data, err := json.Marshal(NewMoney(1050, "USD"))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"amount":1050,"currency":"USD"}`, string(data))
+	})
+
+	t.Run("Should return error when format is invalid", func(t *testing.T) {
+		// Act
+		err := SetMoneyFormat("float")
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should unmarshal both formats", func(t *testing.T) {
+		// Arrange
+		var decimal, minor Money
+
+		// Act
+		errDecimal := json.Unmarshal([]byte(`10.5`), &decimal)
+		errMinor := json.Unmarshal([]byte(`{"amount":1050,"currency":"USD"}`), &minor)
+
+		// Assert
+		assert.NoError(t, errDecimal)
+		assert.NoError(t, errMinor)
+		assert.Equal(t, NewMoney(1050, DefaultCurrency), decimal)
+		assert.Equal(t, NewMoney(1050, "USD"), minor)
+	})
+
+	t.Run("Should return error when value is invalid", func(t *testing.T) {
+		// Arrange
+		var money Money
+
+		// Act
+		err := json.Unmarshal([]byte(`"abc"`), &money)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestMoneySQL(t *testing.T) {
+	t.Run("Should store the value as a decimal text", func(t *testing.T) {
+		// Act
+		value, err := NewMoney(1999, DefaultCurrency).Value()
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "19.99", value)
+	})
+
+	t.Run("Should scan the supported types", func(t *testing.T) {
+		// Arrange
+		var fromBytes, fromString, fromInt, fromFloat, fromNil Money
+
+		// Act
+		errBytes := fromBytes.Scan([]byte("19.99"))
+		errString := fromString.Scan("19.99")
+		errInt := fromInt.Scan(int64(19))
+		errFloat := fromFloat.Scan(19.99)
+		errNil := fromNil.Scan(nil)
+
+		// Assert
+		assert.NoError(t, errBytes)
+		assert.NoError(t, errString)
+		assert.NoError(t, errInt)
+		assert.NoError(t, errFloat)
+		assert.NoError(t, errNil)
+		assert.Equal(t, int64(1999), fromBytes.Amount)
+		assert.Equal(t, int64(1999), fromString.Amount)
+		assert.Equal(t, int64(1900), fromInt.Amount)
+		assert.Equal(t, int64(1999), fromFloat.Amount)
+		assert.True(t, fromNil.IsZero())
+	})
+
+	t.Run("Should return error when type is not supported", func(t *testing.T) {
+		// Arrange
+		var money Money
+
+		// Act
+		err := money.Scan(true)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
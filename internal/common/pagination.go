@@ -18,3 +18,11 @@ func (p *Pagination) SetDefaults() {
 		p.Size = 100
 	}
 }
+
+func (p *Pagination) TotalPages(totalItems int64) int64 {
+	if p.Size < 1 {
+		return 0
+	}
+
+	return (totalItems + p.Size - 1) / p.Size
+}
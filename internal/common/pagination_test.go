@@ -86,3 +86,30 @@ func TestSetDefaults(t *testing.T) {
 		}
 	})
 }
+
+func TestTotalPages(t *testing.T) {
+	t.Run("Should round up the number of pages", func(t *testing.T) {
+		// Arrange
+		p := Pagination{
+			Page: 1,
+			Size: 10,
+		}
+
+		// Act
+		totalPages := p.TotalPages(21)
+
+		// Assert
+		assert.Equal(t, int64(3), totalPages)
+	})
+
+	t.Run("Should return zero when size is not set", func(t *testing.T) {
+		// Arrange
+		p := Pagination{}
+
+		// Act
+		totalPages := p.TotalPages(21)
+
+		// Assert
+		assert.Zero(t, totalPages)
+	})
+}
@@ -0,0 +1,63 @@
+package event_entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	OrderCreated      EventType = "OrderCreated"
+	ItemAdded         EventType = "ItemAdded"
+	OrderStateChanged EventType = "OrderStateChanged"
+	OrderCancelled    EventType = "OrderCancelled"
+	PaymentRequested  EventType = "PaymentRequested"
+	PaymentApproved   EventType = "PaymentApproved"
+	PaymentRejected   EventType = "PaymentRejected"
+)
+
+// SchemaVersion is the version of the payload of the event type, it must be
+// bumped whenever a field is removed or changes its meaning
+func (t EventType) SchemaVersion() int {
+	switch t {
+	case OrderCreated,
+		ItemAdded,
+		OrderStateChanged,
+		OrderCancelled,
+		PaymentRequested,
+		PaymentApproved,
+		PaymentRejected:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Event is a change raised by an order or one of its payments, consumers
+// can use the aggregate version to discard stale or duplicated events
+type Event struct {
+	Id            string    `json:"id"`
+	EventType     EventType `json:"event_type"`
+	SchemaVersion int       `json:"schema_version"`
+
+	AggregateId      string `json:"aggregate_id"`
+	AggregateVersion int    `json:"aggregate_version"`
+
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+func NewEvent(eventType EventType, aggregateId string, data interface{}, now time.Time) Event {
+	return Event{
+		Id:            uuid.NewString(),
+		EventType:     eventType,
+		SchemaVersion: eventType.SchemaVersion(),
+
+		AggregateId: aggregateId,
+
+		OccurredAt: now,
+		Data:       data,
+	}
+}
@@ -0,0 +1,39 @@
+package event_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEvent(t *testing.T) {
+	t.Run("Should create an event with the schema version of its type", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		res := NewEvent(OrderCreated, "order-id", map[string]string{"key": "value"}, now)
+
+		// Assert
+		assert.NotEmpty(t, res.Id)
+		assert.Equal(t, OrderCreated, res.EventType)
+		assert.Equal(t, 1, res.SchemaVersion)
+		assert.Equal(t, "order-id", res.AggregateId)
+		assert.Zero(t, res.AggregateVersion)
+		assert.Equal(t, now, res.OccurredAt)
+	})
+}
+
+func TestSchemaVersion(t *testing.T) {
+	t.Run("Should return zero for an unknown event type", func(t *testing.T) {
+		// Arrange
+		eventType := EventType("Unknown")
+
+		// Act
+		res := eventType.SchemaVersion()
+
+		// Assert
+		assert.Zero(t, res)
+	})
+}
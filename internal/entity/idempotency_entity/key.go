@@ -0,0 +1,63 @@
+package idempotency_entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type Key struct {
+	Key         string   `json:"key"`
+	UserId      string   `json:"user_id"`
+	Fingerprint string   `json:"fingerprint"`
+	State       KeyState `json:"state"`
+
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+	Response    []byte `json:"response"`
+
+	CreatedAt   time.Time `json:"created_at"`
+	LockedUntil time.Time `json:"locked_until"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// NewKey creates a key in progress, its request may be taken over by a retry
+// once the lock timeout is over, in case it was never completed nor released
+func NewKey(userId string, key string, fingerprint string, now time.Time, ttl time.Duration, lockTimeout time.Duration) Key {
+	return Key{
+		Key:         key,
+		UserId:      userId,
+		Fingerprint: fingerprint,
+		State:       InProgress,
+
+		CreatedAt:   now,
+		LockedUntil: now.Add(lockTimeout),
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+// Fingerprint identifies a request by its method, path and body, so a key can
+// not be reused to perform a different operation
+func Fingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (k *Key) Matches(fingerprint string) bool {
+	return k.Fingerprint == fingerprint
+}
+
+func (k *Key) Complete(statusCode int, contentType string, eTag string, response []byte) {
+	k.State = Completed
+	k.StatusCode = statusCode
+	k.ContentType = contentType
+	k.ETag = eTag
+	k.Response = response
+}
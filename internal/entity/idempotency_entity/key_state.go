@@ -0,0 +1,22 @@
+package idempotency_entity
+
+type KeyState int
+
+const (
+	None       KeyState = iota
+	InProgress          // When the first request with the key is being handled
+	Completed           // When the response of the request was stored
+)
+
+func (s KeyState) String() string {
+	text, ok := map[KeyState]string{
+		None:       "None",
+		InProgress: "InProgress",
+		Completed:  "Completed",
+	}[s]
+	if !ok {
+		return "Unknown"
+	}
+
+	return text
+}
@@ -0,0 +1,70 @@
+package idempotency_entity
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKey(t *testing.T) {
+	t.Run("Should create a key in progress locked for the lock timeout that expires after the ttl", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		key := NewKey("user_id", "key", "fingerprint", now, time.Hour, time.Minute)
+
+		// Assert
+		assert.Equal(t, InProgress, key.State)
+		assert.Equal(t, now, key.CreatedAt)
+		assert.Equal(t, now.Add(time.Minute), key.LockedUntil)
+		assert.Equal(t, now.Add(time.Hour), key.ExpiresAt)
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Run("Should return the same fingerprint for the same request", func(t *testing.T) {
+		// Act
+		first := Fingerprint(http.MethodPost, "/orders", []byte(`{"a":1}`))
+		second := Fingerprint(http.MethodPost, "/orders", []byte(`{"a":1}`))
+
+		// Assert
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("Should return another fingerprint when the body differs", func(t *testing.T) {
+		// Act
+		first := Fingerprint(http.MethodPost, "/orders", []byte(`{"a":1}`))
+		second := Fingerprint(http.MethodPost, "/orders", []byte(`{"a":2}`))
+
+		// Assert
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("Should return another fingerprint when the path differs", func(t *testing.T) {
+		// Act
+		first := Fingerprint(http.MethodPost, "/orders/1/items", nil)
+		second := Fingerprint(http.MethodPost, "/orders/2/items", nil)
+
+		// Assert
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestComplete(t *testing.T) {
+	t.Run("Should store the response", func(t *testing.T) {
+		// Arrange
+		key := NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+
+		// Act
+		key.Complete(http.StatusCreated, "application/json", "", []byte(`{}`))
+
+		// Assert
+		assert.Equal(t, Completed, key.State)
+		assert.Equal(t, http.StatusCreated, key.StatusCode)
+		assert.Equal(t, "application/json", key.ContentType)
+		assert.Equal(t, []byte(`{}`), key.Response)
+	})
+}
@@ -0,0 +1,35 @@
+package order_entity
+
+import (
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+)
+
+type OrderCreatedEvent struct {
+	OrderId    string     `json:"order_id"`
+	CustomerId string     `json:"customer_id"`
+	TrackId    TrackId    `json:"track_id"`
+	State      OrderState `json:"state"`
+	StateTitle string     `json:"state_title"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ItemAddedEvent struct {
+	OrderId   string       `json:"order_id"`
+	ItemId    string       `json:"item_id"`
+	Name      string       `json:"name"`
+	UnitPrice common.Money `json:"unit_price"`
+	Quantity  int          `json:"quantity"`
+}
+
+type OrderStateChangedEvent struct {
+	StateTransition
+}
+
+type OrderCancelledEvent struct {
+	OrderId        string     `json:"order_id"`
+	FromState      OrderState `json:"from_state"`
+	FromStateTitle string     `json:"from_state_title"`
+	CancelledAt    time.Time  `json:"cancelled_at"`
+}
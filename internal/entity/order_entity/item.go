@@ -1,13 +1,15 @@
 package order_entity
 
+import "github.com/jfelipearaujo-org/ms-order-management/internal/common"
+
 type Item struct {
-	Id        string  `json:"id"`
-	Name      string  `json:"name"`
-	UnitPrice float64 `json:"unit_price"`
-	Quantity  int     `json:"quantity"`
+	Id        string       `json:"id"`
+	Name      string       `json:"name"`
+	UnitPrice common.Money `json:"unit_price"`
+	Quantity  int          `json:"quantity"`
 }
 
-func NewItem(id string, name string, unitPrice float64, quantity int) Item {
+func NewItem(id string, name string, unitPrice common.Money, quantity int) Item {
 	return Item{
 		Id:        id,
 		Name:      name,
@@ -15,3 +17,7 @@ func NewItem(id string, name string, unitPrice float64, quantity int) Item {
 		Quantity:  quantity,
 	}
 }
+
+func (i *Item) TotalPrice() common.Money {
+	return i.UnitPrice.Multiply(i.Quantity)
+}
@@ -3,6 +3,7 @@ package order_entity
 import (
 	"testing"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,12 +13,12 @@ func TestNewItem(t *testing.T) {
 		expect := Item{
 			Id:        "1",
 			Name:      "name",
-			UnitPrice: 10.0,
+			UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
 			Quantity:  2,
 		}
 
 		// Act
-		res := NewItem("1", "name", 10.0, 2)
+		res := NewItem("1", "name", common.NewMoney(1000, common.DefaultCurrency), 2)
 
 		// Assert
 		assert.Equal(t, expect, res)
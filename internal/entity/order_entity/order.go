@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 )
@@ -17,8 +19,8 @@ type Order struct {
 	StateTitle     string     `json:"state_title"`
 	StateUpdatedAt time.Time  `json:"state_updated_at"`
 
-	TotalItems int     `json:"total_items"`
-	TotalPrice float64 `json:"total_price"`
+	TotalItems int          `json:"total_items"`
+	TotalPrice common.Money `json:"total_price"`
 
 	Items []Item `json:"items"`
 
@@ -26,10 +28,15 @@ type Order struct {
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	Version int `json:"version"`
+
+	pendingTransitions []StateTransition
+	pendingEvents      []event_entity.Event
 }
 
 func NewOrder(customerID string, now time.Time) Order {
-	return Order{
+	order := Order{
 		Id: uuid.NewString(),
 
 		CustomerId:     customerID,
@@ -38,13 +45,26 @@ func NewOrder(customerID string, now time.Time) Order {
 		StateUpdatedAt: now,
 
 		TotalItems: 0,
-		TotalPrice: 0,
+		TotalPrice: common.NewMoney(0, common.DefaultCurrency),
 
 		Items: make([]Item, 0),
 
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		Version: 1,
 	}
+
+	order.raise(event_entity.OrderCreated, OrderCreatedEvent{
+		OrderId:    order.Id,
+		CustomerId: order.CustomerId,
+		TrackId:    order.TrackId,
+		State:      order.State,
+		StateTitle: order.State.String(),
+		CreatedAt:  now,
+	}, now)
+
+	return order
 }
 
 func (o *Order) AddItem(item Item, now time.Time) error {
@@ -52,6 +72,10 @@ func (o *Order) AddItem(item Item, now time.Time) error {
 		if i.Id == item.Id {
 			return custom_error.ErrOrderItemAlreadyExists
 		}
+
+		if !i.UnitPrice.SameCurrency(item.UnitPrice) {
+			return custom_error.ErrOrderItemCurrencyMismatch
+		}
 	}
 
 	o.Items = append(o.Items, item)
@@ -59,16 +83,64 @@ func (o *Order) AddItem(item Item, now time.Time) error {
 
 	o.CalculateTotals()
 
+	o.raise(event_entity.ItemAdded, ItemAddedEvent{
+		OrderId:   o.Id,
+		ItemId:    item.Id,
+		Name:      item.Name,
+		UnitPrice: item.UnitPrice,
+		Quantity:  item.Quantity,
+	}, now)
+
 	return nil
 }
 
+func (o *Order) RemoveItem(itemId string, now time.Time) error {
+	for i, item := range o.Items {
+		if item.Id == itemId {
+			o.Items = append(o.Items[:i], o.Items[i+1:]...)
+			o.UpdatedAt = now
+
+			o.CalculateTotals()
+
+			return nil
+		}
+	}
+
+	return custom_error.ErrOrderItemNotFound
+}
+
+func (o *Order) SetItemQuantity(itemId string, quantity int, now time.Time) error {
+	if quantity < 1 {
+		return custom_error.ErrOrderItemInvalidQuantity
+	}
+
+	for i, item := range o.Items {
+		if item.Id == itemId {
+			o.Items[i].Quantity = quantity
+			o.UpdatedAt = now
+
+			o.CalculateTotals()
+
+			return nil
+		}
+	}
+
+	return custom_error.ErrOrderItemNotFound
+}
+
 func (o *Order) CalculateTotals() {
+	currency := common.DefaultCurrency
+
+	if len(o.Items) > 0 {
+		currency = o.Items[0].UnitPrice.Currency
+	}
+
 	o.TotalItems = 0
-	o.TotalPrice = 0
+	o.TotalPrice = common.NewMoney(0, currency)
 
 	for _, item := range o.Items {
 		o.TotalItems += item.Quantity
-		o.TotalPrice += item.UnitPrice * float64(item.Quantity)
+		o.TotalPrice = o.TotalPrice.Add(item.TotalPrice())
 	}
 }
 
@@ -81,6 +153,21 @@ func (o *Order) UpdateState(toState OrderState, now time.Time) error {
 		return custom_error.ErrOrderInvalidStateTransition
 	}
 
+	transition := NewStateTransition(o.Id, o.State, toState, now)
+
+	o.pendingTransitions = append(o.pendingTransitions, transition)
+
+	o.raise(event_entity.OrderStateChanged, OrderStateChangedEvent{StateTransition: transition}, now)
+
+	if toState == Cancelled {
+		o.raise(event_entity.OrderCancelled, OrderCancelledEvent{
+			OrderId:        o.Id,
+			FromState:      o.State,
+			FromStateTitle: o.State.String(),
+			CancelledAt:    now,
+		}, now)
+	}
+
 	o.State = toState
 	o.StateTitle = toState.String()
 	o.StateUpdatedAt = now
@@ -89,6 +176,29 @@ func (o *Order) UpdateState(toState OrderState, now time.Time) error {
 	return nil
 }
 
+// PendingStateTransitions returns the state changes not persisted yet
+func (o *Order) PendingStateTransitions() []StateTransition {
+	return o.pendingTransitions
+}
+
+func (o *Order) ClearPendingStateTransitions() {
+	o.pendingTransitions = nil
+}
+
+// PendingEvents returns the domain events raised since the order was loaded,
+// their aggregate version is only known when they are persisted
+func (o *Order) PendingEvents() []event_entity.Event {
+	return o.pendingEvents
+}
+
+func (o *Order) ClearPendingEvents() {
+	o.pendingEvents = nil
+}
+
+func (o *Order) raise(eventType event_entity.EventType, data interface{}, now time.Time) {
+	o.pendingEvents = append(o.pendingEvents, event_entity.NewEvent(eventType, o.Id, data, now))
+}
+
 func (o *Order) RefreshStateTitle() {
 	o.StateTitle = o.State.String()
 }
@@ -141,19 +251,17 @@ func (o *Order) GetOnGoingPayment() *payment_entity.Payment {
 }
 
 func (o *Order) ShouldCancel() bool {
-	numOfPayments := len(o.Payments)
-
-	if numOfPayments == 0 {
-		return false
-	}
+	return o.CountPaymentsInState(payment_entity.Rejected) == 3
+}
 
-	paymentsRejected := 0
+func (o *Order) CountPaymentsInState(state payment_entity.PaymentState) int {
+	count := 0
 
 	for _, payment := range o.Payments {
-		if payment.IsInState(payment_entity.Rejected) {
-			paymentsRejected++
+		if payment.IsInState(state) {
+			count++
 		}
 	}
 
-	return paymentsRejected == 3
+	return count
 }
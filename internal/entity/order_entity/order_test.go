@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 	"github.com/stretchr/testify/assert"
@@ -24,10 +26,11 @@ func TestOrder(t *testing.T) {
 		assert.Equal(t, Created, res.State)
 		assert.Equal(t, now, res.StateUpdatedAt)
 		assert.Equal(t, 0, res.TotalItems)
-		assert.Equal(t, 0.0, res.TotalPrice)
+		assert.Equal(t, common.NewMoney(0, common.DefaultCurrency), res.TotalPrice)
 		assert.Empty(t, res.Items)
 		assert.Equal(t, now, res.CreatedAt)
 		assert.Equal(t, now, res.UpdatedAt)
+		assert.Equal(t, 1, res.Version)
 	})
 
 	t.Run("Should add an item to the order", func(t *testing.T) {
@@ -37,14 +40,14 @@ func TestOrder(t *testing.T) {
 		expectedItem := Item{
 			Id:        "item_id",
 			Name:      "name",
-			UnitPrice: 1.23,
+			UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 			Quantity:  1,
 		}
 
 		order := NewOrder("customer_id", now)
 
 		// Act
-		err := order.AddItem(NewItem("item_id", "name", 1.23, 1), now)
+		err := order.AddItem(NewItem("item_id", "name", common.NewMoney(123, common.DefaultCurrency), 1), now)
 
 		// Assert
 		assert.NoError(t, err)
@@ -60,10 +63,10 @@ func TestOrder(t *testing.T) {
 
 		var err error
 
-		err = order.AddItem(NewItem("item_id_1", "name", 1.23, 1), now)
+		err = order.AddItem(NewItem("item_id_1", "name", common.NewMoney(123, common.DefaultCurrency), 1), now)
 		assert.NoError(t, err)
 
-		err = order.AddItem(NewItem("item_id_2", "name", 2.34, 2), now)
+		err = order.AddItem(NewItem("item_id_2", "name", common.NewMoney(234, common.DefaultCurrency), 2), now)
 		assert.NoError(t, err)
 
 		// Act
@@ -71,7 +74,7 @@ func TestOrder(t *testing.T) {
 
 		// Assert
 		assert.Equal(t, 3, order.TotalItems)
-		assert.Equal(t, 5.91, order.TotalPrice)
+		assert.Equal(t, common.NewMoney(591, common.DefaultCurrency), order.TotalPrice)
 	})
 
 	t.Run("Should update the state of the order", func(t *testing.T) {
@@ -89,6 +92,72 @@ func TestOrder(t *testing.T) {
 		assert.Equal(t, Received, order.State)
 		assert.Equal(t, now, order.StateUpdatedAt)
 		assert.Equal(t, now, order.UpdatedAt)
+		assert.Equal(t, []StateTransition{
+			NewStateTransition(order.Id, Created, Received, now),
+		}, order.PendingStateTransitions())
+	})
+
+	t.Run("Should clear the pending state transitions", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		err := order.UpdateState(Received, now)
+		assert.NoError(t, err)
+
+		// Act
+		order.ClearPendingStateTransitions()
+
+		// Assert
+		assert.Empty(t, order.PendingStateTransitions())
+	})
+
+	t.Run("Should raise the domain events of the order", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+		item := NewItem("item_id", "item_name", common.NewMoney(100, common.DefaultCurrency), 2)
+
+		// Act
+		err := order.AddItem(item, now)
+		assert.NoError(t, err)
+
+		err = order.UpdateState(Cancelled, now)
+		assert.NoError(t, err)
+
+		// Assert
+		events := order.PendingEvents()
+		assert.Len(t, events, 4)
+
+		assert.Equal(t, event_entity.OrderCreated, events[0].EventType)
+		assert.Equal(t, event_entity.ItemAdded, events[1].EventType)
+		assert.Equal(t, event_entity.OrderStateChanged, events[2].EventType)
+		assert.Equal(t, event_entity.OrderCancelled, events[3].EventType)
+
+		for _, event := range events {
+			assert.Equal(t, order.Id, event.AggregateId)
+			assert.Equal(t, 1, event.SchemaVersion)
+		}
+
+		assert.Equal(t, OrderCancelledEvent{
+			OrderId:        order.Id,
+			FromState:      Created,
+			FromStateTitle: "Created",
+			CancelledAt:    now,
+		}, events[3].Data)
+	})
+
+	t.Run("Should clear the pending events", func(t *testing.T) {
+		// Arrange
+		order := NewOrder("customer_id", time.Now())
+
+		// Act
+		order.ClearPendingEvents()
+
+		// Assert
+		assert.Empty(t, order.PendingEvents())
 	})
 
 	t.Run("Should return an error when trying to update the state to an invalid state", func(t *testing.T) {
@@ -120,6 +189,7 @@ func TestOrder(t *testing.T) {
 		assert.Equal(t, Created, order.State)
 		assert.Equal(t, past, order.StateUpdatedAt)
 		assert.Equal(t, past, order.UpdatedAt)
+		assert.Empty(t, order.PendingStateTransitions())
 	})
 
 	t.Run("Should refresh the state title", func(t *testing.T) {
@@ -209,22 +279,123 @@ func TestOrder(t *testing.T) {
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		order.Items = append(order.Items, NewItem("item_id", "name", 1.23, 1))
+		order.Items = append(order.Items, NewItem("item_id", "name", common.NewMoney(123, common.DefaultCurrency), 1))
 
 		// Act
-		err := order.AddItem(NewItem("item_id", "name", 1.23, 1), now)
+		err := order.AddItem(NewItem("item_id", "name", common.NewMoney(123, common.DefaultCurrency), 1), now)
 
 		// Assert
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, custom_error.ErrOrderItemAlreadyExists)
 	})
 
+	t.Run("Should return an error when trying to add an item with a different currency", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+		order.Items = append(order.Items, NewItem("item_id_1", "name", common.NewMoney(123, "BRL"), 1))
+
+		// Act
+		err := order.AddItem(NewItem("item_id_2", "name", common.NewMoney(123, "USD"), 1), now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderItemCurrencyMismatch)
+	})
+
+	t.Run("Should remove an item and recalculate the totals", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		err := order.AddItem(NewItem("item_id_1", "name", common.NewMoney(123, common.DefaultCurrency), 1), now)
+		assert.NoError(t, err)
+
+		err = order.AddItem(NewItem("item_id_2", "name", common.NewMoney(234, common.DefaultCurrency), 2), now)
+		assert.NoError(t, err)
+
+		// Act
+		err = order.RemoveItem("item_id_1", now)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, order.Items, 1)
+		assert.Equal(t, 2, order.TotalItems)
+		assert.Equal(t, common.NewMoney(468, common.DefaultCurrency), order.TotalPrice)
+	})
+
+	t.Run("Should return an error when trying to remove an item that does not exist", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		// Act
+		err := order.RemoveItem("item_id", now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderItemNotFound)
+	})
+
+	t.Run("Should set the item quantity and recalculate the totals", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		err := order.AddItem(NewItem("item_id", "name", common.NewMoney(123, common.DefaultCurrency), 1), now)
+		assert.NoError(t, err)
+
+		// Act
+		err = order.SetItemQuantity("item_id", 3, now)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 3, order.Items[0].Quantity)
+		assert.Equal(t, 3, order.TotalItems)
+		assert.Equal(t, common.NewMoney(369, common.DefaultCurrency), order.TotalPrice)
+	})
+
+	t.Run("Should return an error when trying to set an invalid item quantity", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		err := order.AddItem(NewItem("item_id", "name", common.NewMoney(123, common.DefaultCurrency), 1), now)
+		assert.NoError(t, err)
+
+		// Act
+		err = order.SetItemQuantity("item_id", 0, now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderItemInvalidQuantity)
+	})
+
+	t.Run("Should return an error when trying to set the quantity of an item that does not exist", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		// Act
+		err := order.SetItemQuantity("item_id", 2, now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderItemNotFound)
+	})
+
 	t.Run("Should return true if the order has items", func(t *testing.T) {
 		// Arrange
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		order.Items = append(order.Items, NewItem("item_id", "name", 1.23, 1))
+		order.Items = append(order.Items, NewItem("item_id", "name", common.NewMoney(123, common.DefaultCurrency), 1))
 
 		// Act
 		res := order.HasItems()
@@ -238,7 +409,7 @@ func TestOrder(t *testing.T) {
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, 1.23, now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 
 		order.Payments = append(order.Payments, payment)
 
@@ -254,7 +425,7 @@ func TestOrder(t *testing.T) {
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, 1.23, now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 		payment.State = payment_entity.Rejected
 
 		order.Payments = append(order.Payments, payment)
@@ -271,7 +442,7 @@ func TestOrder(t *testing.T) {
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, 1.23, now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 
 		order.Payments = append(order.Payments, payment)
 
@@ -301,7 +472,7 @@ func TestOrder(t *testing.T) {
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, 1.23, now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 		payment.State = payment_entity.WaitingForApproval
 
 		order.Payments = append(order.Payments, payment)
@@ -353,7 +524,7 @@ func TestOrder_ShouldCancel(t *testing.T) {
 		now := time.Now()
 
 		order := NewOrder("customer_id", now)
-		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, 1.23, now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 		payment.State = payment_entity.Approved
 
 		order.Payments = append(order.Payments, payment)
@@ -378,3 +549,24 @@ func TestOrder_ShouldCancel(t *testing.T) {
 		assert.False(t, res)
 	})
 }
+
+func TestOrder_CountPaymentsInState(t *testing.T) {
+	t.Run("should count the payments in the state", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		order := NewOrder("customer_id", now)
+
+		order.Payments = []payment_entity.Payment{
+			{PaymentId: "1", State: payment_entity.Rejected},
+			{PaymentId: "2", State: payment_entity.Rejected},
+			{PaymentId: "3", State: payment_entity.Approved},
+		}
+
+		// Act
+		res := order.CountPaymentsInState(payment_entity.Rejected)
+
+		// Assert
+		assert.Equal(t, 2, res)
+	})
+}
@@ -0,0 +1,36 @@
+package order_entity
+
+import "time"
+
+type StateTransition struct {
+	OrderId string `json:"order_id"`
+
+	FromState      OrderState `json:"from_state"`
+	FromStateTitle string     `json:"from_state_title"`
+	ToState        OrderState `json:"to_state"`
+	ToStateTitle   string     `json:"to_state_title"`
+
+	Actor    string `json:"actor"`
+	Source   string `json:"source"`
+	SourceId string `json:"source_id"`
+
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func NewStateTransition(orderId string, from OrderState, to OrderState, now time.Time) StateTransition {
+	return StateTransition{
+		OrderId: orderId,
+
+		FromState:      from,
+		FromStateTitle: from.String(),
+		ToState:        to,
+		ToStateTitle:   to.String(),
+
+		ChangedAt: now,
+	}
+}
+
+func (t *StateTransition) RefreshStateTitles() {
+	t.FromStateTitle = t.FromState.String()
+	t.ToStateTitle = t.ToState.String()
+}
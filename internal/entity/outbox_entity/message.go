@@ -0,0 +1,95 @@
+package outbox_entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = 5 * time.Minute
+)
+
+type Message struct {
+	Id        string       `json:"id"`
+	Topic     string       `json:"topic"`
+	EventType string       `json:"event_type"`
+	Subject   string       `json:"subject"`
+	Payload   string       `json:"payload"`
+	State     MessageState `json:"state"`
+	MessageId string       `json:"message_id"`
+
+	// TraceContext is the trace of the operation that stored the message,
+	// continued when the message is published
+	TraceContext map[string]string `json:"trace_context"`
+
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewMessage(topic string, payload interface{}, now time.Time) (Message, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Id:      uuid.NewString(),
+		Topic:   topic,
+		Payload: string(body),
+		State:   Pending,
+
+		NextAttemptAt: now,
+
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// NewEventMessage is a message that knows the type of what it carries and the
+// order it is about, used as the attributes of its envelope when published
+func NewEventMessage(topic string, eventType string, subject string, payload interface{}, now time.Time) (Message, error) {
+	message, err := NewMessage(topic, payload, now)
+	if err != nil {
+		return Message{}, err
+	}
+
+	message.EventType = eventType
+	message.Subject = subject
+
+	return message, nil
+}
+
+func (m *Message) MarkAsSent(messageId string, now time.Time) {
+	m.State = Sent
+	m.MessageId = messageId
+	m.Attempts++
+	m.LastError = ""
+	m.UpdatedAt = now
+}
+
+// MarkAsFailed schedules the next attempt with an exponential backoff, giving
+// up once the message reaches maxAttempts
+func (m *Message) MarkAsFailed(err error, maxAttempts int, now time.Time) {
+	m.Attempts++
+	m.LastError = err.Error()
+	m.UpdatedAt = now
+
+	if m.Attempts >= maxAttempts {
+		m.State = Failed
+		return
+	}
+
+	delay := minRetryDelay << (m.Attempts - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	m.NextAttemptAt = now.Add(delay)
+}
@@ -0,0 +1,24 @@
+package outbox_entity
+
+type MessageState int
+
+const (
+	None    MessageState = iota
+	Pending              // When the message is waiting to be published
+	Sent                 // When the message was published to the topic
+	Failed               // When the message exhausted all the publish attempts
+)
+
+func (s MessageState) String() string {
+	text, ok := map[MessageState]string{
+		None:    "None",
+		Pending: "Pending",
+		Sent:    "Sent",
+		Failed:  "Failed",
+	}[s]
+	if !ok {
+		return "Unknown"
+	}
+
+	return text
+}
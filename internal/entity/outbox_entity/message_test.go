@@ -0,0 +1,139 @@
+package outbox_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessage(t *testing.T) {
+	t.Run("Should create a pending message with the serialized payload", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		message, err := NewMessage("topic", map[string]string{"order_id": "123"}, now)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEmpty(t, message.Id)
+		assert.Equal(t, "topic", message.Topic)
+		assert.Equal(t, `{"order_id":"123"}`, message.Payload)
+		assert.Equal(t, Pending, message.State)
+		assert.Equal(t, now, message.NextAttemptAt)
+	})
+
+	t.Run("Should return error when payload can not be serialized", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		_, err := NewMessage("topic", make(chan int), now)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestNewEventMessage(t *testing.T) {
+	t.Run("Should create a message with the event type and subject", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		message, err := NewEventMessage("topic", "OrderCreated", "123", map[string]string{"order_id": "123"}, now)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "OrderCreated", message.EventType)
+		assert.Equal(t, "123", message.Subject)
+		assert.Equal(t, `{"order_id":"123"}`, message.Payload)
+	})
+
+	t.Run("Should return error when payload can not be serialized", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		_, err := NewEventMessage("topic", "OrderCreated", "123", make(chan int), now)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestMarkAsSent(t *testing.T) {
+	t.Run("Should mark the message as sent", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		message, err := NewMessage("topic", "payload", now)
+		assert.NoError(t, err)
+
+		// Act
+		message.MarkAsSent("message_id", now)
+
+		// Assert
+		assert.Equal(t, Sent, message.State)
+		assert.Equal(t, "message_id", message.MessageId)
+		assert.Equal(t, 1, message.Attempts)
+	})
+}
+
+func TestMarkAsFailed(t *testing.T) {
+	t.Run("Should schedule the next attempt with backoff", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		message, err := NewMessage("topic", "payload", now)
+		assert.NoError(t, err)
+
+		// Act
+		message.MarkAsFailed(assert.AnError, 5, now)
+		message.MarkAsFailed(assert.AnError, 5, now)
+
+		// Assert
+		assert.Equal(t, Pending, message.State)
+		assert.Equal(t, 2, message.Attempts)
+		assert.Equal(t, assert.AnError.Error(), message.LastError)
+		assert.Equal(t, now.Add(2*time.Second), message.NextAttemptAt)
+	})
+
+	t.Run("Should cap the backoff", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		message, err := NewMessage("topic", "payload", now)
+		assert.NoError(t, err)
+
+		message.Attempts = 100
+
+		// Act
+		message.MarkAsFailed(assert.AnError, 1000, now)
+
+		// Assert
+		assert.Equal(t, now.Add(maxRetryDelay), message.NextAttemptAt)
+	})
+
+	t.Run("Should give up when the max attempts is reached", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		message, err := NewMessage("topic", "payload", now)
+		assert.NoError(t, err)
+
+		// Act
+		message.MarkAsFailed(assert.AnError, 1, now)
+
+		// Assert
+		assert.Equal(t, Failed, message.State)
+	})
+}
+
+func TestMessageStateString(t *testing.T) {
+	t.Run("Should return the state title", func(t *testing.T) {
+		assert.Equal(t, "Pending", Pending.String())
+		assert.Equal(t, "Unknown", MessageState(42).String())
+	})
+}
@@ -0,0 +1,28 @@
+package payment_entity
+
+import (
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+)
+
+type PaymentRequestedEvent struct {
+	OrderId    string       `json:"order_id"`
+	PaymentId  string       `json:"payment_id"`
+	TotalItems int          `json:"total_items"`
+	Amount     common.Money `json:"amount"`
+}
+
+type PaymentApprovedEvent struct {
+	OrderId    string       `json:"order_id"`
+	PaymentId  string       `json:"payment_id"`
+	Amount     common.Money `json:"amount"`
+	ApprovedAt time.Time    `json:"approved_at"`
+}
+
+type PaymentRejectedEvent struct {
+	OrderId    string       `json:"order_id"`
+	PaymentId  string       `json:"payment_id"`
+	Amount     common.Money `json:"amount"`
+	RejectedAt time.Time    `json:"rejected_at"`
+}
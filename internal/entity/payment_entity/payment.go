@@ -1,22 +1,29 @@
 package payment_entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
+)
 
 type Payment struct {
 	OrderId   string `json:"order_id"`
 	PaymentId string `json:"payment_id"`
 
 	TotalItems int          `json:"total_items"`
-	Amount     float64      `json:"amount"`
+	Amount     common.Money `json:"amount"`
 	State      PaymentState `json:"state"`
 	StateTitle string       `json:"state_title"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	pendingEvents []event_entity.Event
 }
 
-func NewPayment(orderId string, paymentId string, totalItems int, amount float64, now time.Time) Payment {
-	return Payment{
+func NewPayment(orderId string, paymentId string, totalItems int, amount common.Money, now time.Time) Payment {
+	payment := Payment{
 		OrderId:   orderId,
 		PaymentId: paymentId,
 
@@ -27,6 +34,15 @@ func NewPayment(orderId string, paymentId string, totalItems int, amount float64
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+
+	payment.raise(event_entity.PaymentRequested, PaymentRequestedEvent{
+		OrderId:    orderId,
+		PaymentId:  paymentId,
+		TotalItems: totalItems,
+		Amount:     amount,
+	}, now)
+
+	return payment
 }
 
 func (p *Payment) IsInState(states ...PaymentState) bool {
@@ -48,4 +64,35 @@ func (p *Payment) UpdateState(newState PaymentState, now time.Time) {
 	p.StateTitle = p.State.String()
 
 	p.UpdatedAt = now
+
+	switch newState {
+	case Approved:
+		p.raise(event_entity.PaymentApproved, PaymentApprovedEvent{
+			OrderId:    p.OrderId,
+			PaymentId:  p.PaymentId,
+			Amount:     p.Amount,
+			ApprovedAt: now,
+		}, now)
+	case Rejected:
+		p.raise(event_entity.PaymentRejected, PaymentRejectedEvent{
+			OrderId:    p.OrderId,
+			PaymentId:  p.PaymentId,
+			Amount:     p.Amount,
+			RejectedAt: now,
+		}, now)
+	}
+}
+
+// PendingEvents returns the domain events raised since the payment was
+// loaded, they belong to the order the payment is part of
+func (p *Payment) PendingEvents() []event_entity.Event {
+	return p.pendingEvents
+}
+
+func (p *Payment) ClearPendingEvents() {
+	p.pendingEvents = nil
+}
+
+func (p *Payment) raise(eventType event_entity.EventType, data interface{}, now time.Time) {
+	p.pendingEvents = append(p.pendingEvents, event_entity.NewEvent(eventType, p.OrderId, data, now))
 }
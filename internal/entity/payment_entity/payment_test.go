@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,7 +17,7 @@ func TestIsInState(t *testing.T) {
 		states := []PaymentState{WaitingForApproval, Approved, Rejected}
 
 		for _, state := range states {
-			payment := NewPayment("order_id", "payment_id", 1, 1.23, now)
+			payment := NewPayment("order_id", "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 			payment.State = state
 
 			// Act
@@ -33,7 +35,7 @@ func TestIsInState(t *testing.T) {
 		states := []PaymentState{WaitingForApproval, Approved, Rejected}
 
 		for _, state := range states {
-			payment := NewPayment("order_id", "payment_id", 1, 1.23, now)
+			payment := NewPayment("order_id", "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 			payment.State = state
 
 			// Act
@@ -50,7 +52,7 @@ func TestRefreshStateTitle(t *testing.T) {
 		// Arrange
 		now := time.Now()
 
-		payment := NewPayment("order_id", "payment_id", 1, 1.23, now)
+		payment := NewPayment("order_id", "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 		payment.State = Approved
 
 		// Act
@@ -66,7 +68,7 @@ func TestUpdateState(t *testing.T) {
 		// Arrange
 		now := time.Now()
 
-		payment := NewPayment("order_id", "payment_id", 1, 1.23, now)
+		payment := NewPayment("order_id", "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
 
 		// Act
 		payment.UpdateState(Approved, now)
@@ -76,4 +78,28 @@ func TestUpdateState(t *testing.T) {
 		assert.Equal(t, "Approved", payment.StateTitle)
 		assert.Equal(t, now, payment.UpdatedAt)
 	})
+
+	t.Run("Should raise the payment events", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		payment := NewPayment("order_id", "payment_id", 1, common.NewMoney(123, common.DefaultCurrency), now)
+
+		// Act
+		payment.UpdateState(Rejected, now)
+
+		// Assert
+		events := payment.PendingEvents()
+		assert.Len(t, events, 2)
+
+		assert.Equal(t, event_entity.PaymentRequested, events[0].EventType)
+		assert.Equal(t, event_entity.PaymentRejected, events[1].EventType)
+		assert.Equal(t, "order_id", events[1].AggregateId)
+		assert.Equal(t, PaymentRejectedEvent{
+			OrderId:    "order_id",
+			PaymentId:  "payment_id",
+			Amount:     common.NewMoney(123, common.DefaultCurrency),
+			RejectedAt: now,
+		}, events[1].Data)
+	})
 }
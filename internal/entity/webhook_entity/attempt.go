@@ -0,0 +1,46 @@
+package webhook_entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attempt is one entry of the delivery log of a subscription
+type Attempt struct {
+	Id             string        `json:"id"`
+	DeliveryId     string        `json:"delivery_id"`
+	SubscriptionId string        `json:"subscription_id"`
+	EventId        string        `json:"event_id"`
+	EventType      EventType     `json:"event_type"`
+	Number         int           `json:"number"`
+	State          DeliveryState `json:"state"`
+	StateTitle     string        `json:"state_title"`
+	StatusCode     int           `json:"status_code"`
+	Error          string        `json:"error"`
+	DurationMs     int64         `json:"duration_ms"`
+	AttemptedAt    time.Time     `json:"attempted_at"`
+}
+
+// NewAttempt records the outcome of the last attempt of the delivery, so it
+// must be called after the delivery is marked as delivered or failed
+func NewAttempt(delivery Delivery, duration time.Duration, now time.Time) Attempt {
+	return Attempt{
+		Id:             uuid.NewString(),
+		DeliveryId:     delivery.Id,
+		SubscriptionId: delivery.SubscriptionId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Number:         delivery.Attempts,
+		State:          delivery.State,
+		StateTitle:     delivery.State.String(),
+		StatusCode:     delivery.LastStatusCode,
+		Error:          delivery.LastError,
+		DurationMs:     duration.Milliseconds(),
+		AttemptedAt:    now,
+	}
+}
+
+func (a *Attempt) RefreshStateTitle() {
+	a.StateTitle = a.State.String()
+}
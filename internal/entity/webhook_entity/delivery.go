@@ -0,0 +1,56 @@
+package webhook_entity
+
+import (
+	"time"
+)
+
+const (
+	minRetryDelay = 10 * time.Second
+	maxRetryDelay = time.Hour
+)
+
+type Delivery struct {
+	Id             string        `json:"id"`
+	SubscriptionId string        `json:"subscription_id"`
+	EventId        string        `json:"event_id"`
+	EventType      EventType     `json:"event_type"`
+	Payload        string        `json:"payload"`
+	State          DeliveryState `json:"state"`
+
+	Attempts       int       `json:"attempts"`
+	LastStatusCode int       `json:"last_status_code"`
+	LastError      string    `json:"last_error"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (d *Delivery) MarkAsDelivered(statusCode int, now time.Time) {
+	d.State = Delivered
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.UpdatedAt = now
+}
+
+// MarkAsFailed schedules the next attempt with an exponential backoff, giving
+// up once the delivery reaches maxAttempts
+func (d *Delivery) MarkAsFailed(statusCode int, err error, maxAttempts int, now time.Time) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = err.Error()
+	d.UpdatedAt = now
+
+	if d.Attempts >= maxAttempts {
+		d.State = Failed
+		return
+	}
+
+	delay := minRetryDelay << (d.Attempts - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	d.NextAttemptAt = now.Add(delay)
+}
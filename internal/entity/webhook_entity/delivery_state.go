@@ -0,0 +1,25 @@
+package webhook_entity
+
+type DeliveryState int
+
+const (
+	None      DeliveryState = iota
+	Pending                 // When the delivery is waiting to be sent
+	Delivered               // When the subscriber acknowledged the delivery
+	Failed                  // When the delivery exhausted all the attempts
+)
+
+func (s DeliveryState) String() string {
+	text, ok := map[DeliveryState]string{
+		None:      "None",
+		Pending:   "Pending",
+		Delivered: "Delivered",
+		Failed:    "Failed",
+	}[s]
+
+	if !ok {
+		return "Unknown"
+	}
+
+	return text
+}
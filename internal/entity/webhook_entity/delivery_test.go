@@ -0,0 +1,89 @@
+package webhook_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkAsDelivered(t *testing.T) {
+	t.Run("Should mark the delivery as delivered", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		delivery := Delivery{State: Pending, LastError: "error"}
+
+		// Act
+		delivery.MarkAsDelivered(200, now)
+
+		// Assert
+		assert.Equal(t, Delivered, delivery.State)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, 200, delivery.LastStatusCode)
+		assert.Empty(t, delivery.LastError)
+	})
+}
+
+func TestMarkAsFailed(t *testing.T) {
+	t.Run("Should schedule the next attempt with backoff", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		delivery := Delivery{State: Pending}
+
+		// Act
+		delivery.MarkAsFailed(500, assert.AnError, 5, now)
+		delivery.MarkAsFailed(500, assert.AnError, 5, now)
+
+		// Assert
+		assert.Equal(t, Pending, delivery.State)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, 500, delivery.LastStatusCode)
+		assert.Equal(t, now.Add(20*time.Second), delivery.NextAttemptAt)
+	})
+
+	t.Run("Should cap the backoff", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		delivery := Delivery{State: Pending, Attempts: 100}
+
+		// Act
+		delivery.MarkAsFailed(0, assert.AnError, 1000, now)
+
+		// Assert
+		assert.Equal(t, now.Add(maxRetryDelay), delivery.NextAttemptAt)
+	})
+
+	t.Run("Should give up when the max attempts is reached", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		delivery := Delivery{State: Pending, Attempts: 4}
+
+		// Act
+		delivery.MarkAsFailed(0, assert.AnError, 5, now)
+
+		// Assert
+		assert.Equal(t, Failed, delivery.State)
+		assert.Equal(t, 5, delivery.Attempts)
+	})
+}
+
+func TestNewAttempt(t *testing.T) {
+	t.Run("Should record the outcome of the last attempt", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		delivery := Delivery{Id: "delivery-id", SubscriptionId: "subscription-id", EventType: OrderStateChanged, State: Pending}
+		delivery.MarkAsFailed(500, assert.AnError, 5, now)
+
+		// Act
+		attempt := NewAttempt(delivery, 150*time.Millisecond, now)
+
+		// Assert
+		assert.Equal(t, "delivery-id", attempt.DeliveryId)
+		assert.Equal(t, "subscription-id", attempt.SubscriptionId)
+		assert.Equal(t, 1, attempt.Number)
+		assert.Equal(t, 500, attempt.StatusCode)
+		assert.Equal(t, assert.AnError.Error(), attempt.Error)
+		assert.Equal(t, int64(150), attempt.DurationMs)
+		assert.Equal(t, "Pending", attempt.StateTitle)
+	})
+}
@@ -0,0 +1,25 @@
+package webhook_entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is the body posted to the subscribers, its id is the same for every
+// subscription so the receivers can drop the duplicates of a retried delivery
+type Event struct {
+	Id         string      `json:"id"`
+	Type       EventType   `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+func NewEvent(eventType EventType, data interface{}, now time.Time) Event {
+	return Event{
+		Id:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: now,
+		Data:       data,
+	}
+}
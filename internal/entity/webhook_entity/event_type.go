@@ -0,0 +1,12 @@
+package webhook_entity
+
+type EventType string
+
+const (
+	OrderStateChanged   EventType = "order.state_changed"
+	PaymentStateChanged EventType = "payment.state_changed"
+)
+
+func IsValidEventType(eventType EventType) bool {
+	return eventType == OrderStateChanged || eventType == PaymentStateChanged
+}
@@ -0,0 +1,66 @@
+package webhook_entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Subscription struct {
+	Id         string      `json:"id"`
+	Url        string      `json:"url"`
+	Secret     string      `json:"-"`
+	EventTypes []EventType `json:"event_types"`
+	Active     bool        `json:"active"`
+
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewSubscription(url string, secret string, eventTypes []EventType, now time.Time) Subscription {
+	return Subscription{
+		Id:         uuid.NewString(),
+		Url:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (s *Subscription) Subscribes(eventType EventType) bool {
+	for _, subscribed := range s.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Subscription) RecordSuccess(now time.Time) {
+	if s.ConsecutiveFailures == 0 {
+		return
+	}
+
+	s.ConsecutiveFailures = 0
+	s.UpdatedAt = now
+}
+
+func (s *Subscription) Enable(now time.Time) {
+	s.Active = true
+	s.ConsecutiveFailures = 0
+	s.DisabledAt = nil
+	s.UpdatedAt = now
+}
+
+func (s *Subscription) Disable(now time.Time) {
+	s.Active = false
+	s.DisabledAt = &now
+	s.UpdatedAt = now
+}
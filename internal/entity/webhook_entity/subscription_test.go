@@ -0,0 +1,58 @@
+package webhook_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSubscription(t *testing.T) {
+	t.Run("Should create an active subscription", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		// Act
+		subscription := NewSubscription("https://partner.com/hook", "secret", []EventType{OrderStateChanged}, now)
+
+		// Assert
+		assert.NotEmpty(t, subscription.Id)
+		assert.True(t, subscription.Active)
+		assert.True(t, subscription.Subscribes(OrderStateChanged))
+		assert.False(t, subscription.Subscribes(PaymentStateChanged))
+		assert.Equal(t, now, subscription.CreatedAt)
+	})
+}
+
+func TestRecordSuccess(t *testing.T) {
+	t.Run("Should reset the failures on success", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		subscription := NewSubscription("https://partner.com/hook", "secret", []EventType{OrderStateChanged}, now)
+		subscription.ConsecutiveFailures = 2
+
+		// Act
+		subscription.RecordSuccess(now)
+
+		// Assert
+		assert.Equal(t, 0, subscription.ConsecutiveFailures)
+	})
+}
+
+func TestEnable(t *testing.T) {
+	t.Run("Should enable a disabled subscription", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+		subscription := NewSubscription("https://partner.com/hook", "secret", []EventType{OrderStateChanged}, now)
+		subscription.ConsecutiveFailures = 3
+		subscription.Disable(now)
+
+		// Act
+		subscription.Enable(now)
+
+		// Assert
+		assert.True(t, subscription.Active)
+		assert.Equal(t, 0, subscription.ConsecutiveFailures)
+		assert.Nil(t, subscription.DisabledAt)
+	})
+}
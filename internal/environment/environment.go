@@ -2,26 +2,173 @@ package environment
 
 import (
 	"context"
+	"time"
+)
+
+const (
+	BrokerDriverAws      = "aws"
+	BrokerDriverMemory   = "memory"
+	BrokerDriverFile     = "file"
+	BrokerDriverPostgres = "postgres"
+)
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOtlp   = "otlp"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
 )
 
 type ApiConfig struct {
-	Port       int    `env:"PORT, default=8080"`
-	EnvName    string `env:"ENV_NAME, default=development"`
-	ApiVersion string `env:"VERSION, default=v1"`
+	Port        int    `env:"PORT, default=8080"`
+	EnvName     string `env:"ENV_NAME, default=development"`
+	ApiVersion  string `env:"VERSION, default=v1"`
+	MoneyFormat string `env:"MONEY_FORMAT, default=decimal"`
+
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT, default=15s"`
 }
 
 func (c *ApiConfig) IsDevelopment() bool {
 	return c.EnvName == "development"
 }
 
+type WorkerConfig struct {
+	HealthPort int `env:"HEALTH_PORT, default=8081"`
+
+	// DrainTimeout is how long the messages in flight have to be handled on
+	// shutdown, on top of the SHUTDOWN_TIMEOUT of the other phases
+	DrainTimeout time.Duration `env:"DRAIN_TIMEOUT, default=10s"`
+}
+
 type DatabaseConfig struct {
-	Url           string `env:"URL, required"`
+	Url           string `env:"URL"`
 	UrlSecretName string `env:"URL_SECRET_NAME, required"`
+	AutoMigrate   bool   `env:"AUTO_MIGRATE, default=false"`
+}
+
+type SecretConfig struct {
+	Providers       []string      `env:"PROVIDERS, default=aws"`
+	Directory       string        `env:"DIRECTORY, default=/mnt/secrets-store"`
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL, default=5m"`
+}
+
+type AuthConfig struct {
+	HmacSecret          string        `env:"HMAC_SECRET"`
+	JwksFile            string        `env:"JWKS_FILE"`
+	JwksUrl             string        `env:"JWKS_URL"`
+	JwksCacheTtl        time.Duration `env:"JWKS_CACHE_TTL, default=10m"`
+	JwksRefreshInterval time.Duration `env:"JWKS_REFRESH_INTERVAL, default=30s"`
+	Issuer              string        `env:"ISSUER"`
+	Audience            string        `env:"AUDIENCE"`
+	ClockSkew           time.Duration `env:"CLOCK_SKEW, default=30s"`
+	RolesClaim          string        `env:"ROLES_CLAIM, default=roles"`
+}
+
+type OrderConfig struct {
+	PaymentRules string `env:"PAYMENT_RULES, default=Approved:Received,Rejected:Cancelled:3"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"POLL_INTERVAL, default=5s"`
+	BatchSize    int           `env:"BATCH_SIZE, default=10"`
+	MaxAttempts  int           `env:"MAX_ATTEMPTS, default=10"`
+}
+
+type InboxConfig struct {
+	Retention     time.Duration `env:"RETENTION, default=168h"`
+	PurgeInterval time.Duration `env:"PURGE_INTERVAL, default=1h"`
+}
+
+type IdempotencyConfig struct {
+	Ttl           time.Duration `env:"TTL, default=24h"`
+	LockTimeout   time.Duration `env:"LOCK_TIMEOUT, default=1m"`
+	PurgeInterval time.Duration `env:"PURGE_INTERVAL, default=1h"`
+}
+
+type TrackingConfig struct {
+	Channel       string        `env:"CHANNEL, default=order_tracking"`
+	Heartbeat     time.Duration `env:"HEARTBEAT, default=15s"`
+	NotifyEnabled bool          `env:"NOTIFY_ENABLED, default=true"`
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration `env:"POLL_INTERVAL, default=5s"`
+	BatchSize    int           `env:"BATCH_SIZE, default=10"`
+	MaxAttempts  int           `env:"MAX_ATTEMPTS, default=10"`
+	DisableAfter int           `env:"DISABLE_AFTER, default=20"`
+	Timeout      time.Duration `env:"TIMEOUT, default=10s"`
+}
+
+type QueueConfig struct {
+	Concurrency    int           `env:"CONCURRENCY, default=10"`
+	MaxReceives    int           `env:"MAX_RECEIVES, default=5"`
+	RetryBaseDelay time.Duration `env:"RETRY_BASE_DELAY, default=5s"`
+	RetryMaxDelay  time.Duration `env:"RETRY_MAX_DELAY, default=15m"`
+
+	// only the SNS notifications are signed, with the verification on the
+	// CloudEvents and raw deliveries are dead-lettered as unsigned
+	VerifySignature   bool     `env:"VERIFY_SIGNATURE, default=false"`
+	SigningCertBundle string   `env:"SIGNING_CERT_BUNDLE"`
+	AllowedTopicArns  []string `env:"ALLOWED_TOPIC_ARNS"`
+}
+
+type BrokerConfig struct {
+	Driver            string        `env:"DRIVER, default=aws"`
+	Directory         string        `env:"DIRECTORY, default=.broker"`
+	VisibilityTimeout time.Duration `env:"VISIBILITY_TIMEOUT, default=30s"`
+	PollInterval      time.Duration `env:"POLL_INTERVAL, default=1s"`
+
+	// Subscriptions lists the queues of the postgres broker fed by each topic,
+	// as topic:queue entries
+	Subscriptions []string `env:"SUBSCRIPTIONS"`
+}
+
+func (c *BrokerConfig) IsAws() bool {
+	return c.Driver == BrokerDriverAws
+}
+
+func (c *BrokerConfig) IsKnownDriver() bool {
+	switch c.Driver {
+	case BrokerDriverAws, BrokerDriverMemory, BrokerDriverFile, BrokerDriverPostgres:
+		return true
+	}
+
+	return false
+}
+
+type TracingConfig struct {
+	Exporter    string  `env:"EXPORTER, default=none"`
+	Endpoint    string  `env:"ENDPOINT"`
+	File        string  `env:"FILE, default=traces.json"`
+	ServiceName string  `env:"SERVICE_NAME, default=ms-order-management"`
+	SampleRatio float64 `env:"SAMPLE_RATIO, default=1"`
+}
+
+func (c *TracingConfig) IsEnabled() bool {
+	return c.Exporter != TracingExporterNone
+}
+
+func (c *TracingConfig) IsKnownExporter() bool {
+	switch c.Exporter {
+	case TracingExporterNone, TracingExporterOtlp, TracingExporterStdout, TracingExporterFile:
+		return true
+	}
+
+	return false
+}
+
+type CloudEventsConfig struct {
+	Enabled bool   `env:"ENABLED, default=false"`
+	Source  string `env:"SOURCE, default=ms-order-management"`
 }
 
 type CloudConfig struct {
 	OrderPaymentTopicName string `env:"ORDER_PAYMENT_TOPIC_NAME, required"`
+	OrderEventsTopicName  string `env:"ORDER_EVENTS_TOPIC_NAME, default=OrderEventsTopic"`
 	UpdateOrderQueueName  string `env:"UPDATE_ORDER_QUEUE_NAME, required"`
+	UpdateOrderDLQName    string `env:"UPDATE_ORDER_DLQ_NAME, default=UpdateOrderDLQ"`
+
+	PaymentResponseTopicName string `env:"PAYMENT_RESPONSE_TOPIC_NAME, default=PaymentResponseTopic"`
 
 	BaseEndpoint string `env:"BASE_ENDPOINT"`
 }
@@ -31,9 +178,22 @@ func (c *CloudConfig) IsBaseEndpointSet() bool {
 }
 
 type Config struct {
-	ApiConfig   *ApiConfig      `env:",prefix=API_"`
-	DbConfig    *DatabaseConfig `env:",prefix=DB_"`
-	CloudConfig *CloudConfig    `env:",prefix=AWS_"`
+	ApiConfig         *ApiConfig         `env:",prefix=API_"`
+	WorkerConfig      *WorkerConfig      `env:",prefix=WORKER_"`
+	DbConfig          *DatabaseConfig    `env:",prefix=DB_"`
+	SecretConfig      *SecretConfig      `env:",prefix=SECRET_"`
+	AuthConfig        *AuthConfig        `env:",prefix=AUTH_"`
+	OrderConfig       *OrderConfig       `env:",prefix=ORDER_"`
+	OutboxConfig      *OutboxConfig      `env:",prefix=OUTBOX_"`
+	InboxConfig       *InboxConfig       `env:",prefix=INBOX_"`
+	IdempotencyConfig *IdempotencyConfig `env:",prefix=IDEMPOTENCY_"`
+	TrackingConfig    *TrackingConfig    `env:",prefix=TRACKING_"`
+	WebhookConfig     *WebhookConfig     `env:",prefix=WEBHOOK_"`
+	QueueConfig       *QueueConfig       `env:",prefix=QUEUE_"`
+	BrokerConfig      *BrokerConfig      `env:",prefix=BROKER_"`
+	CloudEventsConfig *CloudEventsConfig `env:",prefix=CLOUDEVENTS_"`
+	TracingConfig     *TracingConfig     `env:",prefix=TRACING_"`
+	CloudConfig       *CloudConfig       `env:",prefix=AWS_"`
 }
 
 type Environment interface {
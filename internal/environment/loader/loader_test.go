@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
 	"github.com/stretchr/testify/assert"
@@ -39,17 +40,89 @@ func TestGetEnvironment(t *testing.T) {
 
 		expected := &environment.Config{
 			ApiConfig: &environment.ApiConfig{
-				Port:       8080,
-				EnvName:    "development",
-				ApiVersion: "v1",
+				Port:        8080,
+				EnvName:     "development",
+				ApiVersion:  "v1",
+				MoneyFormat: "decimal",
+
+				ShutdownTimeout: 15 * time.Second,
+			},
+			WorkerConfig: &environment.WorkerConfig{
+				HealthPort:   8081,
+				DrainTimeout: 10 * time.Second,
 			},
 			DbConfig: &environment.DatabaseConfig{
 				Url:           "db://host:1234",
 				UrlSecretName: "db-orders-url-secret",
 			},
+			SecretConfig: &environment.SecretConfig{
+				Providers:       []string{"aws"},
+				Directory:       "/mnt/secrets-store",
+				RefreshInterval: 5 * time.Minute,
+			},
+			AuthConfig: &environment.AuthConfig{
+				JwksCacheTtl:        10 * time.Minute,
+				JwksRefreshInterval: 30 * time.Second,
+				ClockSkew:           30 * time.Second,
+				RolesClaim:          "roles",
+			},
+			OrderConfig: &environment.OrderConfig{
+				PaymentRules: "Approved:Received,Rejected:Cancelled:3",
+			},
+			OutboxConfig: &environment.OutboxConfig{
+				PollInterval: 5 * time.Second,
+				BatchSize:    10,
+				MaxAttempts:  10,
+			},
+			InboxConfig: &environment.InboxConfig{
+				Retention:     168 * time.Hour,
+				PurgeInterval: time.Hour,
+			},
+			IdempotencyConfig: &environment.IdempotencyConfig{
+				Ttl:           24 * time.Hour,
+				LockTimeout:   time.Minute,
+				PurgeInterval: time.Hour,
+			},
+			TrackingConfig: &environment.TrackingConfig{
+				Channel:       "order_tracking",
+				Heartbeat:     15 * time.Second,
+				NotifyEnabled: true,
+			},
+			WebhookConfig: &environment.WebhookConfig{
+				PollInterval: 5 * time.Second,
+				BatchSize:    10,
+				MaxAttempts:  10,
+				DisableAfter: 20,
+				Timeout:      10 * time.Second,
+			},
+			QueueConfig: &environment.QueueConfig{
+				Concurrency:    10,
+				MaxReceives:    5,
+				RetryBaseDelay: 5 * time.Second,
+				RetryMaxDelay:  15 * time.Minute,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver:            "aws",
+				Directory:         ".broker",
+				VisibilityTimeout: 30 * time.Second,
+				PollInterval:      time.Second,
+			},
+			CloudEventsConfig: &environment.CloudEventsConfig{
+				Source: "ms-order-management",
+			},
+			TracingConfig: &environment.TracingConfig{
+				Exporter:    "none",
+				File:        "traces.json",
+				ServiceName: "ms-order-management",
+				SampleRatio: 1,
+			},
 			CloudConfig: &environment.CloudConfig{
 				OrderPaymentTopicName: "order_payment",
+				OrderEventsTopicName:  "OrderEventsTopic",
 				UpdateOrderQueueName:  "update_order",
+				UpdateOrderDLQName:    "UpdateOrderDLQ",
+
+				PaymentResponseTopicName: "PaymentResponseTopic",
 			},
 		}
 
@@ -86,17 +159,89 @@ func TestGetEnvironmentFromFile(t *testing.T) {
 
 		expected := &environment.Config{
 			ApiConfig: &environment.ApiConfig{
-				Port:       8080,
-				EnvName:    "development",
-				ApiVersion: "v1",
+				Port:        8080,
+				EnvName:     "development",
+				ApiVersion:  "v1",
+				MoneyFormat: "decimal",
+
+				ShutdownTimeout: 15 * time.Second,
+			},
+			WorkerConfig: &environment.WorkerConfig{
+				HealthPort:   8081,
+				DrainTimeout: 10 * time.Second,
 			},
 			DbConfig: &environment.DatabaseConfig{
 				Url:           "db://host:1234",
 				UrlSecretName: "db-orders-url-secret",
 			},
+			SecretConfig: &environment.SecretConfig{
+				Providers:       []string{"aws"},
+				Directory:       "/mnt/secrets-store",
+				RefreshInterval: 5 * time.Minute,
+			},
+			AuthConfig: &environment.AuthConfig{
+				JwksCacheTtl:        10 * time.Minute,
+				JwksRefreshInterval: 30 * time.Second,
+				ClockSkew:           30 * time.Second,
+				RolesClaim:          "roles",
+			},
+			OrderConfig: &environment.OrderConfig{
+				PaymentRules: "Approved:Received,Rejected:Cancelled:3",
+			},
+			OutboxConfig: &environment.OutboxConfig{
+				PollInterval: 5 * time.Second,
+				BatchSize:    10,
+				MaxAttempts:  10,
+			},
+			InboxConfig: &environment.InboxConfig{
+				Retention:     168 * time.Hour,
+				PurgeInterval: time.Hour,
+			},
+			IdempotencyConfig: &environment.IdempotencyConfig{
+				Ttl:           24 * time.Hour,
+				LockTimeout:   time.Minute,
+				PurgeInterval: time.Hour,
+			},
+			TrackingConfig: &environment.TrackingConfig{
+				Channel:       "order_tracking",
+				Heartbeat:     15 * time.Second,
+				NotifyEnabled: true,
+			},
+			WebhookConfig: &environment.WebhookConfig{
+				PollInterval: 5 * time.Second,
+				BatchSize:    10,
+				MaxAttempts:  10,
+				DisableAfter: 20,
+				Timeout:      10 * time.Second,
+			},
+			QueueConfig: &environment.QueueConfig{
+				Concurrency:    10,
+				MaxReceives:    5,
+				RetryBaseDelay: 5 * time.Second,
+				RetryMaxDelay:  15 * time.Minute,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver:            "aws",
+				Directory:         ".broker",
+				VisibilityTimeout: 30 * time.Second,
+				PollInterval:      time.Second,
+			},
+			CloudEventsConfig: &environment.CloudEventsConfig{
+				Source: "ms-order-management",
+			},
+			TracingConfig: &environment.TracingConfig{
+				Exporter:    "none",
+				File:        "traces.json",
+				ServiceName: "ms-order-management",
+				SampleRatio: 1,
+			},
 			CloudConfig: &environment.CloudConfig{
 				OrderPaymentTopicName: "order_payment",
+				OrderEventsTopicName:  "OrderEventsTopic",
 				UpdateOrderQueueName:  "update_order",
+				UpdateOrderDLQName:    "UpdateOrderDLQ",
+
+				PaymentResponseTopicName: "PaymentResponseTopic",
 			},
 		}
 
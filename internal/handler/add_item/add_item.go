@@ -3,6 +3,7 @@ package add_item
 import (
 	"net/http"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update"
@@ -47,6 +48,10 @@ func (h *Handler) Handle(ctx echo.Context) error {
 		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
 	}
 
+	if err := handler.CheckIfMatch(ctx, order); err != nil {
+		return err
+	}
+
 	if order.IsCompleted() {
 		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderAlreadyCompleted)
 	}
@@ -71,5 +76,7 @@ func (h *Handler) Handle(ctx echo.Context) error {
 
 	order.RefreshStateTitle()
 
+	handler.SetETag(ctx, order)
+
 	return ctx.JSON(http.StatusOK, order)
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
@@ -39,7 +40,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -67,6 +68,60 @@ func TestHandle(t *testing.T) {
 		// Assert
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `"0"`, resp.Header().Get("ETag"))
+		getService.AssertExpectations(t)
+		updateService.AssertExpectations(t)
+	})
+
+	t.Run("Should return precondition failed when the order version does not match", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateService := mocks.NewMockUpdateOrderService[update.UpdateOrderDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State:   order_entity.Created,
+				Version: 2,
+			}, nil).
+			Once()
+
+		reqBody := update.UpdateOrderDto{
+			Items: []update.UpdateOrderItemDto{
+				{
+					ItemId:    uuid.NewString(),
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
+					Quantity:  1,
+				},
+			},
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.POST, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("If-Match", `"1"`)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(uuid.NewString())
+
+		handler := NewHandler(getService, updateService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusPreconditionFailed, he.Code)
 		getService.AssertExpectations(t)
 		updateService.AssertExpectations(t)
 	})
@@ -84,7 +139,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -139,7 +194,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -196,7 +251,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -253,7 +308,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -314,7 +369,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -375,7 +430,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -437,7 +492,7 @@ func TestHandle(t *testing.T) {
 			Items: []update.UpdateOrderItemDto{
 				{
 					ItemId:    uuid.NewString(),
-					UnitPrice: 1.23,
+					UnitPrice: common.NewMoney(123, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
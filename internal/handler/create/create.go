@@ -3,6 +3,7 @@ package create
 import (
 	"net/http"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/create"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
@@ -39,5 +40,7 @@ func (h *Handler) Handle(ctx echo.Context) error {
 
 	order.RefreshStateTitle()
 
+	handler.SetETag(ctx, *order)
+
 	return ctx.JSON(http.StatusCreated, order)
 }
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+func ETag(order order_entity.Order) string {
+	return fmt.Sprintf("\"%d\"", order.Version)
+}
+
+// SetETag exposes the order version, so the client can send it back on the
+// If-Match header of the next update
+func SetETag(ctx echo.Context, order order_entity.Order) {
+	ctx.Response().Header().Set(HeaderETag, ETag(order))
+}
+
+// CheckIfMatch rejects the request when its If-Match header does not list the
+// current version of the order, a request without the header being accepted
+func CheckIfMatch(ctx echo.Context, order order_entity.Order) error {
+	ifMatch := ctx.Request().Header.Get(HeaderIfMatch)
+	if ifMatch == "" {
+		return nil
+	}
+
+	etag := ETag(order)
+
+	for _, value := range strings.Split(ifMatch, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || value == etag {
+			return nil
+		}
+	}
+
+	return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderPreconditionFailed)
+}
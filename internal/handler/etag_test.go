@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetETag(t *testing.T) {
+	t.Run("Should set the order version as the ETag", func(t *testing.T) {
+		// Arrange
+		resp := httptest.NewRecorder()
+
+		ctx := echo.New().NewContext(httptest.NewRequest(echo.GET, "/", nil), resp)
+
+		// Act
+		SetETag(ctx, order_entity.Order{Version: 3})
+
+		// Assert
+		assert.Equal(t, `"3"`, resp.Header().Get(HeaderETag))
+	})
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	order := order_entity.Order{Version: 3}
+
+	t.Run("Should accept the request without If-Match", func(t *testing.T) {
+		// Arrange
+		ctx := echo.New().NewContext(httptest.NewRequest(echo.PATCH, "/", nil), httptest.NewRecorder())
+
+		// Act
+		err := CheckIfMatch(ctx, order)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should accept the request matching the order version", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.PATCH, "/", nil)
+		req.Header.Set(HeaderIfMatch, `"2", "3"`)
+
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+		// Act
+		err := CheckIfMatch(ctx, order)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should accept any version with a wildcard", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.PATCH, "/", nil)
+		req.Header.Set(HeaderIfMatch, "*")
+
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+		// Act
+		err := CheckIfMatch(ctx, order)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject the request with a stale version", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.PATCH, "/", nil)
+		req.Header.Set(HeaderIfMatch, `"2"`)
+
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+		// Act
+		err := CheckIfMatch(ctx, order)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, he.Code)
+	})
+}
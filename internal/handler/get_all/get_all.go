@@ -0,0 +1,69 @@
+package get_all
+
+import (
+	"net/http"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_all"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service service.GetOrdersService[get_all.GetOrdersDto]
+}
+
+func NewHandler(service service.GetOrdersService[get_all.GetOrdersDto]) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	var request get_all.GetOrdersDto
+
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, &request); err != nil {
+		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+	}
+
+	request.SetDefaults()
+
+	context := ctx.Request().Context()
+
+	principal, ok := auth.FromContext(context)
+	if !ok {
+		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrForbidden)
+	}
+
+	// customers only ever list their own orders, whatever filter they asked for
+	if !principal.IsStaff() {
+		request.CustomerID = principal.UserId
+	}
+
+	count, orders, err := h.service.Handle(context, request)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	for i := range orders {
+		orders[i].RefreshStateTitle()
+	}
+
+	totalItems := int64(count)
+
+	response := common.NewPaginationResponse[order_entity.Order](
+		request.Page,
+		request.TotalPages(totalItems),
+		totalItems,
+		orders,
+	)
+
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,235 @@
+package get_all
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_all"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return the orders paginated", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrdersService[get_all.GetOrdersDto](t)
+
+		expectedRequest := get_all.GetOrdersDto{
+			State: int(order_entity.Created),
+			Pagination: common.Pagination{
+				Page: 2,
+				Size: 10,
+			},
+		}
+
+		service.On("Handle", mock.Anything, expectedRequest).
+			Return(21, []order_entity.Order{{State: order_entity.Created}}, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/?page=2&size=10&state=1", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)})))
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var body common.PaginationResponse[order_entity.Order]
+		err = json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(2), body.Page)
+		assert.Equal(t, int64(3), body.TotalPages)
+		assert.Equal(t, int64(21), body.TotalItems)
+		assert.Len(t, body.Data, 1)
+		assert.Equal(t, "Created", body.Data[0].StateTitle)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return bad request when query params are invalid", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrdersService[get_all.GetOrdersDto](t)
+
+		req := httptest.NewRequest(echo.GET, "/?page=abc", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrdersService[get_all.GetOrdersDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(0, nil, custom_error.ErrRequestNotValid).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/?state=10", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)})))
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "validation error",
+			Details: "request not valid, please check the fields",
+		}, he.Message)
+
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal server error when an unexpected error occurs", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrdersService[get_all.GetOrdersDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(0, nil, assert.AnError).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)})))
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+			Details: "assert.AnError general error for testing",
+		}, he.Message)
+
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should only return the orders of the customer when the caller is not staff", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrdersService[get_all.GetOrdersDto](t)
+
+		customerId := uuid.NewString()
+
+		expectedRequest := get_all.GetOrdersDto{
+			CustomerID: customerId,
+			Pagination: common.Pagination{
+				Page: 1,
+				Size: 10,
+			},
+		}
+
+		service.On("Handle", mock.Anything, expectedRequest).
+			Return(0, []order_entity.Order{}, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/?customer_id="+uuid.NewString(), nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), auth.NewPrincipal(customerId, nil)))
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return forbidden when there is no principal", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrdersService[get_all.GetOrdersDto](t)
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusForbidden, he.Code)
+		service.AssertExpectations(t)
+	})
+}
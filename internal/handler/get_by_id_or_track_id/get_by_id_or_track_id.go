@@ -3,6 +3,7 @@ package get_by_id
 import (
 	"net/http"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
@@ -26,7 +27,11 @@ func (h *Handler) Handle(ctx echo.Context) error {
 		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
 	}
 
-	request.CustomerId = ctx.Get("userId").(string)
+	// the customer route looks up the latest order of the caller, the other ones
+	// must not be shadowed by it
+	if request.OrderId == "" && request.TrackId == "" {
+		request.CustomerId = ctx.Get("userId").(string)
+	}
 
 	context := ctx.Request().Context()
 
@@ -42,5 +47,7 @@ func (h *Handler) Handle(ctx echo.Context) error {
 	order.RefreshStateTitle()
 	order.CalculateTotals()
 
+	handler.SetETag(ctx, order)
+
 	return ctx.JSON(http.StatusOK, order)
 }
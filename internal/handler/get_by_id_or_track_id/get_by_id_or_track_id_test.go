@@ -133,7 +133,7 @@ func TestHandleGetByTrackId(t *testing.T) {
 		// Arrange
 		service := mocks.NewMockGetOrderService[get.GetOrderDto](t)
 
-		service.On("Handle", mock.Anything, mock.Anything).
+		service.On("Handle", mock.Anything, get.GetOrderDto{TrackId: "ABC-123"}).
 			Return(order_entity.Order{}, nil).
 			Once()
 
@@ -0,0 +1,41 @@
+package get_history
+
+import (
+	"net/http"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_history"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service service.GetOrderHistoryService[get_history.GetOrderHistoryDto]
+}
+
+func NewHandler(service service.GetOrderHistoryService[get_history.GetOrderHistoryDto]) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	var request get_history.GetOrderHistoryDto
+
+	if err := ctx.Bind(&request); err != nil {
+		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+	}
+
+	context := ctx.Request().Context()
+
+	history, err := h.service.Handle(context, request)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,135 @@
+package get_history
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_history"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return the order state history", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrderHistoryService[get_history.GetOrderHistoryDto](t)
+
+		orderId := uuid.NewString()
+
+		service.On("Handle", mock.Anything, get_history.GetOrderHistoryDto{OrderId: orderId}).
+			Return([]order_entity.StateTransition{
+				order_entity.NewStateTransition(orderId, order_entity.None, order_entity.Created, time.Now()),
+			}, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/orders/:id/history")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(orderId)
+		ctx.Set("userId", uuid.NewString())
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"to_state_title":"Created"`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrderHistoryService[get_history.GetOrderHistoryDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(nil, custom_error.ErrOrderNotFound).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/orders/:id/history")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(uuid.NewString())
+		ctx.Set("userId", uuid.NewString())
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusNotFound,
+			Message: "unable to find the order",
+			Details: "order not found",
+		}, he.Message)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal server error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrderHistoryService[get_history.GetOrderHistoryDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(nil, assert.AnError).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/orders/:id/history")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(uuid.NewString())
+		ctx.Set("userId", uuid.NewString())
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+			Details: "assert.AnError general error for testing",
+		}, he.Message)
+		service.AssertExpectations(t)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/payment/send_to_pay"
@@ -52,6 +53,10 @@ func (h *Handler) Handle(ctx echo.Context) error {
 		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
 	}
 
+	if err := handler.CheckIfMatch(ctx, order); err != nil {
+		return err
+	}
+
 	if !order.HasItems() {
 		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderHasNoItems)
 	}
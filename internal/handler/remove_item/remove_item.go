@@ -0,0 +1,80 @@
+package remove_item
+
+import (
+	"net/http"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/remove_item"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	getService        service.GetOrderService[get.GetOrderDto]
+	removeItemService service.UpdateOrderService[remove_item.RemoveOrderItemDto]
+}
+
+func NewHandler(
+	getService service.GetOrderService[get.GetOrderDto],
+	removeItemService service.UpdateOrderService[remove_item.RemoveOrderItemDto],
+) *Handler {
+	return &Handler{
+		getService:        getService,
+		removeItemService: removeItemService,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	var request remove_item.RemoveOrderItemDto
+
+	if err := ctx.Bind(&request); err != nil {
+		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+	}
+
+	context := ctx.Request().Context()
+
+	getOrderRequest := get.GetOrderDto{
+		OrderId: request.OrderId,
+	}
+
+	order, err := h.getService.Handle(context, getOrderRequest)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	if err := handler.CheckIfMatch(ctx, order); err != nil {
+		return err
+	}
+
+	if order.IsCompleted() {
+		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderAlreadyCompleted)
+	}
+
+	if !order.CanAddItems() {
+		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderInProgress)
+	}
+
+	if order.HasOnGoingPayments() {
+		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderHasOnGoingPayments)
+	}
+
+	if err := h.removeItemService.Handle(context, &order, request); err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	order.RefreshStateTitle()
+
+	handler.SetETag(ctx, order)
+
+	return ctx.JSON(http.StatusOK, order)
+}
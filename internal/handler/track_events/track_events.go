@@ -0,0 +1,112 @@
+package track_events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service    service.GetOrderService[get.GetOrderDto]
+	subscriber tracking.Subscriber
+	heartbeat  time.Duration
+}
+
+func NewHandler(
+	service service.GetOrderService[get.GetOrderDto],
+	subscriber tracking.Subscriber,
+	heartbeat time.Duration,
+) *Handler {
+	return &Handler{
+		service:    service,
+		subscriber: subscriber,
+		heartbeat:  heartbeat,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	var request get.GetOrderDto
+
+	if err := ctx.Bind(&request); err != nil {
+		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+	}
+
+	// subscribing before loading the order makes sure no change made in between
+	// is missed, at worst the first event repeats the snapshot
+	events, unsubscribe := h.subscriber.Subscribe(request.TrackId)
+	defer unsubscribe()
+
+	context := ctx.Request().Context()
+
+	order, err := h.service.Handle(context, request)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	// the stream outlives the write timeout of the server
+	if err := http.NewResponseController(ctx.Response().Writer).SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(context, "error clearing the write deadline of the stream", "error", err)
+	}
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	response.Header().Set(echo.HeaderConnection, "keep-alive")
+	response.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(response, tracking.NewStateChangedEvent(order)); err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-context.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := writeEvent(response, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			// a comment line keeps proxies from closing an idle stream
+			if _, err := fmt.Fprint(response, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+
+			response.Flush()
+		}
+	}
+}
+
+func writeEvent(response *echo.Response, event tracking.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(response, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+
+	response.Flush()
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package track_events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+	tracking_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking/mocks"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should stream the current state and the following events", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		subscriber := tracking_mocks.NewMockSubscriber(t)
+
+		orderId := uuid.NewString()
+
+		updates := make(chan tracking.Event, 1)
+		updates <- tracking.Event{
+			Type:       tracking.EventStateChanged,
+			OrderId:    orderId,
+			TrackId:    "ABC-123",
+			State:      order_entity.Received,
+			StateTitle: order_entity.Received.String(),
+		}
+		close(updates)
+
+		var events <-chan tracking.Event = updates
+
+		unsubscribed := false
+
+		subscriber.On("Subscribe", "ABC-123").
+			Return(events, func() { unsubscribed = true }).
+			Once()
+
+		service.On("Handle", mock.Anything, get.GetOrderDto{TrackId: "ABC-123"}).
+			Return(order_entity.Order{
+				Id:      orderId,
+				TrackId: order_entity.NewTrackIdFrom("ABC-123"),
+				State:   order_entity.Created,
+			}, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/orders/tracking/:track_id/events")
+		ctx.SetParamNames("track_id")
+		ctx.SetParamValues("ABC-123")
+		ctx.Set("userId", uuid.NewString())
+
+		handler := NewHandler(service, subscriber, time.Minute)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "text/event-stream", resp.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, resp.Body.String(), `event: state_changed`+"\n"+`data: {"type":"state_changed","order_id":"`+orderId+`","track_id":"ABC-123","state":1,"state_title":"Created"`)
+		assert.Contains(t, resp.Body.String(), `"state":2,"state_title":"Received"`)
+		assert.True(t, unsubscribed)
+		service.AssertExpectations(t)
+		subscriber.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		subscriber := tracking_mocks.NewMockSubscriber(t)
+
+		var events <-chan tracking.Event = make(chan tracking.Event)
+
+		unsubscribed := false
+
+		subscriber.On("Subscribe", "ABC-123").
+			Return(events, func() { unsubscribed = true }).
+			Once()
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{}, custom_error.ErrOrderNotFound).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/orders/tracking/:track_id/events")
+		ctx.SetParamNames("track_id")
+		ctx.SetParamValues("ABC-123")
+		ctx.Set("userId", uuid.NewString())
+
+		handler := NewHandler(service, subscriber, time.Minute)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusNotFound,
+			Message: "unable to find the order",
+			Details: "order not found",
+		}, he.Message)
+		assert.True(t, unsubscribed)
+		service.AssertExpectations(t)
+		subscriber.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal server error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		subscriber := tracking_mocks.NewMockSubscriber(t)
+
+		var events <-chan tracking.Event = make(chan tracking.Event)
+
+		subscriber.On("Subscribe", "ABC-123").
+			Return(events, func() {}).
+			Once()
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{}, assert.AnError).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/orders/tracking/:track_id/events")
+		ctx.SetParamNames("track_id")
+		ctx.SetParamValues("ABC-123")
+		ctx.Set("userId", uuid.NewString())
+
+		handler := NewHandler(service, subscriber, time.Minute)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+			Details: "assert.AnError general error for testing",
+		}, he.Message)
+		service.AssertExpectations(t)
+		subscriber.AssertExpectations(t)
+	})
+}
@@ -3,6 +3,7 @@ package update
 import (
 	"net/http"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update"
@@ -45,6 +46,10 @@ func (h *Handler) Handle(ctx echo.Context) error {
 		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
 	}
 
+	if err := handler.CheckIfMatch(ctx, order); err != nil {
+		return err
+	}
+
 	if err := h.updateService.Handle(context, &order, request); err != nil {
 		if custom_error.IsBusinessErr(err) {
 			return custom_error.NewHttpAppErrorFromBusinessError(err)
@@ -53,5 +58,7 @@ func (h *Handler) Handle(ctx echo.Context) error {
 		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
 	}
 
+	handler.SetETag(ctx, order)
+
 	return ctx.JSON(http.StatusCreated, order)
 }
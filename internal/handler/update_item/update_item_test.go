@@ -0,0 +1,433 @@
+package update_item
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update_item"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should update the item quantity", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State: order_entity.Created,
+			}, nil).
+			Once()
+
+		updateItemService.On("Handle", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when order is not found", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{}, custom_error.ErrOrderNotFound).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusNotFound,
+			Message: "unable to find the order",
+			Details: "order not found",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal error when try to find the order", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{}, assert.AnError).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+			Details: "assert.AnError general error for testing",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when order is already completed", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State: order_entity.Delivered,
+			}, nil).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusBadRequest,
+			Message: "unable to update/insert information to the order",
+			Details: "order is already completed or cancelled",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when order is in progress", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State: order_entity.Processing,
+			}, nil).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusBadRequest,
+			Message: "unable to update/insert information to the order",
+			Details: "order is in progress",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when order has on going payments", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State: order_entity.Created,
+				Payments: []payment_entity.Payment{
+					{
+						State: payment_entity.WaitingForApproval,
+					},
+				},
+			}, nil).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusBadRequest,
+			Message: "operation not allowed",
+			Details: "order has on going payments or is already paid",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error when the item does not exist", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State: order_entity.Created,
+			}, nil).
+			Once()
+
+		updateItemService.On("Handle", mock.Anything, mock.Anything, mock.Anything).
+			Return(custom_error.ErrOrderItemNotFound).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusNotFound,
+			Message: "unable to find the order item",
+			Details: "order item not found",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal error when try to update the order", func(t *testing.T) {
+		// Arrange
+		getService := mocks.NewMockGetOrderService[get.GetOrderDto](t)
+		updateItemService := mocks.NewMockUpdateOrderService[update_item.UpdateOrderItemDto](t)
+
+		getService.On("Handle", mock.Anything, mock.Anything).
+			Return(order_entity.Order{
+				State: order_entity.Created,
+			}, nil).
+			Once()
+
+		updateItemService.On("Handle", mock.Anything, mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		reqBody := update_item.UpdateOrderItemDto{
+			Quantity: 2,
+		}
+
+		body, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/orders/:id/items/:item_id")
+		ctx.SetParamNames("id", "item_id")
+		ctx.SetParamValues(uuid.NewString(), uuid.NewString())
+
+		handler := NewHandler(getService, updateItemService)
+
+		// Act
+		err = handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: "internal server error",
+			Details: "assert.AnError general error for testing",
+		}, he.Message)
+
+		getService.AssertExpectations(t)
+		updateItemService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,41 @@
+package create
+
+import (
+	"net/http"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/create"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service service.CreateWebhookService[create.CreateWebhookDto]
+}
+
+func NewHandler(service service.CreateWebhookService[create.CreateWebhookDto]) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	var request create.CreateWebhookDto
+
+	if err := ctx.Bind(&request); err != nil {
+		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+	}
+
+	context := ctx.Request().Context()
+
+	subscription, err := h.service.Handle(context, request)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	return ctx.JSON(http.StatusCreated, subscription)
+}
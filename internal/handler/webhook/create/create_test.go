@@ -0,0 +1,118 @@
+package create
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/create"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should create a subscription without exposing its secret", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockCreateWebhookService[create.CreateWebhookDto](t)
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		service.On("Handle", mock.Anything, create.CreateWebhookDto{
+			Url:        "https://partner.com/hook",
+			Secret:     "0123456789abcdef",
+			EventTypes: []string{"order.state_changed"},
+		}).
+			Return(&subscription, nil).
+			Once()
+
+		body := `{"url":"https://partner.com/hook","secret":"0123456789abcdef","event_types":["order.state_changed"]}`
+
+		req := httptest.NewRequest(echo.POST, "/", bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Contains(t, resp.Body.String(), subscription.Id)
+		assert.NotContains(t, resp.Body.String(), "0123456789abcdef")
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockCreateWebhookService[create.CreateWebhookDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(nil, custom_error.ErrRequestNotValid).
+			Once()
+
+		req := httptest.NewRequest(echo.POST, "/", bytes.NewBufferString(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal server error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockCreateWebhookService[create.CreateWebhookDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(nil, assert.AnError).
+			Once()
+
+		req := httptest.NewRequest(echo.POST, "/", bytes.NewBufferString(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		service.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,34 @@
+package get_all
+
+import (
+	"net/http"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service service.GetWebhooksService
+}
+
+func NewHandler(service service.GetWebhooksService) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	context := ctx.Request().Context()
+
+	subscriptions, err := h.service.Handle(context)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	return ctx.JSON(http.StatusOK, subscriptions)
+}
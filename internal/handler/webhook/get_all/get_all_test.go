@@ -0,0 +1,75 @@
+package get_all
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return the subscriptions", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetWebhooksService(t)
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		service.On("Handle", mock.Anything).
+			Return([]webhook_entity.Subscription{subscription}, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"url":"https://partner.com/hook"`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal server error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetWebhooksService(t)
+
+		service.On("Handle", mock.Anything).
+			Return(nil, assert.AnError).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusInternalServerError, he.Code)
+		service.AssertExpectations(t)
+	})
+}
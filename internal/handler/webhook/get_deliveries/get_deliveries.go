@@ -0,0 +1,58 @@
+package get_deliveries
+
+import (
+	"net/http"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/get_deliveries"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service service.GetWebhookDeliveriesService[get_deliveries.GetWebhookDeliveriesDto]
+}
+
+func NewHandler(service service.GetWebhookDeliveriesService[get_deliveries.GetWebhookDeliveriesDto]) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Handle(ctx echo.Context) error {
+	var request get_deliveries.GetWebhookDeliveriesDto
+
+	if err := ctx.Bind(&request); err != nil {
+		return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+	}
+
+	request.SetDefaults()
+
+	context := ctx.Request().Context()
+
+	count, attempts, err := h.service.Handle(context, request)
+	if err != nil {
+		if custom_error.IsBusinessErr(err) {
+			return custom_error.NewHttpAppErrorFromBusinessError(err)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	for i := range attempts {
+		attempts[i].RefreshStateTitle()
+	}
+
+	totalItems := int64(count)
+
+	response := common.NewPaginationResponse[webhook_entity.Attempt](
+		request.Page,
+		request.TotalPages(totalItems),
+		totalItems,
+		attempts,
+	)
+
+	return ctx.JSON(http.StatusOK, response)
+}
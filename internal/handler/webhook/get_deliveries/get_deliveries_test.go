@@ -0,0 +1,93 @@
+package get_deliveries
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/get_deliveries"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return a page of the delivery log", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetWebhookDeliveriesService[get_deliveries.GetWebhookDeliveriesDto](t)
+
+		id := uuid.NewString()
+
+		attempts := []webhook_entity.Attempt{
+			{Id: uuid.NewString(), SubscriptionId: id, State: webhook_entity.Delivered, StatusCode: 200, AttemptedAt: time.Now()},
+		}
+
+		service.On("Handle", mock.Anything, get_deliveries.GetWebhookDeliveriesDto{
+			Id:         id,
+			Pagination: common.Pagination{Page: 2, Size: 10},
+		}).
+			Return(11, attempts, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/?page=2", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/webhooks/:id/deliveries")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"page":2,"total_pages":2,"total_items":11`)
+		assert.Contains(t, resp.Body.String(), `"state_title":"Delivered"`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockGetWebhookDeliveriesService[get_deliveries.GetWebhookDeliveriesDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(0, nil, custom_error.ErrWebhookNotFound).
+			Once()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/webhooks/:id/deliveries")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(uuid.NewString())
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		service.AssertExpectations(t)
+	})
+}
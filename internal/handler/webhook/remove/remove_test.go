@@ -0,0 +1,81 @@
+package remove
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/remove"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should delete the subscription", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockDeleteWebhookService[remove.DeleteWebhookDto](t)
+
+		id := uuid.NewString()
+
+		service.On("Handle", mock.Anything, remove.DeleteWebhookDto{Id: id}).
+			Return(nil).
+			Once()
+
+		req := httptest.NewRequest(echo.DELETE, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/webhooks/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockDeleteWebhookService[remove.DeleteWebhookDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(custom_error.ErrWebhookNotFound).
+			Once()
+
+		req := httptest.NewRequest(echo.DELETE, "/", nil)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/webhooks/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(uuid.NewString())
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		service.AssertExpectations(t)
+	})
+}
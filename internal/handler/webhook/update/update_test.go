@@ -0,0 +1,93 @@
+package update
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/update"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should update the subscription", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockUpdateWebhookService[update.UpdateWebhookDto](t)
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		service.On("Handle", mock.Anything, mock.MatchedBy(func(request update.UpdateWebhookDto) bool {
+			return request.Id == subscription.Id && request.Active != nil && *request.Active
+		})).
+			Return(subscription, nil).
+			Once()
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBufferString(`{"active":true}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/webhooks/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(subscription.Id)
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Should return business error", func(t *testing.T) {
+		// Arrange
+		service := mocks.NewMockUpdateWebhookService[update.UpdateWebhookDto](t)
+
+		service.On("Handle", mock.Anything, mock.Anything).
+			Return(webhook_entity.Subscription{}, custom_error.ErrWebhookNotFound).
+			Once()
+
+		req := httptest.NewRequest(echo.PATCH, "/", bytes.NewBufferString(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, resp)
+		ctx.SetPath("/api/v1/webhooks/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(uuid.NewString())
+
+		handler := NewHandler(service)
+
+		// Act
+		err := handler.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+
+		he, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+
+		assert.Equal(t, http.StatusNotFound, he.Code)
+		assert.Equal(t, custom_error.AppError{
+			Code:    http.StatusNotFound,
+			Message: "unable to find the webhook",
+			Details: "webhook not found",
+		}, he.Message)
+		service.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,150 @@
+package idempotency_repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/idempotency_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type IdempotencyRepository struct {
+	conn *sql.DB
+}
+
+func NewIdempotencyRepository(conn *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		conn: conn,
+	}
+}
+
+// Reserve stores the key unless a live one already exists for the user, and
+// reports whether the key was reserved. An expired key is taken over, as is a
+// key of the same request left in progress past its lock, e.g. by a crash
+func (r *IdempotencyRepository) Reserve(ctx context.Context, key *idempotency_entity.Key) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, fingerprint, state, status_code, content_type, etag, response, created_at, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, 0, '', '', NULL, $5, $6, $7)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			state = EXCLUDED.state,
+			status_code = 0,
+			content_type = '',
+			etag = '',
+			response = NULL,
+			created_at = EXCLUDED.created_at,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.state = $8
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint
+				AND idempotency_keys.locked_until <= EXCLUDED.created_at);
+	`
+
+	res, err := r.conn.ExecContext(ctx,
+		query,
+		key.UserId,
+		key.Key,
+		key.Fingerprint,
+		key.State,
+		key.CreatedAt,
+		key.LockedUntil,
+		key.ExpiresAt,
+		idempotency_entity.InProgress)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, userId string, key string) (idempotency_entity.Key, error) {
+	query := `
+		SELECT user_id, key, fingerprint, state, status_code, content_type, etag, response, created_at, locked_until, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2;
+	`
+
+	result := idempotency_entity.Key{}
+
+	err := r.conn.QueryRowContext(ctx, query, userId, key).Scan(
+		&result.UserId,
+		&result.Key,
+		&result.Fingerprint,
+		&result.State,
+		&result.StatusCode,
+		&result.ContentType,
+		&result.ETag,
+		&result.Response,
+		&result.CreatedAt,
+		&result.LockedUntil,
+		&result.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return idempotency_entity.Key{}, custom_error.ErrIdempotencyKeyNotFound
+		}
+
+		return idempotency_entity.Key{}, err
+	}
+
+	return result, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, key *idempotency_entity.Key) error {
+	query := `
+		UPDATE idempotency_keys
+		SET state = $1, status_code = $2, content_type = $3, etag = $4, response = $5
+		WHERE user_id = $6 AND key = $7;
+	`
+
+	_, err := r.conn.ExecContext(ctx,
+		query,
+		key.State,
+		key.StatusCode,
+		key.ContentType,
+		key.ETag,
+		key.Response,
+		key.UserId,
+		key.Key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Release removes a key still in progress, so the request can be retried
+func (r *IdempotencyRepository) Release(ctx context.Context, userId string, key string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND state = $3;
+	`
+
+	_, err := r.conn.ExecContext(ctx, query, userId, key, idempotency_entity.InProgress)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE expires_at < $1;
+	`
+
+	res, err := r.conn.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
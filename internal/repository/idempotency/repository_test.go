@@ -0,0 +1,297 @@
+package idempotency_repository
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/idempotency_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserve(t *testing.T) {
+	t.Run("Should reserve the key", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		key := idempotency_entity.NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WithArgs(key.UserId, key.Key, key.Fingerprint, key.State, key.CreatedAt, key.LockedUntil, key.ExpiresAt, idempotency_entity.InProgress).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		reserved, err := repo.Reserve(ctx, &key)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should take over a key of the same request left in progress past its lock", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		key := idempotency_entity.NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+
+		mock.ExpectExec("INSERT INTO idempotency_keys (.+) ON CONFLICT (.+) idempotency_keys.fingerprint = EXCLUDED.fingerprint AND idempotency_keys.locked_until <= EXCLUDED.created_at").
+			WithArgs(key.UserId, key.Key, key.Fingerprint, key.State, key.CreatedAt, key.LockedUntil, key.ExpiresAt, idempotency_entity.InProgress).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		reserved, err := repo.Reserve(ctx, &key)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should not reserve the key when it is already taken", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		key := idempotency_entity.NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		reserved, err := repo.Reserve(ctx, &key)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		key := idempotency_entity.NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WillReturnError(assert.AnError)
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		reserved, err := repo.Reserve(ctx, &key)
+
+		// Assert
+		assert.Error(t, err)
+		assert.False(t, reserved)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("Should return the key", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+			WithArgs("user_id", "key").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "key", "fingerprint", "state", "status_code", "content_type", "etag", "response", "created_at", "locked_until", "expires_at"}).
+				AddRow("user_id", "key", "fingerprint", idempotency_entity.Completed, http.StatusCreated, "application/json", `"1"`, []byte(`{}`), now, now.Add(time.Minute), now.Add(time.Hour)))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		key, err := repo.Get(ctx, "user_id", "key")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, idempotency_entity.Key{
+			Key:         "key",
+			UserId:      "user_id",
+			Fingerprint: "fingerprint",
+			State:       idempotency_entity.Completed,
+			StatusCode:  http.StatusCreated,
+			ContentType: "application/json",
+			ETag:        `"1"`,
+			Response:    []byte(`{}`),
+			CreatedAt:   now,
+			LockedUntil: now.Add(time.Minute),
+			ExpiresAt:   now.Add(time.Hour),
+		}, key)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the key is not found", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		_, err = repo.Get(ctx, "user_id", "key")
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrIdempotencyKeyNotFound)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the query fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+			WillReturnError(assert.AnError)
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		_, err = repo.Get(ctx, "user_id", "key")
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestComplete(t *testing.T) {
+	t.Run("Should store the response of the key", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		key := idempotency_entity.NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+		key.Complete(http.StatusCreated, "application/json", `"1"`, []byte(`{}`))
+
+		mock.ExpectExec("UPDATE idempotency_keys").
+			WithArgs(idempotency_entity.Completed, http.StatusCreated, "application/json", `"1"`, []byte(`{}`), "user_id", "key").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		err = repo.Complete(ctx, &key)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the update fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		key := idempotency_entity.NewKey("user_id", "key", "fingerprint", time.Now(), time.Hour, time.Minute)
+
+		mock.ExpectExec("UPDATE idempotency_keys").
+			WillReturnError(assert.AnError)
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		err = repo.Complete(ctx, &key)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRelease(t *testing.T) {
+	t.Run("Should delete the key in progress", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("DELETE FROM idempotency_keys").
+			WithArgs("user_id", "key", idempotency_entity.InProgress).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		err = repo.Release(ctx, "user_id", "key")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteExpiredBefore(t *testing.T) {
+	t.Run("Should delete the keys expired before the date", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		before := time.Now()
+
+		mock.ExpectExec("DELETE FROM idempotency_keys").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		repo := NewIdempotencyRepository(db)
+
+		// Act
+		deleted, err := repo.DeleteExpiredBefore(ctx, before)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,84 @@
+package inbox_repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/inbox"
+)
+
+type InboxRepository struct {
+	conn *sql.DB
+}
+
+func NewInboxRepository(conn *sql.DB) *InboxRepository {
+	return &InboxRepository{
+		conn: conn,
+	}
+}
+
+func (r *InboxRepository) Exists(ctx context.Context, messageId string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM inbox WHERE message_id = $1);
+	`
+
+	var exists bool
+
+	if err := r.conn.QueryRowContext(ctx, query, messageId).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *InboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM inbox
+		WHERE processed_at < $1;
+	`
+
+	res, err := r.conn.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// RecordMessage stores the inbound message carried by the context inside the
+// caller transaction at the given time, failing when another consumer already
+// processed it
+func RecordMessage(ctx context.Context, conn execer, processedAt time.Time) error {
+	messageId, ok := inbox.Claim(ctx)
+	if !ok {
+		return nil
+	}
+
+	query := `
+		INSERT INTO inbox (message_id, processed_at)
+		VALUES ($1, $2)
+		ON CONFLICT (message_id) DO NOTHING;
+	`
+
+	res, err := conn.ExecContext(ctx, query, messageId, processedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return custom_error.ErrQueueMessageAlreadyProcessed
+	}
+
+	return nil
+}
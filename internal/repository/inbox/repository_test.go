@@ -0,0 +1,187 @@
+package inbox_repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/inbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExists(t *testing.T) {
+	t.Run("Should return true when the message was processed", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs("message_id").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		repo := NewInboxRepository(db)
+
+		// Act
+		exists, err := repo.Exists(ctx, "message_id")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the query fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT EXISTS").
+			WillReturnError(assert.AnError)
+
+		repo := NewInboxRepository(db)
+
+		// Act
+		exists, err := repo.Exists(ctx, "message_id")
+
+		// Assert
+		assert.Error(t, err)
+		assert.False(t, exists)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteProcessedBefore(t *testing.T) {
+	t.Run("Should delete the messages processed before the date", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		before := time.Now()
+
+		mock.ExpectExec("DELETE FROM inbox").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		repo := NewInboxRepository(db)
+
+		// Act
+		deleted, err := repo.DeleteProcessedBefore(ctx, before)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the delete fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("DELETE FROM inbox").
+			WillReturnError(assert.AnError)
+
+		repo := NewInboxRepository(db)
+
+		// Act
+		_, err = repo.DeleteProcessedBefore(ctx, time.Now())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecordMessage(t *testing.T) {
+	t.Run("Should do nothing when the context has no message", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		// Act
+		err = RecordMessage(ctx, db, time.Now())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should record the message only once", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := inbox.WithMessage(context.Background(), "message_id")
+
+		now := time.Now()
+
+		mock.ExpectExec("INSERT INTO inbox").
+			WithArgs("message_id", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Act
+		err = RecordMessage(ctx, db, now)
+		errAgain := RecordMessage(ctx, db, now)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, errAgain)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the message was already processed", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := inbox.WithMessage(context.Background(), "message_id")
+
+		mock.ExpectExec("INSERT INTO inbox").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err = RecordMessage(ctx, db, time.Now())
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageAlreadyProcessed)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := inbox.WithMessage(context.Background(), "message_id")
+
+		mock.ExpectExec("INSERT INTO inbox").
+			WillReturnError(assert.AnError)
+
+		// Act
+		err = RecordMessage(ctx, db, time.Now())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
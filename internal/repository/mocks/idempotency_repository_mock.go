@@ -0,0 +1,151 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	idempotency_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/idempotency_entity"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockIdempotencyRepository is an autogenerated mock type for the IdempotencyRepository type
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+// Complete provides a mock function with given fields: ctx, key
+func (_m *MockIdempotencyRepository) Complete(ctx context.Context, key *idempotency_entity.Key) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *idempotency_entity.Key) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredBefore provides a mock function with given fields: ctx, before
+func (_m *MockIdempotencyRepository) DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: ctx, userId, key
+func (_m *MockIdempotencyRepository) Get(ctx context.Context, userId string, key string) (idempotency_entity.Key, error) {
+	ret := _m.Called(ctx, userId, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 idempotency_entity.Key
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (idempotency_entity.Key, error)); ok {
+		return rf(ctx, userId, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) idempotency_entity.Key); ok {
+		r0 = rf(ctx, userId, key)
+	} else {
+		r0 = ret.Get(0).(idempotency_entity.Key)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userId, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, userId, key
+func (_m *MockIdempotencyRepository) Release(ctx context.Context, userId string, key string) error {
+	ret := _m.Called(ctx, userId, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userId, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, key
+func (_m *MockIdempotencyRepository) Reserve(ctx context.Context, key *idempotency_entity.Key) (bool, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserve")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *idempotency_entity.Key) (bool, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *idempotency_entity.Key) bool); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *idempotency_entity.Key) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockIdempotencyRepository creates a new instance of MockIdempotencyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockIdempotencyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
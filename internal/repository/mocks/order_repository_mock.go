@@ -11,6 +11,8 @@ import (
 
 	order_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 
+	payment_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+
 	repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository"
 )
 
@@ -158,6 +160,36 @@ func (_m *MockOrderRepository) GetByTrackID(ctx context.Context, trackId string)
 	return r0, r1
 }
 
+// GetStateHistory provides a mock function with given fields: ctx, orderId
+func (_m *MockOrderRepository) GetStateHistory(ctx context.Context, orderId string) ([]order_entity.StateTransition, error) {
+	ret := _m.Called(ctx, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStateHistory")
+	}
+
+	var r0 []order_entity.StateTransition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]order_entity.StateTransition, error)); ok {
+		return rf(ctx, orderId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []order_entity.StateTransition); ok {
+		r0 = rf(ctx, orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]order_entity.StateTransition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, order, updateItems
 func (_m *MockOrderRepository) Update(ctx context.Context, order *order_entity.Order, updateItems bool) error {
 	ret := _m.Called(ctx, order, updateItems)
@@ -176,6 +208,24 @@ func (_m *MockOrderRepository) Update(ctx context.Context, order *order_entity.O
 	return r0
 }
 
+// UpdateWithPayment provides a mock function with given fields: ctx, order, payment
+func (_m *MockOrderRepository) UpdateWithPayment(ctx context.Context, order *order_entity.Order, payment *payment_entity.Payment) error {
+	ret := _m.Called(ctx, order, payment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWithPayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *order_entity.Order, *payment_entity.Payment) error); ok {
+		r0 = rf(ctx, order, payment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewMockOrderRepository creates a new instance of MockOrderRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockOrderRepository(t interface {
@@ -0,0 +1,97 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	outbox_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockOutboxRepository is an autogenerated mock type for the OutboxRepository type
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+// ClaimPending provides a mock function with given fields: ctx, limit, now, leaseUntil
+func (_m *MockOutboxRepository) ClaimPending(ctx context.Context, limit int, now time.Time, leaseUntil time.Time) ([]outbox_entity.Message, error) {
+	ret := _m.Called(ctx, limit, now, leaseUntil)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimPending")
+	}
+
+	var r0 []outbox_entity.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time) ([]outbox_entity.Message, error)); ok {
+		return rf(ctx, limit, now, leaseUntil)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time) []outbox_entity.Message); ok {
+		r0 = rf(ctx, limit, now, leaseUntil)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]outbox_entity.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, limit, now, leaseUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, message
+func (_m *MockOutboxRepository) Create(ctx context.Context, message *outbox_entity.Message) error {
+	ret := _m.Called(ctx, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *outbox_entity.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, message
+func (_m *MockOutboxRepository) Update(ctx context.Context, message *outbox_entity.Message) error {
+	ret := _m.Called(ctx, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *outbox_entity.Message) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMockOutboxRepository creates a new instance of MockOutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOutboxRepository {
+	mock := &MockOutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
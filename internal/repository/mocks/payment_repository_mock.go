@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 
+	outbox_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
 	payment_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -32,6 +33,24 @@ func (_m *MockPaymentRepository) Create(ctx context.Context, payment *payment_en
 	return r0
 }
 
+// CreateWithMessage provides a mock function with given fields: ctx, payment, message, orderVersion
+func (_m *MockPaymentRepository) CreateWithMessage(ctx context.Context, payment *payment_entity.Payment, message *outbox_entity.Message, orderVersion int) error {
+	ret := _m.Called(ctx, payment, message, orderVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWithMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *payment_entity.Payment, *outbox_entity.Message, int) error); ok {
+		r0 = rf(ctx, payment, message, orderVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, payment
 func (_m *MockPaymentRepository) Update(ctx context.Context, payment *payment_entity.Payment) error {
 	ret := _m.Called(ctx, payment)
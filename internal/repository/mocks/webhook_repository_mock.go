@@ -0,0 +1,231 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	common "github.com/jfelipearaujo-org/ms-order-management/internal/common"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	webhook_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+)
+
+// MockWebhookRepository is an autogenerated mock type for the WebhookRepository type
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+// ClaimPendingDeliveries provides a mock function with given fields: ctx, limit, now, leaseUntil
+func (_m *MockWebhookRepository) ClaimPendingDeliveries(ctx context.Context, limit int, now time.Time, leaseUntil time.Time) ([]webhook_entity.Delivery, error) {
+	ret := _m.Called(ctx, limit, now, leaseUntil)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimPendingDeliveries")
+	}
+
+	var r0 []webhook_entity.Delivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time) ([]webhook_entity.Delivery, error)); ok {
+		return rf(ctx, limit, now, leaseUntil)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time) []webhook_entity.Delivery); ok {
+		r0 = rf(ctx, limit, now, leaseUntil)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]webhook_entity.Delivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, limit, now, leaseUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSubscription provides a mock function with given fields: ctx, subscription
+func (_m *MockWebhookRepository) CreateSubscription(ctx context.Context, subscription *webhook_entity.Subscription) error {
+	ret := _m.Called(ctx, subscription)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook_entity.Subscription) error); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSubscription provides a mock function with given fields: ctx, id
+func (_m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllSubscriptions provides a mock function with given fields: ctx
+func (_m *MockWebhookRepository) GetAllSubscriptions(ctx context.Context) ([]webhook_entity.Subscription, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllSubscriptions")
+	}
+
+	var r0 []webhook_entity.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]webhook_entity.Subscription, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []webhook_entity.Subscription); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]webhook_entity.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAttempts provides a mock function with given fields: ctx, subscriptionId, pagination
+func (_m *MockWebhookRepository) GetAttempts(ctx context.Context, subscriptionId string, pagination common.Pagination) (int, []webhook_entity.Attempt, error) {
+	ret := _m.Called(ctx, subscriptionId, pagination)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttempts")
+	}
+
+	var r0 int
+	var r1 []webhook_entity.Attempt
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, common.Pagination) (int, []webhook_entity.Attempt, error)); ok {
+		return rf(ctx, subscriptionId, pagination)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, common.Pagination) int); ok {
+		r0 = rf(ctx, subscriptionId, pagination)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, common.Pagination) []webhook_entity.Attempt); ok {
+		r1 = rf(ctx, subscriptionId, pagination)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]webhook_entity.Attempt)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, common.Pagination) error); ok {
+		r2 = rf(ctx, subscriptionId, pagination)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSubscriptionByID provides a mock function with given fields: ctx, id
+func (_m *MockWebhookRepository) GetSubscriptionByID(ctx context.Context, id string) (webhook_entity.Subscription, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscriptionByID")
+	}
+
+	var r0 webhook_entity.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (webhook_entity.Subscription, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) webhook_entity.Subscription); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(webhook_entity.Subscription)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordAttempt provides a mock function with given fields: ctx, delivery, attempt, subscription, disableAfter
+func (_m *MockWebhookRepository) RecordAttempt(ctx context.Context, delivery *webhook_entity.Delivery, attempt webhook_entity.Attempt, subscription *webhook_entity.Subscription, disableAfter int) error {
+	ret := _m.Called(ctx, delivery, attempt, subscription, disableAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook_entity.Delivery, webhook_entity.Attempt, *webhook_entity.Subscription, int) error); ok {
+		r0 = rf(ctx, delivery, attempt, subscription, disableAfter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSubscription provides a mock function with given fields: ctx, subscription
+func (_m *MockWebhookRepository) UpdateSubscription(ctx context.Context, subscription *webhook_entity.Subscription) error {
+	ret := _m.Called(ctx, subscription)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook_entity.Subscription) error); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMockWebhookRepository creates a new instance of MockWebhookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockWebhookRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockWebhookRepository {
+	mock := &MockWebhookRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,32 +5,44 @@ import (
 	"database/sql"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	inbox_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/inbox"
+	outbox_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/outbox"
+	webhook_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/webhook"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/audit"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 )
 
 type OrderRepository struct {
-	conn *sql.DB
+	conn         *sql.DB
+	eventTopic   string
+	timeProvider provider.TimeProvider
 }
 
-func NewOrderRepository(conn *sql.DB) *OrderRepository {
+func NewOrderRepository(conn *sql.DB, eventTopic string, timeProvider provider.TimeProvider) *OrderRepository {
 	return &OrderRepository{
-		conn: conn,
+		conn:         conn,
+		eventTopic:   eventTopic,
+		timeProvider: timeProvider,
 	}
 }
 
 func (r *OrderRepository) Create(ctx context.Context, order *order_entity.Order) error {
 	queryInsertOrder := `
-		INSERT INTO orders (id, customer_id, track_id, state, state_updated_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7);
+		INSERT INTO orders (id, customer_id, track_id, state, state_updated_at, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 	`
 
 	queryInsertOrderItems := `
-		INSERT INTO order_items (order_id, product_id, name, quantity, price)
-		VALUES ($1, $2, $3, $4, $5);
+		INSERT INTO order_items (order_id, product_id, name, quantity, price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6);
 	`
 
 	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
@@ -46,7 +58,8 @@ func (r *OrderRepository) Create(ctx context.Context, order *order_entity.Order)
 		order.State,
 		order.StateUpdatedAt,
 		order.CreatedAt,
-		order.UpdatedAt)
+		order.UpdatedAt,
+		order.Version)
 	if err != nil {
 		errTx := tx.Rollback()
 		if errTx != nil {
@@ -62,7 +75,8 @@ func (r *OrderRepository) Create(ctx context.Context, order *order_entity.Order)
 			item.Id,
 			item.Name,
 			item.Quantity,
-			item.UnitPrice)
+			item.UnitPrice,
+			item.UnitPrice.Currency)
 		if err != nil {
 			errTx := tx.Rollback()
 			if errTx != nil {
@@ -72,7 +86,32 @@ func (r *OrderRepository) Create(ctx context.Context, order *order_entity.Order)
 		}
 	}
 
-	return tx.Commit()
+	transition := order_entity.NewStateTransition(order.Id, order_entity.None, order.State, order.CreatedAt)
+
+	if err := r.insertStateTransition(ctx, tx, transition); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := outbox_repository.InsertEvents(ctx, tx, r.eventTopic, order.Version, order.PendingEvents()); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	order.ClearPendingStateTransitions()
+	order.ClearPendingEvents()
+
+	return nil
 }
 
 func (r *OrderRepository) GetByID(ctx context.Context, id string) (order_entity.Order, error) {
@@ -92,7 +131,7 @@ func (r *OrderRepository) getBy(ctx context.Context, column string, value string
 
 	sql, params, err := goqu.
 		From("orders").
-		Select("id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at").
+		Select("id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version").
 		Where(goqu.Ex{column: value}).
 		ToSQL()
 	if err != nil {
@@ -113,7 +152,8 @@ func (r *OrderRepository) getBy(ctx context.Context, column string, value string
 			&order.State,
 			&order.StateUpdatedAt,
 			&order.CreatedAt,
-			&order.UpdatedAt)
+			&order.UpdatedAt,
+			&order.Version)
 		if err != nil {
 			return order_entity.Order{}, err
 		}
@@ -125,7 +165,7 @@ func (r *OrderRepository) getBy(ctx context.Context, column string, value string
 
 	sql, params, err = goqu.
 		From("order_payments").
-		Select("order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at").
+		Select("order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at").
 		Where(goqu.Ex{"order_id": order.Id}).
 		Order(goqu.I("created_at").Asc()).
 		ToSQL()
@@ -146,6 +186,7 @@ func (r *OrderRepository) getBy(ctx context.Context, column string, value string
 			&payment.PaymentId,
 			&payment.TotalItems,
 			&payment.Amount,
+			&payment.Amount.Currency,
 			&payment.State,
 			&payment.CreatedAt,
 			&payment.UpdatedAt)
@@ -162,7 +203,7 @@ func (r *OrderRepository) getBy(ctx context.Context, column string, value string
 
 	sql, params, err = goqu.
 		From("order_items").
-		Select("order_items.product_id", "order_items.name", "order_items.quantity", "order_items.price").
+		Select("order_items.product_id", "order_items.name", "order_items.quantity", "order_items.price", "order_items.currency").
 		LeftJoin(goqu.T("orders"), goqu.On(goqu.I("order_items.order_id").Eq(goqu.I("orders.id")))).
 		Where(goqu.ExOr{
 			"order_items.order_id": value,
@@ -185,7 +226,8 @@ func (r *OrderRepository) getBy(ctx context.Context, column string, value string
 			&item.Id,
 			&item.Name,
 			&item.Quantity,
-			&item.UnitPrice)
+			&item.UnitPrice,
+			&item.UnitPrice.Currency)
 		if err != nil {
 			return order_entity.Order{}, err
 		}
@@ -202,22 +244,12 @@ func (r *OrderRepository) GetAll(
 ) (int, []order_entity.Order, error) {
 	skip := pagination.Page*pagination.Size - pagination.Size
 
-	stateFilter := goqu.And(
-		goqu.Ex{"customer_id": filter.CustomerID},
-		goqu.Ex{"state": goqu.Op{
-			"gte": filter.StateFrom,
-		}},
-		goqu.Ex{"state": goqu.Op{
-			"lt": filter.StateTo,
-		}},
-	)
+	where := buildGetAllFilter(filter)
 
 	sql, params, err := goqu.
 		From("orders").
 		Select(goqu.COUNT("id")).
-		Where(goqu.And(stateFilter)).
-		Limit(uint(pagination.Size)).
-		Offset(uint(skip)).
+		Where(where).
 		ToSQL()
 
 	if err != nil {
@@ -228,6 +260,7 @@ func (r *OrderRepository) GetAll(
 	if err != nil {
 		return 0, nil, err
 	}
+	defer statement.Close()
 
 	var count int
 
@@ -240,8 +273,8 @@ func (r *OrderRepository) GetAll(
 
 	sql, params, err = goqu.
 		From("orders").
-		Select("id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at").
-		Where(stateFilter).
+		Select("id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version").
+		Where(where).
 		Order(goqu.I("created_at").Asc()).
 		Limit(uint(pagination.Size)).
 		Offset(uint(skip)).
@@ -254,6 +287,7 @@ func (r *OrderRepository) GetAll(
 	if err != nil {
 		return 0, nil, err
 	}
+	defer statement.Close()
 
 	orders := []order_entity.Order{}
 
@@ -266,7 +300,8 @@ func (r *OrderRepository) GetAll(
 			&order.State,
 			&order.StateUpdatedAt,
 			&order.CreatedAt,
-			&order.UpdatedAt)
+			&order.UpdatedAt,
+			&order.Version)
 		if err != nil {
 			return 0, nil, err
 		}
@@ -276,21 +311,33 @@ func (r *OrderRepository) GetAll(
 	return count, orders, nil
 }
 
-func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order, updateItems bool) error {
-	queryUpdateOrder := `
-		UPDATE orders
-		SET state = $1, state_updated_at = $2, updated_at = $3
-		WHERE id = $4;
-	`
+func buildGetAllFilter(filter repository.GetAllOrdersFilter) exp.ExpressionList {
+	expressions := []exp.Expression{}
+
+	if filter.CustomerID != "" {
+		expressions = append(expressions, goqu.Ex{"customer_id": filter.CustomerID})
+	}
+
+	if filter.StateFrom != order_entity.None {
+		expressions = append(expressions, goqu.Ex{"state": goqu.Op{"gte": filter.StateFrom}})
+	}
 
+	if filter.StateTo != order_entity.None {
+		expressions = append(expressions, goqu.Ex{"state": goqu.Op{"lt": filter.StateTo}})
+	}
+
+	return goqu.And(expressions...)
+}
+
+func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order, updateItems bool) error {
 	queryDeleteOrderItems := `
 		DELETE FROM order_items
 		WHERE order_id = $1;
 	`
 
 	queryInsertOrderItems := `
-		INSERT INTO order_items (order_id, product_id, name, quantity, price)
-		VALUES ($1, $2, $3, $4, $5);
+		INSERT INTO order_items (order_id, product_id, name, quantity, price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6);
 	`
 
 	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
@@ -298,13 +345,7 @@ func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order,
 		return err
 	}
 
-	res, err := tx.ExecContext(ctx,
-		queryUpdateOrder,
-		order.State,
-		order.StateUpdatedAt,
-		order.UpdatedAt,
-		order.Id)
-	if err != nil {
+	if err := r.updateOrder(ctx, tx, order); err != nil {
 		errTx := tx.Rollback()
 		if errTx != nil {
 			return errTx
@@ -312,8 +353,7 @@ func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order,
 		return err
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
+	if err := inbox_repository.RecordMessage(ctx, tx, r.timeProvider.GetTime()); err != nil {
 		errTx := tx.Rollback()
 		if errTx != nil {
 			return errTx
@@ -321,14 +361,6 @@ func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order,
 		return err
 	}
 
-	if rowsAffected == 0 {
-		errTx := tx.Rollback()
-		if errTx != nil {
-			return errTx
-		}
-		return custom_error.ErrOrderNotFound
-	}
-
 	if updateItems {
 		_, err = tx.ExecContext(ctx,
 			queryDeleteOrderItems,
@@ -348,7 +380,8 @@ func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order,
 				item.Id,
 				item.Name,
 				item.Quantity,
-				item.UnitPrice)
+				item.UnitPrice,
+				item.UnitPrice.Currency)
 			if err != nil {
 				errTx := tx.Rollback()
 				if errTx != nil {
@@ -359,5 +392,260 @@ func (r *OrderRepository) Update(ctx context.Context, order *order_entity.Order,
 		}
 	}
 
-	return tx.Commit()
+	if err := r.insertPendingStateTransitions(ctx, tx, order); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := outbox_repository.InsertEvents(ctx, tx, r.eventTopic, order.Version+1, withOrigin(ctx, order.PendingEvents())); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	order.ClearPendingStateTransitions()
+	order.ClearPendingEvents()
+	order.Version++
+
+	return nil
+}
+
+func (r *OrderRepository) UpdateWithPayment(ctx context.Context, order *order_entity.Order, payment *payment_entity.Payment) error {
+	queryUpdatePayment := `
+		UPDATE order_payments
+		SET state = $1, updated_at = $2
+		WHERE payment_id = $3;
+	`
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		queryUpdatePayment,
+		payment.State,
+		payment.UpdatedAt,
+		payment.PaymentId)
+	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := webhook_repository.EnqueuePaymentChanged(ctx, tx, *payment); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := r.updateOrder(ctx, tx, order); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := inbox_repository.RecordMessage(ctx, tx, r.timeProvider.GetTime()); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := r.insertPendingStateTransitions(ctx, tx, order); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	// the payment is part of the order, so its events share the new version
+	events := append(payment.PendingEvents(), withOrigin(ctx, order.PendingEvents())...)
+
+	if err := outbox_repository.InsertEvents(ctx, tx, r.eventTopic, order.Version+1, events); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	order.ClearPendingStateTransitions()
+	order.ClearPendingEvents()
+	payment.ClearPendingEvents()
+	order.Version++
+
+	return nil
+}
+
+// updateOrder only writes the order when it still has the version it was read
+// with, so concurrent updates do not overwrite each other
+func (r *OrderRepository) updateOrder(ctx context.Context, tx *sql.Tx, order *order_entity.Order) error {
+	queryUpdateOrder := `
+		UPDATE orders
+		SET state = $1, state_updated_at = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5;
+	`
+
+	queryOrderExists := `
+		SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1);
+	`
+
+	res, err := tx.ExecContext(ctx,
+		queryUpdateOrder,
+		order.State,
+		order.StateUpdatedAt,
+		order.UpdatedAt,
+		order.Id,
+		order.Version)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+
+	if err := tx.QueryRowContext(ctx, queryOrderExists, order.Id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return custom_error.ErrOrderNotFound
+	}
+
+	return custom_error.ErrOrderVersionConflict
+}
+
+func (r *OrderRepository) insertPendingStateTransitions(ctx context.Context, tx *sql.Tx, order *order_entity.Order) error {
+	for _, transition := range order.PendingStateTransitions() {
+		if err := r.insertStateTransition(ctx, tx, transition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertStateTransition records the transition with the origin of the request
+// and enqueues its webhook deliveries
+func (r *OrderRepository) insertStateTransition(ctx context.Context, tx *sql.Tx, transition order_entity.StateTransition) error {
+	queryInsertStateTransition := `
+		INSERT INTO order_state_history (order_id, from_state, to_state, actor, source, source_id, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	origin := audit.FromContext(ctx)
+
+	transition.Actor = origin.Actor
+	transition.Source = origin.Source
+	transition.SourceId = origin.SourceId
+
+	_, err := tx.ExecContext(ctx,
+		queryInsertStateTransition,
+		transition.OrderId,
+		transition.FromState,
+		transition.ToState,
+		transition.Actor,
+		transition.Source,
+		transition.SourceId,
+		transition.ChangedAt)
+	if err != nil {
+		return err
+	}
+
+	event := webhook_entity.NewEvent(webhook_entity.OrderStateChanged, transition, transition.ChangedAt)
+
+	return webhook_repository.EnqueueDeliveries(ctx, tx, event)
+}
+
+// withOrigin returns the events with the origin of the request on the state
+// transitions they carry, as it is only known when the order is persisted
+func withOrigin(ctx context.Context, events []event_entity.Event) []event_entity.Event {
+	origin := audit.FromContext(ctx)
+
+	stamped := make([]event_entity.Event, 0, len(events))
+
+	for _, event := range events {
+		if data, ok := event.Data.(order_entity.OrderStateChangedEvent); ok {
+			data.Actor = origin.Actor
+			data.Source = origin.Source
+			data.SourceId = origin.SourceId
+
+			event.Data = data
+		}
+
+		stamped = append(stamped, event)
+	}
+
+	return stamped
+}
+
+func (r *OrderRepository) GetStateHistory(ctx context.Context, orderId string) ([]order_entity.StateTransition, error) {
+	sql, params, err := goqu.
+		From("order_state_history").
+		Select("order_id", "from_state", "to_state", "actor", "source", "source_id", "changed_at").
+		Where(goqu.Ex{"order_id": orderId}).
+		Order(goqu.I("changed_at").Asc(), goqu.I("id").Asc()).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := r.conn.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	history := []order_entity.StateTransition{}
+
+	for statement.Next() {
+		transition := order_entity.StateTransition{}
+		err = statement.Scan(
+			&transition.OrderId,
+			&transition.FromState,
+			&transition.ToState,
+			&transition.Actor,
+			&transition.Source,
+			&transition.SourceId,
+			&transition.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		transition.RefreshStateTitles()
+
+		history = append(history, transition)
+	}
+
+	return history, nil
 }
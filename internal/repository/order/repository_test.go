@@ -2,7 +2,9 @@ package order_repository
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,11 +13,36 @@ import (
 	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/audit"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/inbox"
 	"github.com/stretchr/testify/assert"
 )
 
+// payloadContains matches the outbox payloads holding every fragment
+func payloadContains(fragments ...string) sqlmock.Argument {
+	return payloadMatcher(fragments)
+}
+
+type payloadMatcher []string
+
+func (m payloadMatcher) Match(value driver.Value) bool {
+	payload, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	for _, fragment := range m {
+		if !strings.Contains(payload, fragment) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func TestCreate(t *testing.T) {
 	t.Run("Should create an order", func(t *testing.T) {
 		// Arrange
@@ -28,28 +55,70 @@ func TestCreate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt).
+			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice).
+			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice, item.UnitPrice.Currency).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_state_history").
+			WithArgs(order.Id, order_entity.None, order_entity.Created, "system", audit.SourceSystem, "", order.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderCreated", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderCreated"`, `"aggregate_version":1`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "ItemAdded", sqlmock.AnyArg(), payloadContains(`"event_type":"ItemAdded"`, `"aggregate_version":1`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &order)
 
 		// Assert
 		assert.NoError(t, err)
+		assert.Empty(t, order.PendingEvents())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error if something got wrong while try to insert the state history", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO orders").
+			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt, order.Version).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_state_history").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Create(ctx, &order)
+
+		// Assert
+		assert.Error(t, err)
 		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
@@ -64,23 +133,23 @@ func TestCreate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt).
+			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice).
+			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice, item.UnitPrice.Currency).
 			WillReturnError(errors.New("something got wrong"))
 
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &order)
@@ -101,19 +170,19 @@ func TestCreate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt).
+			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt, order.Version).
 			WillReturnError(errors.New("something got wrong"))
 
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &order)
@@ -134,20 +203,20 @@ func TestCreate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt).
+			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt, order.Version).
 			WillReturnError(errors.New("something got wrong"))
 
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &order)
@@ -168,24 +237,24 @@ func TestCreate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt).
+			WithArgs(order.Id, order.CustomerId, order.TrackId, order.State, order.StateUpdatedAt, order.CreatedAt, order.UpdatedAt, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice).
+			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice, item.UnitPrice.Currency).
 			WillReturnError(errors.New("something got wrong"))
 
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &order)
@@ -206,7 +275,7 @@ func TestCreate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
@@ -214,7 +283,7 @@ func TestCreate(t *testing.T) {
 		mock.ExpectBegin().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &order)
@@ -240,22 +309,22 @@ func TestGetByID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
 
 		productId := uuid.NewString()
 
-		orderItemRows := sqlmock.NewRows([]string{"product_id", "name", "quantity", "price"}).
-			AddRow(productId, "name", 1, 10.0)
+		orderItemRows := sqlmock.NewRows([]string{"product_id", "name", "quantity", "price", "currency"}).
+			AddRow(productId, "name", 1, "10.00", common.DefaultCurrency)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnRows(orderItemRows)
@@ -271,7 +340,7 @@ func TestGetByID(t *testing.T) {
 					Id:        productId,
 					Name:      "name",
 					Quantity:  1,
-					UnitPrice: 10.0,
+					UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
 				},
 			},
 			Payments: []payment_entity.Payment{
@@ -279,7 +348,7 @@ func TestGetByID(t *testing.T) {
 					OrderId:    orderId,
 					PaymentId:  paymentId,
 					TotalItems: 1,
-					Amount:     10.5,
+					Amount:     common.NewMoney(1050, common.DefaultCurrency),
 					State:      payment_entity.WaitingForApproval,
 					StateTitle: "WaitingForApproval",
 					CreatedAt:  now,
@@ -288,9 +357,10 @@ func TestGetByID(t *testing.T) {
 			},
 			CreatedAt: now,
 			UpdatedAt: now,
+			Version:   1,
 		}
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -316,14 +386,14 @@ func TestGetByID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
@@ -345,7 +415,7 @@ func TestGetByID(t *testing.T) {
 					OrderId:    orderId,
 					PaymentId:  paymentId,
 					TotalItems: 1,
-					Amount:     10.5,
+					Amount:     common.NewMoney(1050, common.DefaultCurrency),
 					State:      payment_entity.WaitingForApproval,
 					StateTitle: "WaitingForApproval",
 					CreatedAt:  now,
@@ -354,9 +424,10 @@ func TestGetByID(t *testing.T) {
 			},
 			CreatedAt: now,
 			UpdatedAt: now,
+			Version:   1,
 		}
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -383,7 +454,7 @@ func TestGetByID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -408,7 +479,7 @@ func TestGetByID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -432,13 +503,13 @@ func TestGetByID(t *testing.T) {
 		orderId := uuid.NewString()
 		customerId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", "Created", now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", "Created", now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -463,14 +534,14 @@ func TestGetByID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
@@ -478,7 +549,7 @@ func TestGetByID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnError(assert.AnError)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -503,14 +574,14 @@ func TestGetByID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
@@ -523,7 +594,7 @@ func TestGetByID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnRows(orderItemRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByID(ctx, orderId)
@@ -551,22 +622,22 @@ func TestGetByTrackID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
 
 		productId := uuid.NewString()
 
-		orderItemRows := sqlmock.NewRows([]string{"product_id", "name", "quantity", "price"}).
-			AddRow(productId, "name", 1, 10.0)
+		orderItemRows := sqlmock.NewRows([]string{"product_id", "name", "quantity", "price", "currency"}).
+			AddRow(productId, "name", 1, "10.00", common.DefaultCurrency)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnRows(orderItemRows)
@@ -582,7 +653,7 @@ func TestGetByTrackID(t *testing.T) {
 					Id:        productId,
 					Name:      "name",
 					Quantity:  1,
-					UnitPrice: 10.0,
+					UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
 				},
 			},
 			Payments: []payment_entity.Payment{
@@ -590,7 +661,7 @@ func TestGetByTrackID(t *testing.T) {
 					OrderId:    orderId,
 					PaymentId:  paymentId,
 					TotalItems: 1,
-					Amount:     10.5,
+					Amount:     common.NewMoney(1050, common.DefaultCurrency),
 					State:      payment_entity.WaitingForApproval,
 					StateTitle: "WaitingForApproval",
 					CreatedAt:  now,
@@ -599,9 +670,10 @@ func TestGetByTrackID(t *testing.T) {
 			},
 			CreatedAt: now,
 			UpdatedAt: now,
+			Version:   1,
 		}
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -628,19 +700,19 @@ func TestGetByTrackID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
 
-		orderItemRows := sqlmock.NewRows([]string{"product_id", "name", "quantity", "price"})
+		orderItemRows := sqlmock.NewRows([]string{"product_id", "name", "quantity", "price", "currency"})
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnRows(orderItemRows)
@@ -657,7 +729,7 @@ func TestGetByTrackID(t *testing.T) {
 					OrderId:    orderId,
 					PaymentId:  paymentId,
 					TotalItems: 1,
-					Amount:     10.5,
+					Amount:     common.NewMoney(1050, common.DefaultCurrency),
 					State:      payment_entity.WaitingForApproval,
 					StateTitle: "WaitingForApproval",
 					CreatedAt:  now,
@@ -666,9 +738,10 @@ func TestGetByTrackID(t *testing.T) {
 			},
 			CreatedAt: now,
 			UpdatedAt: now,
+			Version:   1,
 		}
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -690,12 +763,12 @@ func TestGetByTrackID(t *testing.T) {
 
 		trackId := "ABC-123"
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"})
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"})
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -720,7 +793,7 @@ func TestGetByTrackID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -745,13 +818,13 @@ func TestGetByTrackID(t *testing.T) {
 		customerId := uuid.NewString()
 		trackId := "ABC123"
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, trackId, "Created", now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, trackId, "Created", now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -777,14 +850,14 @@ func TestGetByTrackID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
@@ -792,7 +865,7 @@ func TestGetByTrackID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnError(assert.AnError)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -818,14 +891,14 @@ func TestGetByTrackID(t *testing.T) {
 		customerId := uuid.NewString()
 		paymentId := uuid.NewString()
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, trackId, order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(orderRows)
 
-		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at"}).
-			AddRow(orderId, paymentId, 1, 10.5, payment_entity.WaitingForApproval, now, now)
+		paymentRows := sqlmock.NewRows([]string{"order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at"}).
+			AddRow(orderId, paymentId, 1, "10.50", common.DefaultCurrency, payment_entity.WaitingForApproval, now, now)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_payments(.+)?").
 			WillReturnRows(paymentRows)
@@ -838,7 +911,7 @@ func TestGetByTrackID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?order_items(.+)?").
 			WillReturnRows(orderItemRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		res, err := repo.GetByTrackID(ctx, trackId)
@@ -867,13 +940,13 @@ func TestGetAll(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT(.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)? ORDER BY (.+)").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		pagination := common.Pagination{
 			Page: 1,
@@ -892,6 +965,51 @@ func TestGetAll(t *testing.T) {
 		assert.Equal(t, 1, count)
 	})
 
+	t.Run("Should not limit nor offset the order count", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderId := uuid.NewString()
+		customerId := uuid.NewString()
+
+		mock.ExpectQuery(`SELECT COUNT\("id"\) FROM "orders" WHERE \(\("customer_id" = '.+'\) AND \("state" >= 2\) AND \("state" < 3\)\)$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Received, now, now, now, 1)
+
+		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)? ORDER BY (.+) LIMIT 10 OFFSET 20").
+			WillReturnRows(orderRows)
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		pagination := common.Pagination{
+			Page: 3,
+			Size: 10,
+		}
+
+		filter := repository.GetAllOrdersFilter{
+			CustomerID: customerId,
+			StateFrom:  order_entity.Received,
+			StateTo:    order_entity.Processing,
+		}
+
+		// Act
+		count, res, err := repo.GetAll(ctx, pagination, filter)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+		assert.Len(t, res, 1)
+		assert.Equal(t, 25, count)
+	})
+
 	t.Run("Should return error when something got wrong with order count", func(t *testing.T) {
 		// Arrange
 		db, mock, err := sqlmock.New()
@@ -903,7 +1021,7 @@ func TestGetAll(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT(.+) FROM (.+)?orders(.+)?").
 			WillReturnError(assert.AnError)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		pagination := common.Pagination{
 			Page: 1,
@@ -933,7 +1051,7 @@ func TestGetAll(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT(.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("abc"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		pagination := common.Pagination{
 			Page: 1,
@@ -968,13 +1086,13 @@ func TestGetAll(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT(.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", order_entity.Created, now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)? ORDER BY (.+)").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		pagination := common.Pagination{
 			Page: 1,
@@ -1009,7 +1127,7 @@ func TestGetAll(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)? ORDER BY (.+)").
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		pagination := common.Pagination{
 			Page: 1,
@@ -1044,13 +1162,13 @@ func TestGetAll(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT(.+) FROM (.+)?orders(.+)?").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at"}).
-			AddRow(orderId, customerId, "ABC123", "Created", now, now, now)
+		orderRows := sqlmock.NewRows([]string{"id", "customer_id", "track_id", "state", "state_updated_at", "created_at", "updated_at", "version"}).
+			AddRow(orderId, customerId, "ABC123", "Created", now, now, now, 1)
 
 		mock.ExpectQuery("SELECT (.+) FROM (.+)?orders(.+)? ORDER BY (.+)").
 			WillReturnRows(orderRows)
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		pagination := common.Pagination{
 			Page: 1,
@@ -1082,18 +1200,91 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderCreated", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderCreated"`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "ItemAdded", sqlmock.AnyArg(), payloadContains(`"event_type":"ItemAdded"`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &order, false)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, order.Version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return a conflict when the order was changed meanwhile", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE orders").
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(order.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &order, true)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrOrderVersionConflict)
+		assert.Equal(t, 1, order.Version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should record the inbound message while updating an order", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := inbox.WithMessage(context.Background(), "message_id")
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE orders").
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO inbox").
+			WithArgs("message_id", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderCreated", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderCreated"`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(func() time.Time { return now }))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1114,18 +1305,21 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(order.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1147,18 +1341,18 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewErrorResult(errors.New("something got wrong")))
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1179,20 +1373,20 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewErrorResult(errors.New("something got wrong")))
 
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1213,20 +1407,23 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(order.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1247,18 +1444,18 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnError(errors.New("something got wrong"))
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1279,19 +1476,19 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnError(errors.New("something got wrong"))
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, false)
@@ -1312,14 +1509,14 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("DELETE FROM order_items").
@@ -1327,12 +1524,18 @@ func TestUpdate(t *testing.T) {
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice).
+			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice, item.UnitPrice.Currency).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderCreated", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderCreated"`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "ItemAdded", sqlmock.AnyArg(), payloadContains(`"event_type":"ItemAdded"`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, true)
@@ -1353,14 +1556,14 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("DELETE FROM order_items").
@@ -1368,13 +1571,13 @@ func TestUpdate(t *testing.T) {
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice).
+			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice, item.UnitPrice.Currency).
 			WillReturnError(errors.New("something got wrong"))
 
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, true)
@@ -1395,14 +1598,14 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("DELETE FROM order_items").
@@ -1410,12 +1613,12 @@ func TestUpdate(t *testing.T) {
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice).
+			WithArgs(order.Id, item.Id, item.Name, item.Quantity, item.UnitPrice, item.UnitPrice.Currency).
 			WillReturnError(errors.New("something got wrong"))
 
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, true)
@@ -1436,14 +1639,14 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("DELETE FROM order_items").
@@ -1453,7 +1656,7 @@ func TestUpdate(t *testing.T) {
 		mock.ExpectRollback().
 			WillReturnError(errors.New("something got wrong"))
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, true)
@@ -1474,14 +1677,14 @@ func TestUpdate(t *testing.T) {
 		now := time.Now()
 
 		order := order_entity.NewOrder("customer_id", now)
-		item := order_entity.NewItem("product_id", "product name", 1, 10.0)
+		item := order_entity.NewItem("product_id", "product name", common.NewMoney(100, common.DefaultCurrency), 10.0)
 
 		err = order.AddItem(item, now)
 		assert.NoError(t, err)
 
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE orders").
-			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id).
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("DELETE FROM order_items").
@@ -1490,7 +1693,7 @@ func TestUpdate(t *testing.T) {
 
 		mock.ExpectRollback()
 
-		repo := NewOrderRepository(db)
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &order, true)
@@ -1499,4 +1702,323 @@ func TestUpdate(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("Should record the state transitions with the request origin", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := audit.WithOrigin(context.Background(), audit.Origin{
+			Actor:    "user_id",
+			Source:   audit.SourceHttp,
+			SourceId: "request_id",
+		})
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+
+		err = order.UpdateState(order_entity.Received, now)
+		assert.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE orders").
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_state_history").
+			WithArgs(order.Id, order_entity.Created, order_entity.Received, "user_id", audit.SourceHttp, "request_id", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderCreated", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderCreated"`, `"aggregate_version":2`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderStateChanged", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderStateChanged"`, `"aggregate_version":2`, `"actor":"user_id"`, `"source":"http"`, `"source_id":"request_id"`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &order, false)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, order.PendingStateTransitions())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when insert the state transitions", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+
+		err = order.UpdateState(order_entity.Received, now)
+		assert.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE orders").
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_state_history").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &order, false)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Len(t, order.PendingStateTransitions(), 1)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateWithPayment(t *testing.T) {
+	t.Run("Should update the payment and the order in the same transaction", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), now)
+		payment.UpdateState(payment_entity.Approved, now)
+
+		err = order.UpdateState(order_entity.Received, now)
+		assert.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE order_payments").
+			WithArgs(payment.State, payment.UpdatedAt, payment.PaymentId).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE orders").
+			WithArgs(order.State, order.StateUpdatedAt, order.UpdatedAt, order.Id, order.Version).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_state_history").
+			WithArgs(order.Id, order_entity.Created, order_entity.Received, "system", audit.SourceSystem, "", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "PaymentRequested", sqlmock.AnyArg(), payloadContains(`"event_type":"PaymentRequested"`, `"aggregate_version":2`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "PaymentApproved", sqlmock.AnyArg(), payloadContains(`"event_type":"PaymentApproved"`, `"aggregate_version":2`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderCreated", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderCreated"`, `"aggregate_version":2`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "OrderStateChanged", sqlmock.AnyArg(), payloadContains(`"event_type":"OrderStateChanged"`, `"aggregate_version":2`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.UpdateWithPayment(ctx, &order, &payment)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, order.PendingEvents())
+		assert.Empty(t, payment.PendingEvents())
+		assert.Empty(t, order.PendingStateTransitions())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when update the payment fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE order_payments").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.UpdateWithPayment(ctx, &order, &payment)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should rollback the payment when no order were updated", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE order_payments").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE orders").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(order.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.UpdateWithPayment(ctx, &order, &payment)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderNotFound)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when insert the state transitions", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		order := order_entity.NewOrder("customer_id", now)
+		payment := payment_entity.NewPayment(order.Id, "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), now)
+
+		err = order.UpdateState(order_entity.Received, now)
+		assert.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE order_payments").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE orders").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_state_history").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.UpdateWithPayment(ctx, &order, &payment)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Len(t, order.PendingStateTransitions(), 1)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetStateHistory(t *testing.T) {
+	t.Run("Should get the state history of an order", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM \"order_state_history\"").
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "from_state", "to_state", "actor", "source", "source_id", "changed_at"}).
+				AddRow("order_id", order_entity.None, order_entity.Created, "system", audit.SourceSystem, "", now).
+				AddRow("order_id", order_entity.Created, order_entity.Received, "queue", audit.SourceQueue, "message_id", now))
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		res, err := repo.GetStateHistory(ctx, "order_id")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "Created", res[0].ToStateTitle)
+		assert.Equal(t, "Received", res[1].ToStateTitle)
+		assert.Equal(t, "message_id", res[1].SourceId)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when try to query the state history", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT (.+) FROM \"order_state_history\"").
+			WillReturnError(assert.AnError)
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		res, err := repo.GetStateHistory(ctx, "order_id")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return a scan error while try to parse the state history rows", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT (.+) FROM \"order_state_history\"").
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "from_state", "to_state", "actor", "source", "source_id", "changed_at"}).
+				AddRow("order_id", "abc", order_entity.Created, "system", audit.SourceSystem, "", "abc"))
+
+		repo := NewOrderRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		res, err := repo.GetStateHistory(ctx, "order_id")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
 }
@@ -0,0 +1,174 @@
+package outbox_repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracing"
+)
+
+type OutboxRepository struct {
+	conn *sql.DB
+}
+
+func NewOutboxRepository(conn *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		conn: conn,
+	}
+}
+
+func (r *OutboxRepository) Create(ctx context.Context, message *outbox_entity.Message) error {
+	return InsertMessage(ctx, r.conn, message)
+}
+
+// ClaimPending leases up to limit messages ready to be published until
+// leaseUntil, so concurrent relays skip them while they are being sent
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, now time.Time, leaseUntil time.Time) ([]outbox_entity.Message, error) {
+	query := `
+		UPDATE outbox
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE state = $2 AND next_attempt_at <= $3
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, topic, event_type, subject, payload, state, message_id, trace_context, attempts, last_error, next_attempt_at, created_at, updated_at;
+	`
+
+	statement, err := r.conn.QueryContext(ctx,
+		query,
+		leaseUntil,
+		outbox_entity.Pending,
+		now,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	messages := []outbox_entity.Message{}
+
+	for statement.Next() {
+		message := outbox_entity.Message{}
+		var traceContext string
+		err = statement.Scan(
+			&message.Id,
+			&message.Topic,
+			&message.EventType,
+			&message.Subject,
+			&message.Payload,
+			&message.State,
+			&message.MessageId,
+			&traceContext,
+			&message.Attempts,
+			&message.LastError,
+			&message.NextAttemptAt,
+			&message.CreatedAt,
+			&message.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(traceContext), &message.TraceContext); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) Update(ctx context.Context, message *outbox_entity.Message) error {
+	query := `
+		UPDATE outbox
+		SET state = $1, message_id = $2, attempts = $3, last_error = $4, next_attempt_at = $5, updated_at = $6
+		WHERE id = $7;
+	`
+
+	_, err := r.conn.ExecContext(ctx,
+		query,
+		message.State,
+		message.MessageId,
+		message.Attempts,
+		message.LastError,
+		message.NextAttemptAt,
+		message.UpdatedAt,
+		message.Id)
+
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// InsertMessage writes the message using either the connection or an open
+// transaction, letting other repositories store it atomically with their rows.
+// The message keeps the trace of ctx unless it already has one
+func InsertMessage(ctx context.Context, conn execer, message *outbox_entity.Message) error {
+	query := `
+		INSERT INTO outbox (id, topic, event_type, subject, payload, state, message_id, trace_context, attempts, last_error, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+	`
+
+	if message.TraceContext == nil {
+		message.TraceContext = tracing.Inject(ctx)
+	}
+
+	traceContext, err := json.Marshal(message.TraceContext)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx,
+		query,
+		message.Id,
+		message.Topic,
+		message.EventType,
+		message.Subject,
+		message.Payload,
+		message.State,
+		message.MessageId,
+		string(traceContext),
+		message.Attempts,
+		message.LastError,
+		message.NextAttemptAt,
+		message.CreatedAt,
+		message.UpdatedAt)
+
+	return err
+}
+
+// InsertEvents stores the domain events in the outbox stamped with the version
+// the aggregate has once the surrounding transaction commits
+func InsertEvents(ctx context.Context, conn execer, topic string, aggregateVersion int, events []event_entity.Event) error {
+	for _, event := range events {
+		event.AggregateVersion = aggregateVersion
+
+		message, err := outbox_entity.NewEventMessage(topic, string(event.EventType), event.AggregateId, event, event.OccurredAt)
+		if err != nil {
+			return err
+		}
+
+		// consumers deduplicate by the envelope id, which must be the event one
+		message.Id = event.Id
+
+		if err := InsertMessage(ctx, conn, &message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package outbox_repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// payloadContains matches the outbox payloads holding every fragment
+func payloadContains(fragments ...string) sqlmock.Argument {
+	return payloadMatcher(fragments)
+}
+
+type payloadMatcher []string
+
+func (m payloadMatcher) Match(value driver.Value) bool {
+	payload, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	for _, fragment := range m {
+		if !strings.Contains(payload, fragment) {
+			return false
+		}
+	}
+
+	return true
+}
+
+var outboxColumns = []string{"id", "topic", "event_type", "subject", "payload", "state", "message_id", "trace_context", "attempts", "last_error", "next_attempt_at", "created_at", "updated_at"}
+
+func TestCreate(t *testing.T) {
+	t.Run("Should create a message", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		message, err := outbox_entity.NewMessage("topic", "payload", time.Now())
+		assert.NoError(t, err)
+
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(message.Id, message.Topic, message.EventType, message.Subject, message.Payload, message.State, message.MessageId, "{}", message.Attempts, message.LastError, message.NextAttemptAt, message.CreatedAt, message.UpdatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		err = repo.Create(ctx, &message)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should keep the trace context the message already has", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		message, err := outbox_entity.NewMessage("topic", "payload", time.Now())
+		assert.NoError(t, err)
+
+		message.TraceContext = map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(message.Id, message.Topic, message.EventType, message.Subject, message.Payload, message.State, message.MessageId, `{"traceparent":"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}`, message.Attempts, message.LastError, message.NextAttemptAt, message.CreatedAt, message.UpdatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		err = repo.Create(ctx, &message)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("INSERT INTO outbox").
+			WillReturnError(assert.AnError)
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		err = repo.Create(ctx, &outbox_entity.Message{})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimPending(t *testing.T) {
+	t.Run("Should claim the pending messages ordered by creation", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+		leaseUntil := now.Add(time.Minute)
+
+		mock.ExpectQuery("UPDATE outbox").
+			WithArgs(leaseUntil, outbox_entity.Pending, now, 10).
+			WillReturnRows(sqlmock.NewRows(outboxColumns).
+				AddRow("2", "topic", "OrderCreated", "order-id", "{}", outbox_entity.Pending, "", "{}", 0, "", leaseUntil, now, now).
+				AddRow("1", "topic", "OrderCreated", "order-id", "{}", outbox_entity.Pending, "", `{"traceparent":"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}`, 0, "", leaseUntil, now.Add(-time.Second), now))
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		messages, err := repo.ClaimPending(ctx, 10, now, leaseUntil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "1", messages[0].Id)
+		assert.Equal(t, "2", messages[1].Id)
+		assert.Equal(t, map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}, messages[0].TraceContext)
+		assert.Empty(t, messages[1].TraceContext)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the trace context is invalid", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("UPDATE outbox").
+			WillReturnRows(sqlmock.NewRows(outboxColumns).
+				AddRow("1", "topic", "OrderCreated", "order-id", "{}", outbox_entity.Pending, "", "invalid", 0, "", now, now, now))
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		messages, err := repo.ClaimPending(ctx, 10, now, now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the query fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("UPDATE outbox").
+			WillReturnError(assert.AnError)
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		messages, err := repo.ClaimPending(ctx, 10, now, now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return a scan error while try to parse the rows", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("UPDATE outbox").
+			WillReturnRows(sqlmock.NewRows(outboxColumns).
+				AddRow("1", "topic", "OrderCreated", "order-id", "{}", "abc", "", "{}", 0, "", now, now, now))
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		messages, err := repo.ClaimPending(ctx, 10, now, now)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("Should update a message", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		message, err := outbox_entity.NewMessage("topic", "payload", time.Now())
+		assert.NoError(t, err)
+
+		message.MarkAsSent("message_id", time.Now())
+
+		mock.ExpectExec("UPDATE outbox").
+			WithArgs(message.State, message.MessageId, message.Attempts, message.LastError, message.NextAttemptAt, message.UpdatedAt, message.Id).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		err = repo.Update(ctx, &message)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the update fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("UPDATE outbox").
+			WillReturnError(assert.AnError)
+
+		repo := NewOutboxRepository(db)
+
+		// Act
+		err = repo.Update(ctx, &outbox_entity.Message{})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInsertEvents(t *testing.T) {
+	t.Run("Should store each event stamped with the aggregate version", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		events := []event_entity.Event{
+			event_entity.NewEvent(event_entity.OrderCreated, "order-id", "created", now),
+			event_entity.NewEvent(event_entity.ItemAdded, "order-id", "item", now),
+		}
+
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(events[0].Id, "events", "OrderCreated", "order-id", payloadContains(`"aggregate_version":3`), outbox_entity.Pending, "", "{}", 0, "", now, now, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(events[1].Id, "events", "ItemAdded", "order-id", payloadContains(`"aggregate_version":3`), outbox_entity.Pending, "", "{}", 0, "", now, now, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Act
+		err = InsertEvents(ctx, db, "events", 3, events)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		events := []event_entity.Event{
+			event_entity.NewEvent(event_entity.OrderCreated, "order-id", "created", time.Now()),
+		}
+
+		mock.ExpectExec("INSERT INTO outbox").
+			WillReturnError(assert.AnError)
+
+		// Act
+		err = InsertEvents(ctx, db, "events", 1, events)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
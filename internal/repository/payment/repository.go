@@ -3,31 +3,43 @@ package payment
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	inbox_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/inbox"
+	outbox_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/outbox"
+	webhook_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/webhook"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 )
 
 type PaymentRepository struct {
-	conn *sql.DB
+	conn         *sql.DB
+	eventTopic   string
+	timeProvider provider.TimeProvider
 }
 
-func NewPaymentRepository(conn *sql.DB) *PaymentRepository {
+func NewPaymentRepository(conn *sql.DB, eventTopic string, timeProvider provider.TimeProvider) *PaymentRepository {
 	return &PaymentRepository{
-		conn: conn,
+		conn:         conn,
+		eventTopic:   eventTopic,
+		timeProvider: timeProvider,
 	}
 }
 
 func (r *PaymentRepository) Create(ctx context.Context, payment *payment_entity.Payment) error {
 	sql, params, err := goqu.
 		Insert("order_payments").
-		Cols("order_id", "payment_id", "total_items", "amount", "state", "created_at", "updated_at").
+		Cols("order_id", "payment_id", "total_items", "amount", "currency", "state", "created_at", "updated_at").
 		Vals(
 			goqu.Vals{
 				payment.OrderId,
 				payment.PaymentId,
 				payment.TotalItems,
 				payment.Amount,
+				payment.Amount.Currency,
 				payment.State,
 				payment.CreatedAt,
 				payment.UpdatedAt,
@@ -46,8 +58,74 @@ func (r *PaymentRepository) Create(ctx context.Context, payment *payment_entity.
 	return nil
 }
 
+// CreateWithMessage stores the payment along with its request message, only
+// when the order still has the version it was read with, so a payment is never
+// requested for a total changed meanwhile
+func (r *PaymentRepository) CreateWithMessage(ctx context.Context, payment *payment_entity.Payment, message *outbox_entity.Message, orderVersion int) error {
+	queryInsertPayment := `
+		INSERT INTO order_payments (order_id, payment_id, total_items, amount, currency, state, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	version, err := bumpOrderVersion(ctx, tx, payment.OrderId, orderVersion)
+	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		queryInsertPayment,
+		payment.OrderId,
+		payment.PaymentId,
+		payment.TotalItems,
+		payment.Amount,
+		payment.Amount.Currency,
+		payment.State,
+		payment.CreatedAt,
+		payment.UpdatedAt)
+	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := outbox_repository.InsertMessage(ctx, tx, message); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := outbox_repository.InsertEvents(ctx, tx, r.eventTopic, version, payment.PendingEvents()); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	payment.ClearPendingEvents()
+
+	return nil
+}
+
 func (r *PaymentRepository) Update(ctx context.Context, payment *payment_entity.Payment) error {
-	sql, params, err := goqu.
+	query, params, err := goqu.
 		Update("order_payments").
 		Set(goqu.Record{
 			"state":      payment.State,
@@ -59,10 +137,115 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *payment_entity.
 		return err
 	}
 
-	_, err = r.conn.ExecContext(ctx, sql, params...)
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, params...)
 	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := webhook_repository.EnqueuePaymentChanged(ctx, tx, *payment); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
 		return err
 	}
 
+	if err := r.insertEvents(ctx, tx, payment); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := inbox_repository.RecordMessage(ctx, tx, r.timeProvider.GetTime()); err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	payment.ClearPendingEvents()
+
 	return nil
 }
+
+// insertEvents stores the payment events with a new version of its order, the
+// payment being part of it, so each write gets its own aggregate version
+func (r *PaymentRepository) insertEvents(ctx context.Context, tx *sql.Tx, payment *payment_entity.Payment) error {
+	queryBumpOrderVersion := `
+		UPDATE orders
+		SET version = version + 1
+		WHERE id = $1
+		RETURNING version;
+	`
+
+	events := payment.PendingEvents()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	var version int
+
+	if err := tx.QueryRowContext(ctx, queryBumpOrderVersion, payment.OrderId).Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return custom_error.ErrOrderNotFound
+		}
+		return err
+	}
+
+	return outbox_repository.InsertEvents(ctx, tx, r.eventTopic, version, events)
+}
+
+// bumpOrderVersion moves the order to a new version only when it still has
+// the expected one, locking it until the end of the transaction
+func bumpOrderVersion(ctx context.Context, tx *sql.Tx, orderId string, expectedVersion int) (int, error) {
+	queryBumpOrderVersion := `
+		UPDATE orders
+		SET version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING version;
+	`
+
+	queryOrderExists := `
+		SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1);
+	`
+
+	var version int
+
+	err := tx.QueryRowContext(ctx, queryBumpOrderVersion, orderId, expectedVersion).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	var exists bool
+
+	if err := tx.QueryRowContext(ctx, queryOrderExists, orderId).Scan(&exists); err != nil {
+		return 0, err
+	}
+
+	if !exists {
+		return 0, custom_error.ErrOrderNotFound
+	}
+
+	return 0, custom_error.ErrOrderVersionConflict
+}
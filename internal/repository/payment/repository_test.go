@@ -2,13 +2,45 @@ package payment
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/inbox"
 	"github.com/stretchr/testify/assert"
 )
 
+// payloadContains matches the outbox payloads holding every fragment
+func payloadContains(fragments ...string) sqlmock.Argument {
+	return payloadMatcher(fragments)
+}
+
+type payloadMatcher []string
+
+func (m payloadMatcher) Match(value driver.Value) bool {
+	payload, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	for _, fragment := range m {
+		if !strings.Contains(payload, fragment) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func TestCreate(t *testing.T) {
 	t.Run("Should create a payment", func(t *testing.T) {
 		// Arrange
@@ -21,7 +53,7 @@ func TestCreate(t *testing.T) {
 		mock.ExpectExec("INSERT INTO (.+)?order_payments(.+)?").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		repo := NewPaymentRepository(db)
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &payment_entity.Payment{})
@@ -41,7 +73,7 @@ func TestCreate(t *testing.T) {
 		mock.ExpectExec("INSERT INTO (.+)?order_payments(.+)?").
 			WillReturnError(assert.AnError)
 
-		repo := NewPaymentRepository(db)
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Create(ctx, &payment_entity.Payment{})
@@ -51,6 +83,227 @@ func TestCreate(t *testing.T) {
 	})
 }
 
+func TestCreateWithMessage(t *testing.T) {
+	t.Run("Should create a payment and the outbox message in the same transaction", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1 WHERE id = \\$1 AND version = \\$2").
+			WithArgs("", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+		mock.ExpectExec("INSERT INTO order_payments").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment_entity.Payment{}, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should store the payment events with a new version of the order", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		payment := payment_entity.NewPayment("order_id", "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), time.Now())
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1 WHERE id = \\$1 AND version = \\$2").
+			WithArgs("order_id", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+		mock.ExpectExec("INSERT INTO order_payments").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "PaymentRequested", sqlmock.AnyArg(), payloadContains(`"event_type":"PaymentRequested"`, `"aggregate_version":4`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, payment.PendingEvents())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should rollback when the order version can not be bumped", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		payment := payment_entity.NewPayment("order_id", "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), time.Now())
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Len(t, payment.PendingEvents(), 1)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return conflict when the order was changed meanwhile", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		payment := payment_entity.NewPayment("order_id", "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), time.Now())
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1").
+			WithArgs("order_id", 3).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs("order_id").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrOrderVersionConflict)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return not found when the order does not exist", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		payment := payment_entity.NewPayment("order_id", "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), time.Now())
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT EXISTS").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrOrderNotFound)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should rollback when the payment insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1 WHERE id = \\$1 AND version = \\$2").
+			WithArgs("", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+		mock.ExpectExec("INSERT INTO order_payments").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment_entity.Payment{}, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should rollback when the outbox insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1 WHERE id = \\$1 AND version = \\$2").
+			WithArgs("", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+		mock.ExpectExec("INSERT INTO order_payments").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment_entity.Payment{}, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return an error when the transaction can not be started", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectBegin().
+			WillReturnError(assert.AnError)
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.CreateWithMessage(ctx, &payment_entity.Payment{}, &outbox_entity.Message{}, 3)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	t.Run("Should update a payment", func(t *testing.T) {
 		// Arrange
@@ -60,10 +313,14 @@ func TestUpdate(t *testing.T) {
 
 		ctx := context.Background()
 
+		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
 
-		repo := NewPaymentRepository(db)
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &payment_entity.Payment{})
@@ -72,6 +329,75 @@ func TestUpdate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Should store the payment events with a new version of the order", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		payment := payment_entity.NewPayment("order_id", "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), time.Now())
+		payment.ClearPendingEvents()
+		payment.UpdateState(payment_entity.Rejected, time.Now())
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1").
+			WithArgs("order_id").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), "events", "PaymentRejected", sqlmock.AnyArg(), payloadContains(`"event_type":"PaymentRejected"`, `"aggregate_version":3`), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &payment)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, payment.PendingEvents())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return not found when the order of the payment does not exist", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		payment := payment_entity.NewPayment("order_id", "payment_id", 1, common.NewMoney(100, common.DefaultCurrency), time.Now())
+		payment.ClearPendingEvents()
+		payment.UpdateState(payment_entity.Rejected, time.Now())
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("UPDATE orders SET version = version \\+ 1").
+			WithArgs("order_id").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}))
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &payment)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrOrderNotFound)
+		assert.Len(t, payment.PendingEvents(), 1)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Should return an error when the update fails", func(t *testing.T) {
 		// Arrange
 		db, mock, err := sqlmock.New()
@@ -80,10 +406,12 @@ func TestUpdate(t *testing.T) {
 
 		ctx := context.Background()
 
+		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
 			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
 
-		repo := NewPaymentRepository(db)
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
 
 		// Act
 		err = repo.Update(ctx, &payment_entity.Payment{})
@@ -91,4 +419,87 @@ func TestUpdate(t *testing.T) {
 		// Assert
 		assert.Error(t, err)
 	})
+
+	t.Run("Should rollback when the webhook deliveries can not be enqueued", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WithArgs(sqlmock.AnyArg(), webhook_entity.PaymentStateChanged, sqlmock.AnyArg(), webhook_entity.Pending, sqlmock.AnyArg()).
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &payment_entity.Payment{})
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should record the inbound message in the same transaction", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := inbox.WithMessage(context.Background(), "message_id")
+
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO inbox").
+			WithArgs("message_id", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(func() time.Time { return now }))
+
+		// Act
+		err = repo.Update(ctx, &payment_entity.Payment{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should rollback when the inbound message was already processed", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := inbox.WithMessage(context.Background(), "message_id")
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE (.+)?order_payments(.+)?").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO inbox").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		repo := NewPaymentRepository(db, "events", time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		err = repo.Update(ctx, &payment_entity.Payment{})
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrQueueMessageAlreadyProcessed)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
 }
@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/idempotency_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
 )
 
 type OrderRepository interface {
@@ -15,9 +19,42 @@ type OrderRepository interface {
 	GetByCustomerID(ctx context.Context, customerId string) (order_entity.Order, error)
 	GetAll(ctx context.Context, pagination common.Pagination, filter GetAllOrdersFilter) (int, []order_entity.Order, error)
 	Update(ctx context.Context, order *order_entity.Order, updateItems bool) error
+	UpdateWithPayment(ctx context.Context, order *order_entity.Order, payment *payment_entity.Payment) error
+	GetStateHistory(ctx context.Context, orderId string) ([]order_entity.StateTransition, error)
 }
 
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *payment_entity.Payment) error
+	CreateWithMessage(ctx context.Context, payment *payment_entity.Payment, message *outbox_entity.Message, orderVersion int) error
 	Update(ctx context.Context, payment *payment_entity.Payment) error
 }
+
+type OutboxRepository interface {
+	Create(ctx context.Context, message *outbox_entity.Message) error
+	ClaimPending(ctx context.Context, limit int, now time.Time, leaseUntil time.Time) ([]outbox_entity.Message, error)
+	Update(ctx context.Context, message *outbox_entity.Message) error
+}
+
+type InboxRepository interface {
+	Exists(ctx context.Context, messageId string) (bool, error)
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, key *idempotency_entity.Key) (bool, error)
+	Get(ctx context.Context, userId string, key string) (idempotency_entity.Key, error)
+	Complete(ctx context.Context, key *idempotency_entity.Key) error
+	Release(ctx context.Context, userId string, key string) error
+	DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *webhook_entity.Subscription) error
+	GetSubscriptionByID(ctx context.Context, id string) (webhook_entity.Subscription, error)
+	GetAllSubscriptions(ctx context.Context) ([]webhook_entity.Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *webhook_entity.Subscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	ClaimPendingDeliveries(ctx context.Context, limit int, now time.Time, leaseUntil time.Time) ([]webhook_entity.Delivery, error)
+	RecordAttempt(ctx context.Context, delivery *webhook_entity.Delivery, attempt webhook_entity.Attempt, subscription *webhook_entity.Subscription, disableAfter int) error
+	GetAttempts(ctx context.Context, subscriptionId string, pagination common.Pagination) (int, []webhook_entity.Attempt, error)
+}
@@ -0,0 +1,484 @@
+package webhook_repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	conn *sql.DB
+}
+
+func NewWebhookRepository(conn *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		conn: conn,
+	}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *webhook_entity.Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, secret, event_types, active, consecutive_failures, disabled_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+
+	_, err := r.conn.ExecContext(ctx,
+		query,
+		subscription.Id,
+		subscription.Url,
+		subscription.Secret,
+		pq.Array(eventTypeNames(subscription.EventTypes)),
+		subscription.Active,
+		subscription.ConsecutiveFailures,
+		subscription.DisabledAt,
+		subscription.CreatedAt,
+		subscription.UpdatedAt)
+
+	return err
+}
+
+func (r *WebhookRepository) GetSubscriptionByID(ctx context.Context, id string) (webhook_entity.Subscription, error) {
+	query := `
+		SELECT id, url, secret, event_types, active, consecutive_failures, disabled_at, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE id = $1;
+	`
+
+	subscription, err := scanSubscription(r.conn.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return webhook_entity.Subscription{}, custom_error.ErrWebhookNotFound
+	}
+
+	return subscription, err
+}
+
+func (r *WebhookRepository) GetAllSubscriptions(ctx context.Context) ([]webhook_entity.Subscription, error) {
+	query := `
+		SELECT id, url, secret, event_types, active, consecutive_failures, disabled_at, created_at, updated_at
+		FROM webhook_subscriptions
+		ORDER BY created_at;
+	`
+
+	statement, err := r.conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	subscriptions := []webhook_entity.Subscription{}
+
+	for statement.Next() {
+		subscription, err := scanSubscription(statement)
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, subscription *webhook_entity.Subscription) error {
+	res, err := updateSubscription(ctx, r.conn, subscription)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return custom_error.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM webhook_subscriptions
+		WHERE id = $1;
+	`
+
+	res, err := r.conn.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return custom_error.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ClaimPendingDeliveries leases up to limit deliveries of active subscriptions
+// ready to be sent until leaseUntil, so concurrent dispatchers skip them
+func (r *WebhookRepository) ClaimPendingDeliveries(ctx context.Context, limit int, now time.Time, leaseUntil time.Time) ([]webhook_entity.Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			WHERE d.state = $2 AND d.next_attempt_at <= $3 AND s.active
+			ORDER BY d.created_at
+			LIMIT $4
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING id, subscription_id, event_id, event_type, payload, state, attempts, last_status_code, last_error, next_attempt_at, created_at, updated_at;
+	`
+
+	statement, err := r.conn.QueryContext(ctx,
+		query,
+		leaseUntil,
+		webhook_entity.Pending,
+		now,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	deliveries := []webhook_entity.Delivery{}
+
+	for statement.Next() {
+		delivery := webhook_entity.Delivery{}
+		err = statement.Scan(
+			&delivery.Id,
+			&delivery.SubscriptionId,
+			&delivery.EventId,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.State,
+			&delivery.Attempts,
+			&delivery.LastStatusCode,
+			&delivery.LastError,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt along with the
+// failure count of its subscription, disabling it once it fails disableAfter
+// times in a row. Only the failure fields are written, so an admin change made
+// during the dispatch is kept, and the subscription is refreshed with the
+// stored count
+func (r *WebhookRepository) RecordAttempt(
+	ctx context.Context,
+	delivery *webhook_entity.Delivery,
+	attempt webhook_entity.Attempt,
+	subscription *webhook_entity.Subscription,
+	disableAfter int,
+) error {
+	queryUpdateDelivery := `
+		UPDATE webhook_deliveries
+		SET state = $1, attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5, updated_at = $6
+		WHERE id = $7;
+	`
+
+	queryInsertAttempt := `
+		INSERT INTO webhook_delivery_attempts (id, delivery_id, subscription_id, event_id, event_type, number, state, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+	`
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		queryUpdateDelivery,
+		delivery.State,
+		delivery.Attempts,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.UpdatedAt,
+		delivery.Id)
+	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		queryInsertAttempt,
+		attempt.Id,
+		attempt.DeliveryId,
+		attempt.SubscriptionId,
+		attempt.EventId,
+		attempt.EventType,
+		attempt.Number,
+		attempt.State,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.DurationMs,
+		attempt.AttemptedAt)
+	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	if delivery.State == webhook_entity.Delivered {
+		err = resetFailures(ctx, tx, subscription, attempt.AttemptedAt)
+	} else {
+		err = recordFailure(ctx, tx, subscription, disableAfter, attempt.AttemptedAt)
+	}
+	if err != nil {
+		errTx := tx.Rollback()
+		if errTx != nil {
+			return errTx
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func resetFailures(ctx context.Context, tx *sql.Tx, subscription *webhook_entity.Subscription, now time.Time) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = 0, updated_at = $1
+		WHERE id = $2 AND consecutive_failures > 0;
+	`
+
+	if _, err := tx.ExecContext(ctx, query, now, subscription.Id); err != nil {
+		return err
+	}
+
+	subscription.RecordSuccess(now)
+
+	return nil
+}
+
+// recordFailure counts the failure on the stored subscription, so concurrent
+// dispatchers do not lose each other's, and disables it only if it is still
+// active
+func recordFailure(ctx context.Context, tx *sql.Tx, subscription *webhook_entity.Subscription, disableAfter int, now time.Time) error {
+	queryIncrement := `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = consecutive_failures + 1, updated_at = $1
+		WHERE id = $2
+		RETURNING consecutive_failures;
+	`
+
+	queryDisable := `
+		UPDATE webhook_subscriptions
+		SET active = false, disabled_at = $1, updated_at = $1
+		WHERE id = $2 AND active;
+	`
+
+	var consecutiveFailures int
+
+	err := tx.QueryRowContext(ctx, queryIncrement, now, subscription.Id).Scan(&consecutiveFailures)
+	if errors.Is(err, sql.ErrNoRows) {
+		// deleted meanwhile, there is nothing left to count
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	subscription.ConsecutiveFailures = consecutiveFailures
+	subscription.UpdatedAt = now
+
+	if consecutiveFailures < disableAfter {
+		return nil
+	}
+
+	res, err := tx.ExecContext(ctx, queryDisable, now, subscription.Id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows > 0 {
+		subscription.Disable(now)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetAttempts(ctx context.Context, subscriptionId string, pagination common.Pagination) (int, []webhook_entity.Attempt, error) {
+	queryCount := `
+		SELECT COUNT(id) FROM webhook_delivery_attempts
+		WHERE subscription_id = $1;
+	`
+
+	query := `
+		SELECT id, delivery_id, subscription_id, event_id, event_type, number, state, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE subscription_id = $1
+		ORDER BY attempted_at DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	skip := pagination.Page*pagination.Size - pagination.Size
+
+	var count int
+
+	if err := r.conn.QueryRowContext(ctx, queryCount, subscriptionId).Scan(&count); err != nil {
+		return 0, nil, err
+	}
+
+	statement, err := r.conn.QueryContext(ctx, query, subscriptionId, pagination.Size, skip)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer statement.Close()
+
+	attempts := []webhook_entity.Attempt{}
+
+	for statement.Next() {
+		attempt := webhook_entity.Attempt{}
+		err = statement.Scan(
+			&attempt.Id,
+			&attempt.DeliveryId,
+			&attempt.SubscriptionId,
+			&attempt.EventId,
+			&attempt.EventType,
+			&attempt.Number,
+			&attempt.State,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMs,
+			&attempt.AttemptedAt)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	return count, attempts, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// EnqueueDeliveries creates one pending delivery of the event for every active
+// subscription to its type, using either the connection or an open
+// transaction so the deliveries are stored atomically with the change
+func EnqueueDeliveries(ctx context.Context, conn execer, event webhook_entity.Event) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, state, attempts, last_status_code, last_error, next_attempt_at, created_at, updated_at)
+		SELECT gen_random_uuid(), id, $1, $2, $3, $4, 0, 0, '', $5, $5, $5
+		FROM webhook_subscriptions
+		WHERE active AND $2 = ANY(event_types);
+	`
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx,
+		query,
+		event.Id,
+		event.Type,
+		string(payload),
+		webhook_entity.Pending,
+		event.OccurredAt)
+
+	return err
+}
+
+// EnqueuePaymentChanged enqueues the deliveries of the new state of the payment
+func EnqueuePaymentChanged(ctx context.Context, conn execer, payment payment_entity.Payment) error {
+	payment.RefreshStateTitle()
+
+	return EnqueueDeliveries(ctx, conn, webhook_entity.NewEvent(webhook_entity.PaymentStateChanged, payment, payment.UpdatedAt))
+}
+
+func updateSubscription(ctx context.Context, conn execer, subscription *webhook_entity.Subscription) (sql.Result, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $1, secret = $2, event_types = $3, active = $4, consecutive_failures = $5, disabled_at = $6, updated_at = $7
+		WHERE id = $8;
+	`
+
+	return conn.ExecContext(ctx,
+		query,
+		subscription.Url,
+		subscription.Secret,
+		pq.Array(eventTypeNames(subscription.EventTypes)),
+		subscription.Active,
+		subscription.ConsecutiveFailures,
+		subscription.DisabledAt,
+		subscription.UpdatedAt,
+		subscription.Id)
+}
+
+func scanSubscription(row scanner) (webhook_entity.Subscription, error) {
+	subscription := webhook_entity.Subscription{}
+
+	var eventTypes []string
+
+	err := row.Scan(
+		&subscription.Id,
+		&subscription.Url,
+		&subscription.Secret,
+		pq.Array(&eventTypes),
+		&subscription.Active,
+		&subscription.ConsecutiveFailures,
+		&subscription.DisabledAt,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt)
+	if err != nil {
+		return webhook_entity.Subscription{}, err
+	}
+
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, webhook_entity.EventType(eventType))
+	}
+
+	return subscription, nil
+}
+
+func eventTypeNames(eventTypes []webhook_entity.EventType) []string {
+	names := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		names = append(names, string(eventType))
+	}
+
+	return names
+}
@@ -0,0 +1,529 @@
+package webhook_repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+var subscriptionColumns = []string{"id", "url", "secret", "event_types", "active", "consecutive_failures", "disabled_at", "created_at", "updated_at"}
+
+func TestCreateSubscription(t *testing.T) {
+	t.Run("Should create a subscription", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		mock.ExpectExec("INSERT INTO webhook_subscriptions").
+			WithArgs(subscription.Id, subscription.Url, subscription.Secret, `{"order.state_changed"}`, true, 0, nil, subscription.CreatedAt, subscription.UpdatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.CreateSubscription(ctx, &subscription)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetSubscriptionByID(t *testing.T) {
+	t.Run("Should return the subscription", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions").
+			WithArgs("id").
+			WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+				AddRow("id", "https://partner.com/hook", "secret", `{order.state_changed,payment.state_changed}`, true, 0, nil, now, now))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		res, err := repo.GetSubscriptionByID(ctx, "id")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, webhook_entity.Subscription{
+			Id:         "id",
+			Url:        "https://partner.com/hook",
+			Secret:     "secret",
+			EventTypes: []webhook_entity.EventType{webhook_entity.OrderStateChanged, webhook_entity.PaymentStateChanged},
+			Active:     true,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the subscription is not found", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions").
+			WithArgs("id").
+			WillReturnRows(sqlmock.NewRows(subscriptionColumns))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		_, err = repo.GetSubscriptionByID(ctx, "id")
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrWebhookNotFound)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAllSubscriptions(t *testing.T) {
+	t.Run("Should return all the subscriptions", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions").
+			WillReturnRows(sqlmock.NewRows(subscriptionColumns).
+				AddRow("id-1", "https://partner.com/hook", "secret", `{order.state_changed}`, true, 0, nil, now, now).
+				AddRow("id-2", "https://other.com/hook", "secret", `{payment.state_changed}`, false, 20, now, now, now))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		res, err := repo.GetAllSubscriptions(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.False(t, res[1].Active)
+		assert.Equal(t, &now, res[1].DisabledAt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	t.Run("Should update the subscription", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		mock.ExpectExec("UPDATE webhook_subscriptions").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.UpdateSubscription(ctx, &subscription)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the subscription is not found", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		mock.ExpectExec("UPDATE webhook_subscriptions").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.UpdateSubscription(ctx, &subscription)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrWebhookNotFound)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	t.Run("Should delete the subscription", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("DELETE FROM webhook_subscriptions").
+			WithArgs("id").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.DeleteSubscription(ctx, "id")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the subscription is not found", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectExec("DELETE FROM webhook_subscriptions").
+			WithArgs("id").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.DeleteSubscription(ctx, "id")
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrWebhookNotFound)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimPendingDeliveries(t *testing.T) {
+	t.Run("Should lease the pending deliveries", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+		leaseUntil := now.Add(time.Minute)
+
+		mock.ExpectQuery("UPDATE webhook_deliveries").
+			WithArgs(leaseUntil, webhook_entity.Pending, now, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "payload", "state", "attempts", "last_status_code", "last_error", "next_attempt_at", "created_at", "updated_at"}).
+				AddRow("id", "subscription_id", "event_id", "order.state_changed", "{}", webhook_entity.Pending, 0, 0, "", leaseUntil, now, now))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		res, err := repo.ClaimPendingDeliveries(ctx, 10, now, leaseUntil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []webhook_entity.Delivery{
+			{
+				Id:             "id",
+				SubscriptionId: "subscription_id",
+				EventId:        "event_id",
+				EventType:      webhook_entity.OrderStateChanged,
+				Payload:        "{}",
+				State:          webhook_entity.Pending,
+				NextAttemptAt:  leaseUntil,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			},
+		}, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should return error when the query fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		mock.ExpectQuery("UPDATE webhook_deliveries").
+			WillReturnError(assert.AnError)
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		res, err := repo.ClaimPendingDeliveries(ctx, 10, time.Now(), time.Now())
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecordAttempt(t *testing.T) {
+	t.Run("Should record the attempt in the same transaction", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := webhook_entity.Delivery{Id: "id", SubscriptionId: subscription.Id, State: webhook_entity.Pending}
+		delivery.MarkAsDelivered(200, now)
+
+		attempt := webhook_entity.NewAttempt(delivery, time.Second, now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WithArgs(webhook_entity.Delivered, 1, 200, "", delivery.NextAttemptAt, now, "id").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO webhook_delivery_attempts").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE webhook_subscriptions SET consecutive_failures = 0").
+			WithArgs(now, subscription.Id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.RecordAttempt(ctx, &delivery, attempt, &subscription, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should count the failure on the stored subscription", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := webhook_entity.Delivery{Id: "id", SubscriptionId: subscription.Id, State: webhook_entity.Pending}
+		delivery.MarkAsFailed(503, assert.AnError, 5, now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO webhook_delivery_attempts").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("UPDATE webhook_subscriptions SET consecutive_failures = consecutive_failures \\+ 1").
+			WithArgs(now, subscription.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"consecutive_failures"}).AddRow(2))
+		mock.ExpectCommit()
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.RecordAttempt(ctx, &delivery, webhook_entity.NewAttempt(delivery, time.Second, now), &subscription, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, subscription.ConsecutiveFailures)
+		assert.True(t, subscription.Active)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should disable the subscription when it fails too many times in a row", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := webhook_entity.Delivery{Id: "id", SubscriptionId: subscription.Id, State: webhook_entity.Pending}
+		delivery.MarkAsFailed(503, assert.AnError, 5, now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO webhook_delivery_attempts").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("UPDATE webhook_subscriptions SET consecutive_failures = consecutive_failures \\+ 1").
+			WillReturnRows(sqlmock.NewRows([]string{"consecutive_failures"}).AddRow(3))
+		mock.ExpectExec("UPDATE webhook_subscriptions SET active = false(.+)WHERE id = \\$2 AND active").
+			WithArgs(now, subscription.Id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.RecordAttempt(ctx, &delivery, webhook_entity.NewAttempt(delivery, time.Second, now), &subscription, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, subscription.Active)
+		assert.Equal(t, &now, subscription.DisabledAt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should not disable again a subscription disabled meanwhile", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := webhook_entity.Delivery{Id: "id", SubscriptionId: subscription.Id, State: webhook_entity.Pending}
+		delivery.MarkAsFailed(503, assert.AnError, 5, now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO webhook_delivery_attempts").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("UPDATE webhook_subscriptions SET consecutive_failures = consecutive_failures \\+ 1").
+			WillReturnRows(sqlmock.NewRows([]string{"consecutive_failures"}).AddRow(4))
+		mock.ExpectExec("UPDATE webhook_subscriptions SET active = false").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.RecordAttempt(ctx, &delivery, webhook_entity.NewAttempt(delivery, time.Second, now), &subscription, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, subscription.Active)
+		assert.Nil(t, subscription.DisabledAt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Should rollback when the attempt insert fails", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := webhook_entity.Delivery{Id: "id", SubscriptionId: subscription.Id, State: webhook_entity.Pending}
+		delivery.MarkAsDelivered(200, now)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO webhook_delivery_attempts").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		err = repo.RecordAttempt(ctx, &delivery, webhook_entity.NewAttempt(delivery, time.Second, now), &subscription, 3)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAttempts(t *testing.T) {
+	t.Run("Should return a page of the delivery log", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT COUNT(.+) FROM webhook_delivery_attempts").
+			WithArgs("subscription_id").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+		mock.ExpectQuery("SELECT (.+) FROM webhook_delivery_attempts").
+			WithArgs("subscription_id", int64(10), int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "delivery_id", "subscription_id", "event_id", "event_type", "number", "state", "status_code", "error", "duration_ms", "attempted_at"}).
+				AddRow("id", "delivery_id", "subscription_id", "event_id", "order.state_changed", 1, webhook_entity.Delivered, 200, "", 120, now))
+
+		repo := NewWebhookRepository(db)
+
+		// Act
+		count, res, err := repo.GetAttempts(ctx, "subscription_id", common.Pagination{Page: 2, Size: 10})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 11, count)
+		assert.Len(t, res, 1)
+		assert.Equal(t, 200, res[0].StatusCode)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestEnqueueDeliveries(t *testing.T) {
+	t.Run("Should enqueue the event for the subscriptions to its type", func(t *testing.T) {
+		// Arrange
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		ctx := context.Background()
+
+		event := webhook_entity.NewEvent(webhook_entity.OrderStateChanged, map[string]string{"order_id": "123"}, time.Now())
+
+		mock.ExpectExec("INSERT INTO webhook_deliveries (.+) FROM webhook_subscriptions").
+			WithArgs(event.Id, event.Type, sqlmock.AnyArg(), webhook_entity.Pending, event.OccurredAt).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		// Act
+		err = EnqueueDeliveries(ctx, db, event)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
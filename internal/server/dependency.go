@@ -6,21 +6,48 @@ import (
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
 	order_create_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/create"
 	order_get_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
+	order_get_all_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_all"
+	order_get_history_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_history"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	order_remove_item_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/remove_item"
 	order_update_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update"
+	order_update_item_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update_item"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/payment/send_to_pay"
+	webhook_create_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/create"
+	webhook_get_deliveries_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/get_deliveries"
+	webhook_remove_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/remove"
+	webhook_update_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/update"
 )
 
 type Dependency struct {
 	TimeProvider *time_provider.TimeProvider
 
-	OrderRepository   repository.OrderRepository
-	PaymentRepository repository.PaymentRepository
+	OrderRepository       repository.OrderRepository
+	PaymentRepository     repository.PaymentRepository
+	OutboxRepository      repository.OutboxRepository
+	InboxRepository       repository.InboxRepository
+	IdempotencyRepository repository.IdempotencyRepository
+	WebhookRepository     repository.WebhookRepository
 
 	CreateOrderService service.CreateOrderService[order_create_service.CreateOrderDto]
 	GetOrderService    service.GetOrderService[order_get_service.GetOrderDto]
+	GetOrdersService   service.GetOrdersService[order_get_all_service.GetOrdersDto]
+	GetHistoryService  service.GetOrderHistoryService[order_get_history_service.GetOrderHistoryDto]
 	UpdateOrderService service.UpdateOrderService[order_update_service.UpdateOrderDto]
+	RemoveItemService  service.UpdateOrderService[order_remove_item_service.RemoveOrderItemDto]
+	UpdateItemService  service.UpdateOrderService[order_update_item_service.UpdateOrderItemDto]
 	SendToPayService   service.SendToPayService[send_to_pay.SendToPayDto]
 
+	CreateWebhookService        service.CreateWebhookService[webhook_create_service.CreateWebhookDto]
+	GetWebhooksService          service.GetWebhooksService
+	UpdateWebhookService        service.UpdateWebhookService[webhook_update_service.UpdateWebhookDto]
+	DeleteWebhookService        service.DeleteWebhookService[webhook_remove_service.DeleteWebhookDto]
+	GetWebhookDeliveriesService service.GetWebhookDeliveriesService[webhook_get_deliveries_service.GetWebhookDeliveriesDto]
+
+	RelayOutboxService          service.RelayOutboxService
+	PurgeInboxService           service.PurgeInboxService
+	PurgeIdempotencyKeysService service.PurgeIdempotencyKeysService
+	DispatchWebhooksService     service.DispatchWebhooksService
+
 	ProcessMessageService service.ProcessMessageService[process.ProcessMessageDto]
 }
@@ -0,0 +1,316 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+)
+
+const maxJwksSize = 1024 * 1024
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+type KeySet interface {
+	Key(ctx context.Context, keyId string) (interface{}, error)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// StaticKeySet holds the keys of a JWKS document loaded once, like a local file
+type StaticKeySet struct {
+	keys map[string]interface{}
+}
+
+func NewFileKeySet(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := parseJwks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaticKeySet{
+		keys: keys,
+	}, nil
+}
+
+func (s *StaticKeySet) Key(ctx context.Context, keyId string) (interface{}, error) {
+	key, ok := s.keys[keyId]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// RemoteKeySet downloads the JWKS document from an url and keeps it for the
+// cache ttl, the document being reloaded on the first lookup after it expires
+// or for an unknown kid, as the issuer may have rotated its keys. Once keys are
+// cached the reloads are at least the refresh interval apart, and the cached
+// keys keep being used while the document can not be fetched
+type RemoteKeySet struct {
+	url             string
+	client          *http.Client
+	ttl             time.Duration
+	refreshInterval time.Duration
+	timeProvider    provider.TimeProvider
+
+	mutex     sync.Mutex
+	keys      map[string]interface{}
+	expiresAt time.Time
+	fetchedAt time.Time
+	inFlight  *keysFetch
+}
+
+// keysFetch is a fetch of the document shared by the lookups waiting for it
+type keysFetch struct {
+	done chan struct{}
+	keys map[string]interface{}
+	err  error
+}
+
+func NewRemoteKeySet(
+	url string,
+	client *http.Client,
+	ttl time.Duration,
+	refreshInterval time.Duration,
+	timeProvider provider.TimeProvider,
+) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:             url,
+		client:          client,
+		ttl:             ttl,
+		refreshInterval: refreshInterval,
+		timeProvider:    timeProvider,
+	}
+}
+
+func (s *RemoteKeySet) Key(ctx context.Context, keyId string) (interface{}, error) {
+	now := s.timeProvider.GetTime()
+
+	s.mutex.Lock()
+	keys := s.keys
+	expired := !now.Before(s.expiresAt)
+	throttled := now.Before(s.fetchedAt.Add(s.refreshInterval))
+	s.mutex.Unlock()
+
+	key, ok := keys[keyId]
+
+	if keys == nil || ((expired || !ok) && !throttled) {
+		fetched, err := s.refresh(ctx)
+		if err != nil {
+			if keys == nil {
+				return nil, err
+			}
+
+			slog.WarnContext(ctx, "error refreshing the JWKS, using the keys cached", "error", err)
+		} else {
+			key, ok = fetched[keyId]
+		}
+	}
+
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// refresh waits for the fetch in flight or starts one, which is not tied to
+// the context of the caller as other lookups may be waiting for it
+func (s *RemoteKeySet) refresh(ctx context.Context) (map[string]interface{}, error) {
+	s.mutex.Lock()
+
+	fetch := s.inFlight
+	if fetch == nil {
+		fetch = &keysFetch{
+			done: make(chan struct{}),
+		}
+
+		s.inFlight = fetch
+
+		go s.run(context.WithoutCancel(ctx), fetch)
+	}
+
+	s.mutex.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.keys, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *RemoteKeySet) run(ctx context.Context, fetch *keysFetch) {
+	keys, err := s.fetch(ctx)
+
+	now := s.timeProvider.GetTime()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.fetchedAt = now
+	s.inFlight = nil
+
+	if err == nil {
+		s.keys = keys
+		s.expiresAt = now.Add(s.ttl)
+	}
+
+	fetch.keys = keys
+	fetch.err = err
+
+	close(fetch.done)
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch the JWKS, status code %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxJwksSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJwks(data)
+}
+
+// parseJwks reads the RSA and P-256 EC signing keys of a JWKS document, keyed
+// by their kid. A key that can not be used is skipped, so the others still
+// verify tokens, the document only being rejected when it leaves no key
+func parseJwks(data []byte) (map[string]interface{}, error) {
+	var document jwks
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{})
+
+	skipped := 0
+
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var publicKey interface{}
+		var err error
+
+		switch key.Kty {
+		case "RSA":
+			publicKey, err = parseRsaKey(key)
+		case "EC":
+			publicKey, err = parseEcKey(key)
+		default:
+			continue
+		}
+
+		if err != nil {
+			slog.Warn("skipping invalid JWKS key", "kid", key.Kid, "kty", key.Kty, "error", err)
+			skipped++
+			continue
+		}
+
+		keys[key.Kid] = publicKey
+	}
+
+	if len(keys) == 0 && skipped > 0 {
+		return nil, fmt.Errorf("no usable signing key in the JWKS, %d invalid key(s) skipped", skipped)
+	}
+
+	return keys, nil
+}
+
+func parseRsaKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent out of range")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+func parseEcKey(key jwk) (*ecdsa.PublicKey, error) {
+	if key.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(key.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return publicKey, nil
+}
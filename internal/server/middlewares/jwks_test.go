@@ -0,0 +1,308 @@
+package token_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
+	token "github.com/jfelipearaujo-org/ms-order-management/internal/server/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKeySet(t *testing.T) {
+	t.Run("Should return error when the JWKS is not valid", func(t *testing.T) {
+		// Arrange
+		path := writeJwks(t, []byte("not a jwks"))
+
+		// Act
+		_, err := token.NewFileKeySet(path)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return error when an EC key is not on the curve", func(t *testing.T) {
+		// Arrange
+		path := writeJwks(t, []byte(`{"keys":[{"kty":"EC","kid":"ec-key","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+
+		// Act
+		_, err := token.NewFileKeySet(path)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should skip an invalid key and keep the others", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+
+		jwks := encodeJwks(t, rsaKey, ecKey)
+		jwks = append(jwks[:len(jwks)-2], []byte(`,{"kty":"EC","kid":"p384-key","crv":"P-384","x":"AQ","y":"AQ"}]}`)...)
+
+		keySet, err := token.NewFileKeySet(writeJwks(t, jwks))
+		assert.NoError(t, err)
+
+		// Act
+		key, err := keySet.Key(ctx, "rsa-key")
+		_, errSkipped := keySet.Key(ctx, "p384-key")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &rsaKey.PublicKey, key)
+		assert.ErrorIs(t, errSkipped, token.ErrKeyNotFound)
+	})
+
+	t.Run("Should ignore the keys not used for signing", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		path := writeJwks(t, []byte(`{"keys":[{"kty":"RSA","kid":"enc-key","use":"enc","n":"AQ","e":"AQAB"}]}`))
+
+		keySet, err := token.NewFileKeySet(path)
+		assert.NoError(t, err)
+
+		// Act
+		_, err = keySet.Key(ctx, "enc-key")
+
+		// Assert
+		assert.ErrorIs(t, err, token.ErrKeyNotFound)
+	})
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	jwks := encodeJwks(t, rsaKey, ecKey)
+
+	t.Run("Should cache the keys until the ttl expires", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			_, _ = w.Write(jwks)
+		}))
+		defer server.Close()
+
+		now := time.Now()
+		timeProvider := time_provider.NewTimeProvider(func() time.Time { return now })
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Minute, time.Second, timeProvider)
+
+		// Act
+		first, err := keySet.Key(ctx, "rsa-key")
+		assert.NoError(t, err)
+
+		_, err = keySet.Key(ctx, "ec-key")
+		assert.NoError(t, err)
+
+		callsBeforeExpiration := calls
+
+		now = now.Add(time.Minute)
+
+		second, err := keySet.Key(ctx, "rsa-key")
+		assert.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 1, callsBeforeExpiration)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, &rsaKey.PublicKey, first)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("Should return error when the key is not found", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(jwks)
+		}))
+		defer server.Close()
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Minute, time.Second, time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		_, err := keySet.Key(ctx, "another-key")
+
+		// Assert
+		assert.ErrorIs(t, err, token.ErrKeyNotFound)
+	})
+
+	t.Run("Should return error when the JWKS can not be fetched", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Minute, time.Second, time_provider.NewTimeProvider(time.Now))
+
+		// Act
+		_, err := keySet.Key(ctx, "rsa-key")
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should refetch the keys for an unknown kid after the refresh interval", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		// the issuer publishes the key once it is rotated in
+		documents := [][]byte{
+			[]byte(`{"keys":[]}`),
+			jwks,
+		}
+
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(documents[min(calls, len(documents)-1)])
+			calls++
+		}))
+		defer server.Close()
+
+		now := time.Now()
+		timeProvider := time_provider.NewTimeProvider(func() time.Time { return now })
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Hour, time.Second, timeProvider)
+
+		_, err := keySet.Key(ctx, "rsa-key")
+		assert.ErrorIs(t, err, token.ErrKeyNotFound)
+
+		_, err = keySet.Key(ctx, "rsa-key")
+		assert.ErrorIs(t, err, token.ErrKeyNotFound)
+
+		callsWithinInterval := calls
+
+		now = now.Add(time.Second)
+
+		// Act
+		key, err := keySet.Key(ctx, "rsa-key")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callsWithinInterval)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, &rsaKey.PublicKey, key)
+	})
+
+	t.Run("Should keep using the keys cached when the refetch fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		failing := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			_, _ = w.Write(jwks)
+		}))
+		defer server.Close()
+
+		now := time.Now()
+		timeProvider := time_provider.NewTimeProvider(func() time.Time { return now })
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Minute, time.Second, timeProvider)
+
+		_, err := keySet.Key(ctx, "rsa-key")
+		assert.NoError(t, err)
+
+		failing = true
+		now = now.Add(time.Minute)
+
+		// Act
+		key, err := keySet.Key(ctx, "rsa-key")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &rsaKey.PublicKey, key)
+	})
+
+	t.Run("Should share a fetch between concurrent lookups", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		var calls atomic.Int32
+		release := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			<-release
+			_, _ = w.Write(jwks)
+		}))
+		defer server.Close()
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Minute, time.Second, time_provider.NewTimeProvider(time.Now))
+
+		var waitGroup sync.WaitGroup
+		errs := make(chan error, 10)
+
+		// Act
+		for i := 0; i < 10; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+
+				_, err := keySet.Key(ctx, "rsa-key")
+				errs <- err
+			}()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		waitGroup.Wait()
+		close(errs)
+
+		// Assert
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Should stop waiting for the fetch when the context is done", func(t *testing.T) {
+		// Arrange
+		release := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		keySet := token.NewRemoteKeySet(server.URL, server.Client(), time.Minute, time.Second, time_provider.NewTimeProvider(time.Now))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Act
+		_, err := keySet.Key(ctx, "rsa-key")
+
+		// Assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -2,13 +2,15 @@ package token
 
 import (
 	"net/http"
-	"time"
+	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/audit"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 	"github.com/labstack/echo/v4"
 )
 
-func Middleware() echo.MiddlewareFunc {
+func Middleware(verifier *Verifier) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			tokenHeader := c.Request().Header.Get("Authorization")
@@ -16,28 +18,50 @@ func Middleware() echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Token is required")
 			}
 
-			tokenValue := tokenHeader[7:]
-			token, _, err := new(jwt.Parser).ParseUnverified(tokenValue, jwt.MapClaims{})
-			if err != nil {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+			scheme, tokenValue, ok := strings.Cut(tokenHeader, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(tokenValue) == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid authorization header")
 			}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
+			claims, err := verifier.Verify(c.Request().Context(), strings.TrimSpace(tokenValue))
+			if err != nil {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
 			}
 
-			expiration := claims["exp"].(float64)
-			if expiration < float64(time.Now().Unix()) {
+			userId, ok := claims["sub"].(string)
+			if !ok || userId == "" {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
 			}
 
-			userId, ok := claims["sub"].(string)
+			c.Set("userId", userId)
+
+			ctx := audit.WithOrigin(c.Request().Context(), audit.Origin{
+				Actor:    userId,
+				Source:   audit.SourceHttp,
+				SourceId: c.Request().Header.Get(echo.HeaderXRequestID),
+			})
+
+			ctx = auth.WithPrincipal(ctx, auth.NewPrincipal(userId, verifier.Roles(claims)))
+
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// RequireRole only lets through the principals having one of the roles
+func RequireRole(roles ...auth.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := auth.FromContext(c.Request().Context())
 			if !ok {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+				return echo.NewHTTPError(http.StatusUnauthorized, "Token is required")
 			}
 
-			c.Set("userId", userId)
+			if !principal.HasAnyRole(roles...) {
+				return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrForbidden)
+			}
 
 			return next(c)
 		}
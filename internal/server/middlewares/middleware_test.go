@@ -9,7 +9,11 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
 	token "github.com/jfelipearaujo-org/ms-order-management/internal/server/middlewares"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/audit"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,6 +38,29 @@ func generateToken(t *testing.T, userId string, expire time.Duration) string {
 	return fmt.Sprintf("Bearer %s", tokenString)
 }
 
+func newVerifier(t *testing.T) *token.Verifier {
+	verifier, err := token.NewVerifier(&environment.AuthConfig{
+		HmacSecret: "my-secret",
+		RolesClaim: "roles",
+	}, nil, time_provider.NewTimeProvider(time.Now))
+	assert.NoError(t, err)
+
+	return verifier
+}
+
+func generateTokenWithRoles(t *testing.T, userId string, roles ...string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   userId,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"roles": roles,
+	})
+
+	tokenString, err := token.SignedString([]byte("my-secret"))
+	assert.NoError(t, err)
+
+	return fmt.Sprintf("Bearer %s", tokenString)
+}
+
 func TestMiddleware(t *testing.T) {
 	t.Run("Should authorize when token is valid", func(t *testing.T) {
 		// Arrange
@@ -44,7 +71,7 @@ func TestMiddleware(t *testing.T) {
 		res := httptest.NewRecorder()
 
 		e := echo.New()
-		e.Use(token.Middleware())
+		e.Use(token.Middleware(newVerifier(t)))
 		e.GET("/", func(c echo.Context) error {
 			userId := c.Get("userId").(string)
 			return c.String(http.StatusOK, userId)
@@ -58,6 +85,64 @@ func TestMiddleware(t *testing.T) {
 		assert.Equal(t, userId, res.Body.String())
 	})
 
+	t.Run("Should set the request origin when token is valid", func(t *testing.T) {
+		// Arrange
+		userId := uuid.NewString()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", generateToken(t, userId, time.Minute*1))
+		req.Header.Set(echo.HeaderXRequestID, "request-id")
+		res := httptest.NewRecorder()
+
+		var origin audit.Origin
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.GET("/", func(c echo.Context) error {
+			origin = audit.FromContext(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, audit.Origin{
+			Actor:    userId,
+			Source:   audit.SourceHttp,
+			SourceId: "request-id",
+		}, origin)
+	})
+
+	t.Run("Should set the request principal when token is valid", func(t *testing.T) {
+		// Arrange
+		userId := uuid.NewString()
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", generateTokenWithRoles(t, userId, "staff"))
+		res := httptest.NewRecorder()
+
+		var principal auth.Principal
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.GET("/", func(c echo.Context) error {
+			principal, _ = auth.FromContext(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, auth.Principal{
+			UserId: userId,
+			Roles:  []auth.Role{auth.RoleStaff},
+		}, principal)
+	})
+
 	t.Run("Should not authorize when token is invalid", func(t *testing.T) {
 		// Arrange
 		req := httptest.NewRequest(echo.GET, "/", nil)
@@ -65,7 +150,7 @@ func TestMiddleware(t *testing.T) {
 		res := httptest.NewRecorder()
 
 		e := echo.New()
-		e.Use(token.Middleware())
+		e.Use(token.Middleware(newVerifier(t)))
 		e.GET("/", func(c echo.Context) error {
 			userId := c.Get("userId").(string)
 			return c.String(http.StatusOK, userId)
@@ -84,7 +169,7 @@ func TestMiddleware(t *testing.T) {
 		res := httptest.NewRecorder()
 
 		e := echo.New()
-		e.Use(token.Middleware())
+		e.Use(token.Middleware(newVerifier(t)))
 		e.GET("/", func(c echo.Context) error {
 			userId := c.Get("userId").(string)
 			return c.String(http.StatusOK, userId)
@@ -106,7 +191,7 @@ func TestMiddleware(t *testing.T) {
 		res := httptest.NewRecorder()
 
 		e := echo.New()
-		e.Use(token.Middleware())
+		e.Use(token.Middleware(newVerifier(t)))
 		e.GET("/", func(c echo.Context) error {
 			userId := c.Get("userId").(string)
 			return c.String(http.StatusOK, userId)
@@ -126,7 +211,7 @@ func TestMiddleware(t *testing.T) {
 		res := httptest.NewRecorder()
 
 		e := echo.New()
-		e.Use(token.Middleware())
+		e.Use(token.Middleware(newVerifier(t)))
 		e.GET("/", func(c echo.Context) error {
 			userId := c.Get("userId").(string)
 			return c.String(http.StatusOK, userId)
@@ -138,4 +223,148 @@ func TestMiddleware(t *testing.T) {
 		// Assert
 		assert.Equal(t, http.StatusUnauthorized, res.Code)
 	})
+
+	t.Run("Should not authorize when the header is too short", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", "Bearer")
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("Should not authorize when the scheme is not bearer", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("Should not authorize when the token is signed with another secret", func(t *testing.T) {
+		// Arrange
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": uuid.NewString(),
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}).SignedString([]byte("another-secret"))
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("Should not authorize when the token has no expiration", func(t *testing.T) {
+		// Arrange
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": uuid.NewString(),
+		}).SignedString([]byte("my-secret"))
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	t.Run("Should let through a principal having the role", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.PATCH, "/", nil)
+		req.Header.Set("Authorization", generateTokenWithRoles(t, uuid.NewString(), "admin"))
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.PATCH("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}, token.RequireRole(auth.RoleStaff, auth.RoleAdmin))
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("Should forbid a principal without the role", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.PATCH, "/", nil)
+		req.Header.Set("Authorization", generateToken(t, uuid.NewString(), time.Minute*1))
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.Use(token.Middleware(newVerifier(t)))
+		e.PATCH("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}, token.RequireRole(auth.RoleStaff, auth.RoleAdmin))
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Should not authorize when there is no principal", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(echo.PATCH, "/", nil)
+		res := httptest.NewRecorder()
+
+		e := echo.New()
+		e.PATCH("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}, token.RequireRole(auth.RoleStaff))
+
+		// Act
+		e.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
 }
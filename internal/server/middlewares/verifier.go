@@ -0,0 +1,125 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+)
+
+var ErrNoVerificationKey = errors.New("no token verification key configured, set an HMAC secret or a JWKS")
+
+type Verifier struct {
+	hmacSecret []byte
+	keySet     KeySet
+	rolesClaim string
+	parser     *jwt.Parser
+}
+
+func NewVerifier(config *environment.AuthConfig, keySet KeySet, timeProvider provider.TimeProvider) (*Verifier, error) {
+	methods := []string{}
+
+	if config.HmacSecret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if keySet != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoVerificationKey
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(config.ClockSkew),
+		jwt.WithTimeFunc(timeProvider.GetTime),
+	}
+
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &Verifier{
+		hmacSecret: []byte(config.HmacSecret),
+		keySet:     keySet,
+		rolesClaim: config.RolesClaim,
+		parser:     jwt.NewParser(options...),
+	}, nil
+}
+
+// NewKeySet returns the JWKS configured, preferring the local file over the url,
+// or nil when tokens are only verified with the HMAC secret
+func NewKeySet(config *environment.AuthConfig, timeProvider provider.TimeProvider) (KeySet, error) {
+	if config.JwksFile != "" {
+		keySet, err := NewFileKeySet(config.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return keySet, nil
+	}
+
+	if config.JwksUrl != "" {
+		client := &http.Client{
+			Timeout: 10 * time.Second,
+		}
+
+		return NewRemoteKeySet(config.JwksUrl, client, config.JwksCacheTtl, config.JwksRefreshInterval, timeProvider), nil
+	}
+
+	return nil, nil
+}
+
+// Verify checks the token signature and its exp, iat, nbf, iss and aud claims,
+// returning the claims of a valid token
+func (v *Verifier) Verify(ctx context.Context, tokenValue string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(tokenValue, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return v.hmacSecret, nil
+		default:
+			keyId, _ := token.Header["kid"].(string)
+			return v.keySet.Key(ctx, keyId)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Roles reads the roles claim, either a list or a space separated string
+func (v *Verifier) Roles(claims jwt.MapClaims) []string {
+	switch value := claims[v.rolesClaim].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		roles := []string{}
+
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+
+		return roles
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,334 @@
+package token_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
+	token "github.com/jfelipearaujo-org/ms-order-management/internal/server/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeJwks(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) []byte {
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-key",
+				"use": "sig",
+				"n":   encode(rsaKey.N),
+				"e":   encode(big.NewInt(int64(rsaKey.E))),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-key",
+				"crv": "P-256",
+				"x":   encode(ecKey.X),
+				"y":   encode(ecKey.Y),
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	return data
+}
+
+func writeJwks(t *testing.T, data []byte) string {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	err := os.WriteFile(path, data, 0600)
+	assert.NoError(t, err)
+
+	return path
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, keyId string, key interface{}, claims jwt.MapClaims) string {
+	tokenValue := jwt.NewWithClaims(method, claims)
+	if keyId != "" {
+		tokenValue.Header["kid"] = keyId
+	}
+
+	tokenString, err := tokenValue.SignedString(key)
+	assert.NoError(t, err)
+
+	return tokenString
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Run("Should return error when no key is configured", func(t *testing.T) {
+		// Act
+		_, err := token.NewVerifier(&environment.AuthConfig{}, nil, time_provider.NewTimeProvider(time.Now))
+
+		// Assert
+		assert.ErrorIs(t, err, token.ErrNoVerificationKey)
+	})
+}
+
+func TestRoles(t *testing.T) {
+	verifier, err := token.NewVerifier(&environment.AuthConfig{
+		HmacSecret: "my-secret",
+		RolesClaim: "roles",
+	}, nil, time_provider.NewTimeProvider(time.Now))
+	assert.NoError(t, err)
+
+	t.Run("Should read the roles from a space separated claim", func(t *testing.T) {
+		// Act
+		roles := verifier.Roles(jwt.MapClaims{"roles": "staff admin"})
+
+		// Assert
+		assert.Equal(t, []string{"staff", "admin"}, roles)
+	})
+
+	t.Run("Should read the roles from a list claim", func(t *testing.T) {
+		// Act
+		roles := verifier.Roles(jwt.MapClaims{"roles": []interface{}{"staff", 10, "admin"}})
+
+		// Assert
+		assert.Equal(t, []string{"staff", "admin"}, roles)
+	})
+
+	t.Run("Should return no roles when the claim is missing", func(t *testing.T) {
+		// Act
+		roles := verifier.Roles(jwt.MapClaims{})
+
+		// Assert
+		assert.Nil(t, roles)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	jwksPath := writeJwks(t, encodeJwks(t, rsaKey, ecKey))
+
+	keySet, err := token.NewFileKeySet(jwksPath)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	timeProvider := time_provider.NewTimeProvider(func() time.Time { return now })
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "user-id",
+			"iss": "issuer",
+			"aud": "orders",
+			"exp": now.Add(time.Minute).Unix(),
+		}
+	}
+
+	config := &environment.AuthConfig{
+		Issuer:    "issuer",
+		Audience:  "orders",
+		ClockSkew: 30 * time.Second,
+	}
+
+	t.Run("Should accept a RS256 token from the JWKS", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		tokenString := signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, validClaims())
+
+		// Act
+		claims, err := verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "user-id", claims["sub"])
+	})
+
+	t.Run("Should accept a ES256 token from the JWKS", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		tokenString := signToken(t, jwt.SigningMethodES256, "ec-key", ecKey, validClaims())
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject a token signed with an unknown key", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		tokenString := signToken(t, jwt.SigningMethodRS256, "another-key", rsaKey, validClaims())
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.ErrorIs(t, err, token.ErrKeyNotFound)
+	})
+
+	t.Run("Should reject a HS256 token when only the JWKS is configured", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		tokenString := signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims())
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("Should reject an unsigned token", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		tokenString := signToken(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims())
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should reject a token from another issuer", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		claims := validClaims()
+		claims["iss"] = "another-issuer"
+
+		tokenString := signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, claims)
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+
+	t.Run("Should reject a token for another audience", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		claims := validClaims()
+		claims["aud"] = "payments"
+
+		tokenString := signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, claims)
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("Should accept a token expired within the clock skew", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		claims := validClaims()
+		claims["exp"] = now.Add(-10 * time.Second).Unix()
+
+		tokenString := signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, claims)
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject a token expired beyond the clock skew", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		verifier, err := token.NewVerifier(config, keySet, timeProvider)
+		assert.NoError(t, err)
+
+		claims := validClaims()
+		claims["exp"] = now.Add(-time.Minute).Unix()
+
+		tokenString := signToken(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, claims)
+
+		// Act
+		_, err = verifier.Verify(ctx, tokenString)
+
+		// Assert
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+}
+
+func TestNewKeySet(t *testing.T) {
+	t.Run("Should return no key set when no JWKS is configured", func(t *testing.T) {
+		// Act
+		keySet, err := token.NewKeySet(&environment.AuthConfig{}, time_provider.NewTimeProvider(time.Now))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, keySet)
+	})
+
+	t.Run("Should return error when the JWKS file does not exist", func(t *testing.T) {
+		// Act
+		keySet, err := token.NewKeySet(&environment.AuthConfig{
+			JwksFile: filepath.Join(t.TempDir(), "missing.json"),
+		}, time_provider.NewTimeProvider(time.Now))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, keySet)
+	})
+
+	t.Run("Should return a remote key set when the JWKS url is configured", func(t *testing.T) {
+		// Act
+		keySet, err := token.NewKeySet(&environment.AuthConfig{
+			JwksUrl: "https://issuer/.well-known/jwks.json",
+		}, time_provider.NewTimeProvider(time.Now))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.IsType(t, &token.RemoteKeySet{}, keySet)
+	})
+}
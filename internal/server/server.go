@@ -2,41 +2,85 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/add_item"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/create"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/get_all"
 	get_by_id "github.com/jfelipearaujo-org/ms-order-management/internal/handler/get_by_id_or_track_id"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/get_history"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/health"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/payment"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/remove_item"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/track_events"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/update"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/handler/update_item"
+	webhook_create "github.com/jfelipearaujo-org/ms-order-management/internal/handler/webhook/create"
+	webhook_get_all "github.com/jfelipearaujo-org/ms-order-management/internal/handler/webhook/get_all"
+	webhook_get_deliveries "github.com/jfelipearaujo-org/ms-order-management/internal/handler/webhook/get_deliveries"
+	webhook_remove "github.com/jfelipearaujo-org/ms-order-management/internal/handler/webhook/remove"
+	webhook_update "github.com/jfelipearaujo-org/ms-order-management/internal/handler/webhook/update"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
+	idempotency_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/idempotency"
+	inbox_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/inbox"
 	order_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/order"
+	outbox_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/outbox"
 	payment_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/payment"
+	webhook_repository "github.com/jfelipearaujo-org/ms-order-management/internal/repository/webhook"
 	token "github.com/jfelipearaujo-org/ms-order-management/internal/server/middlewares"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service"
+	idempotency_purge "github.com/jfelipearaujo-org/ms-order-management/internal/service/idempotency/purge"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/inbox/purge"
 	order_create_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/create"
 	order_get_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get"
+	order_get_all_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_all"
+	order_get_history_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/get_history"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/order/process"
+	order_remove_item_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/remove_item"
 	order_update_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update"
+	order_update_item_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/order/update_item"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/outbox/relay"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/service/payment/send_to_pay"
+	webhook_create_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/create"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/dispatch"
+	webhook_get_all_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/get_all"
+	webhook_get_deliveries_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/get_deliveries"
+	webhook_remove_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/remove"
+	webhook_update_service "github.com/jfelipearaujo-org/ms-order-management/internal/service/webhook/update"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/idempotency"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/logger"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracing"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/webhook"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 type Server struct {
-	Config          *environment.Config
-	DatabaseService database.DatabaseService
-	TopicService    cloud.TopicService
-	QueueService    cloud.QueueService
+	Config            *environment.Config
+	DatabaseService   database.DatabaseService
+	TopicService      cloud.TopicService
+	EventTopicService cloud.TopicService
+	QueueService      cloud.QueueService
+	TokenVerifier     *token.Verifier
+	TrackingHub       *tracking.Hub
+
+	// NotificationVerifier is set when the signatures are verified, it trusts
+	// the PaymentResponseTopic once its arn is resolved unless an allowlist is
+	// configured
+	NotificationVerifier *cloud.SnsSignatureVerifier
+	PaymentResponseTopic *cloud.AwsSnsService
 
 	Dependency Dependency
 }
@@ -56,35 +100,238 @@ func NewServer(config *environment.Config) *Server {
 	databaseService := database.NewDatabase(config)
 
 	timeProvider := time_provider.NewTimeProvider(time.Now)
-	orderRepository := order_repository.NewOrderRepository(databaseService.GetInstance())
-	paymentRepository := payment_repository.NewPaymentRepository(databaseService.GetInstance())
+	orderRepository := order_repository.NewOrderRepository(databaseService.GetInstance(), config.CloudConfig.OrderEventsTopicName, timeProvider)
+	paymentRepository := payment_repository.NewPaymentRepository(databaseService.GetInstance(), config.CloudConfig.OrderEventsTopicName, timeProvider)
+	outboxRepository := outbox_repository.NewOutboxRepository(databaseService.GetInstance())
+	inboxRepository := inbox_repository.NewInboxRepository(databaseService.GetInstance())
+	idempotencyRepository := idempotency_repository.NewIdempotencyRepository(databaseService.GetInstance())
+	webhookRepository := webhook_repository.NewWebhookRepository(databaseService.GetInstance())
+
+	cloudEvents := cloud.CloudEventsOptions{
+		Enabled: config.CloudEventsConfig.Enabled,
+		Source:  config.CloudEventsConfig.Source,
+	}
+
+	paymentRules, err := process.ParsePaymentRules(config.OrderConfig.PaymentRules)
+	if err != nil {
+		panic(err)
+	}
+
+	keySet, err := token.NewKeySet(config.AuthConfig, timeProvider)
+	if err != nil {
+		panic(err)
+	}
+
+	tokenVerifier, err := token.NewVerifier(config.AuthConfig, keySet, timeProvider)
+	if err != nil {
+		panic(err)
+	}
+
+	// only the notifications delivered by SNS are signed
+	var notificationVerifier cloud.NotificationVerifier
+	var snsVerifier *cloud.SnsSignatureVerifier
+	var paymentResponseTopic *cloud.AwsSnsService
+	if config.BrokerConfig.IsAws() && config.QueueConfig.VerifySignature {
+		snsVerifier, err = newNotificationVerifier(config.QueueConfig)
+		if err != nil {
+			panic(err)
+		}
 
-	topicService := cloud.NewTopicService(config.CloudConfig.OrderPaymentTopicName, cloudConfig)
+		notificationVerifier = snsVerifier
 
-	messageProcessor := process.NewService(orderRepository, paymentRepository, timeProvider)
+		if len(config.QueueConfig.AllowedTopicArns) == 0 {
+			paymentResponseTopic = &cloud.AwsSnsService{
+				TopicName: config.CloudConfig.PaymentResponseTopicName,
+				Client:    sns.NewFromConfig(cloudConfig),
+			}
+		}
+	}
+
+	trackingHub := tracking.NewHub()
+
+	// with notify enabled the events reach the subscribers of every replica
+	// through the listener, otherwise only the ones of this process
+	var trackingPublisher tracking.Publisher = trackingHub
+	if config.TrackingConfig.NotifyEnabled {
+		trackingPublisher = tracking.NewPostgresPublisher(databaseService.GetInstance(), config.TrackingConfig.Channel)
+	}
+
+	messageProcessor := process.NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+	topicService, eventTopicService, queueService, err := newBroker(config, databaseService.GetInstance(), cloudEvents, cloudConfig, notificationVerifier, messageProcessor)
+	if err != nil {
+		panic(err)
+	}
 
 	return &Server{
-		Config:          config,
-		DatabaseService: databaseService,
-		TopicService:    topicService,
-		QueueService:    cloud.NewQueueService(config.CloudConfig.UpdateOrderQueueName, cloudConfig, messageProcessor),
+		Config:            config,
+		DatabaseService:   databaseService,
+		TopicService:      topicService,
+		EventTopicService: eventTopicService,
+		TokenVerifier:     tokenVerifier,
+		TrackingHub:       trackingHub,
+		QueueService:      queueService,
+
+		NotificationVerifier: snsVerifier,
+		PaymentResponseTopic: paymentResponseTopic,
 
 		Dependency: Dependency{
 			TimeProvider: timeProvider,
 
-			OrderRepository:   orderRepository,
-			PaymentRepository: paymentRepository,
+			OrderRepository:       orderRepository,
+			PaymentRepository:     paymentRepository,
+			OutboxRepository:      outboxRepository,
+			InboxRepository:       inboxRepository,
+			IdempotencyRepository: idempotencyRepository,
+			WebhookRepository:     webhookRepository,
 
 			CreateOrderService: order_create_service.NewService(orderRepository, timeProvider),
 			GetOrderService:    order_get_service.NewService(orderRepository),
-			UpdateOrderService: order_update_service.NewService(orderRepository, timeProvider),
-			SendToPayService:   send_to_pay.NewService(topicService, paymentRepository, timeProvider),
+			GetOrdersService:   order_get_all_service.NewService(orderRepository),
+			GetHistoryService:  order_get_history_service.NewService(orderRepository),
+			UpdateOrderService: order_update_service.NewService(orderRepository, trackingPublisher, timeProvider),
+			RemoveItemService:  order_remove_item_service.NewService(orderRepository, timeProvider),
+			UpdateItemService:  order_update_item_service.NewService(orderRepository, timeProvider),
+			SendToPayService:   send_to_pay.NewService(topicService, paymentRepository, outboxRepository, timeProvider),
+
+			CreateWebhookService:        webhook_create_service.NewService(webhookRepository, timeProvider),
+			GetWebhooksService:          webhook_get_all_service.NewService(webhookRepository),
+			UpdateWebhookService:        webhook_update_service.NewService(webhookRepository, timeProvider),
+			DeleteWebhookService:        webhook_remove_service.NewService(webhookRepository),
+			GetWebhookDeliveriesService: webhook_get_deliveries_service.NewService(webhookRepository),
+
+			RelayOutboxService: relay.NewService(
+				outboxRepository,
+				[]cloud.TopicService{topicService, eventTopicService},
+				timeProvider,
+				config.OutboxConfig.BatchSize,
+				config.OutboxConfig.MaxAttempts,
+			),
+			PurgeInboxService:           purge.NewService(inboxRepository, timeProvider, config.InboxConfig.Retention),
+			PurgeIdempotencyKeysService: idempotency_purge.NewService(idempotencyRepository, timeProvider),
+			DispatchWebhooksService: dispatch.NewService(
+				webhookRepository,
+				webhook.NewHttpSender(config.WebhookConfig.Timeout, timeProvider),
+				timeProvider,
+				config.WebhookConfig.BatchSize,
+				config.WebhookConfig.MaxAttempts,
+				config.WebhookConfig.DisableAfter,
+			),
 
 			ProcessMessageService: messageProcessor,
 		},
 	}
 }
 
+// newBroker builds the topics and the queue on the configured broker: AWS by
+// default, the database for deployments without AWS, in memory or in a
+// directory for tests and offline development
+func newBroker(
+	config *environment.Config,
+	conn *sql.DB,
+	cloudEvents cloud.CloudEventsOptions,
+	cloudConfig aws.Config,
+	verifier cloud.NotificationVerifier,
+	messageProcessor service.ProcessMessageService[process.ProcessMessageDto],
+) (cloud.TopicService, cloud.TopicService, cloud.QueueService, error) {
+	retryPolicy := cloud.RetryPolicy{
+		MaxReceives: config.QueueConfig.MaxReceives,
+		BaseDelay:   config.QueueConfig.RetryBaseDelay,
+		MaxDelay:    config.QueueConfig.RetryMaxDelay,
+	}
+
+	switch config.BrokerConfig.Driver {
+	case environment.BrokerDriverAws:
+		return cloud.NewTopicService(config.CloudConfig.OrderPaymentTopicName, cloudEvents, cloudConfig),
+			cloud.NewTopicService(config.CloudConfig.OrderEventsTopicName, cloudEvents, cloudConfig),
+			cloud.NewQueueService(
+				config.CloudConfig.UpdateOrderQueueName,
+				config.CloudConfig.UpdateOrderDLQName,
+				retryPolicy,
+				config.QueueConfig.Concurrency,
+				verifier,
+				cloudConfig,
+				messageProcessor,
+			),
+			nil
+	case environment.BrokerDriverMemory:
+		broker := cloud.NewMemoryBroker()
+
+		return cloud.NewMemoryTopicService(config.CloudConfig.OrderPaymentTopicName, cloudEvents, broker),
+			cloud.NewMemoryTopicService(config.CloudConfig.OrderEventsTopicName, cloudEvents, broker),
+			cloud.NewMemoryQueueService(
+				broker,
+				config.CloudConfig.UpdateOrderQueueName,
+				config.CloudConfig.UpdateOrderDLQName,
+				retryPolicy,
+				config.QueueConfig.Concurrency,
+				verifier,
+				messageProcessor,
+			),
+			nil
+	case environment.BrokerDriverFile:
+		broker := cloud.NewFileBroker(config.BrokerConfig.Directory)
+
+		return cloud.NewFileTopicService(config.CloudConfig.OrderPaymentTopicName, cloudEvents, broker),
+			cloud.NewFileTopicService(config.CloudConfig.OrderEventsTopicName, cloudEvents, broker),
+			cloud.NewFileQueueService(
+				broker,
+				config.CloudConfig.UpdateOrderQueueName,
+				config.CloudConfig.UpdateOrderDLQName,
+				retryPolicy,
+				config.QueueConfig.Concurrency,
+				verifier,
+				messageProcessor,
+			),
+			nil
+	case environment.BrokerDriverPostgres:
+		subscriptions, err := cloud.ParseSubscriptions(config.BrokerConfig.Subscriptions)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		broker := cloud.NewPostgresBroker(conn, config.BrokerConfig.VisibilityTimeout, config.BrokerConfig.PollInterval)
+
+		for topicName, queueNames := range subscriptions {
+			for _, queueName := range queueNames {
+				broker.Subscribe(topicName, queueName)
+			}
+		}
+
+		return cloud.NewPostgresTopicService(config.CloudConfig.OrderPaymentTopicName, cloudEvents, broker),
+			cloud.NewPostgresTopicService(config.CloudConfig.OrderEventsTopicName, cloudEvents, broker),
+			cloud.NewPostgresQueueService(
+				broker,
+				config.CloudConfig.UpdateOrderQueueName,
+				config.CloudConfig.UpdateOrderDLQName,
+				retryPolicy,
+				config.QueueConfig.Concurrency,
+				verifier,
+				messageProcessor,
+			),
+			nil
+	}
+
+	return nil, nil, nil, fmt.Errorf("unknown broker driver %q", config.BrokerConfig.Driver)
+}
+
+func newNotificationVerifier(config *environment.QueueConfig) (*cloud.SnsSignatureVerifier, error) {
+	if config.SigningCertBundle != "" {
+		fetcher, err := cloud.NewFileCertificateFetcher(config.SigningCertBundle)
+		if err != nil {
+			return nil, err
+		}
+
+		return cloud.NewSnsSignatureVerifier(fetcher, config.AllowedTopicArns), nil
+	}
+
+	fetcher := cloud.NewHttpCertificateFetcher(&http.Client{
+		Timeout: 10 * time.Second,
+	})
+
+	return cloud.NewSnsSignatureVerifier(fetcher, config.AllowedTopicArns), nil
+}
+
 func (s *Server) GetHttpServer() *http.Server {
 	return &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.Config.ApiConfig.Port),
@@ -95,16 +342,40 @@ func (s *Server) GetHttpServer() *http.Server {
 	}
 }
 
+// GetWorkerHttpServer serves the health check of a worker on its own port,
+// apart from the api
+func (s *Server) GetWorkerHttpServer() *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", s.Config.WorkerConfig.HealthPort),
+		Handler:      s.RegisterWorkerRoutes(),
+		IdleTimeout:  time.Minute,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+func (s *Server) RegisterWorkerRoutes() http.Handler {
+	e := echo.New()
+	e.Use(logger.Middleware())
+	e.Use(middleware.Recover())
+
+	s.registerHealthCheck(e)
+
+	return e
+}
+
 func (s *Server) RegisterRoutes() http.Handler {
 	e := echo.New()
 	e.Use(logger.Middleware())
 	e.Use(middleware.Recover())
+	e.Use(tracing.Middleware("/health"))
 
 	s.registerHealthCheck(e)
 
 	group := e.Group(fmt.Sprintf("/api/%s", s.Config.ApiConfig.ApiVersion))
 
 	s.registerOrderHandlers(group)
+	s.registerWebhookHandlers(group)
 
 	return e
 }
@@ -118,16 +389,48 @@ func (server *Server) registerHealthCheck(e *echo.Echo) {
 func (s *Server) registerOrderHandlers(e *echo.Group) {
 	createOrderHandler := create.NewHandler(s.Dependency.CreateOrderService)
 	addOrderItemHandler := add_item.NewHandler(s.Dependency.GetOrderService, s.Dependency.UpdateOrderService)
+	removeOrderItemHandler := remove_item.NewHandler(s.Dependency.GetOrderService, s.Dependency.RemoveItemService)
+	updateOrderItemHandler := update_item.NewHandler(s.Dependency.GetOrderService, s.Dependency.UpdateItemService)
 	getOrderByIdOrTrackIdHandler := get_by_id.NewHandler(s.Dependency.GetOrderService)
+	getOrdersHandler := get_all.NewHandler(s.Dependency.GetOrdersService)
+	getOrderHistoryHandler := get_history.NewHandler(s.Dependency.GetHistoryService)
 	sendToPaymentHandler := payment.NewHandler(s.Dependency.SendToPayService, s.Dependency.GetOrderService)
 	updateOrderHandler := update.NewHandler(s.Dependency.GetOrderService, s.Dependency.UpdateOrderService)
+	trackEventsHandler := track_events.NewHandler(s.Dependency.GetOrderService, s.TrackingHub, s.Config.TrackingConfig.Heartbeat)
 
-	e.Use(token.Middleware())
-	e.POST("/orders", createOrderHandler.Handle)
-	e.POST("/orders/:id/items", addOrderItemHandler.Handle)
+	idempotent := idempotency.Middleware(
+		s.Dependency.IdempotencyRepository,
+		s.Dependency.TimeProvider,
+		s.Config.IdempotencyConfig.Ttl,
+		s.Config.IdempotencyConfig.LockTimeout,
+	)
+
+	e.Use(token.Middleware(s.TokenVerifier))
+	e.POST("/orders", createOrderHandler.Handle, idempotent)
+	e.GET("/orders", getOrdersHandler.Handle)
+	e.POST("/orders/:id/items", addOrderItemHandler.Handle, idempotent)
+	e.DELETE("/orders/:id/items/:item_id", removeOrderItemHandler.Handle)
+	e.PATCH("/orders/:id/items/:item_id", updateOrderItemHandler.Handle)
 	e.GET("/orders/:id", getOrderByIdOrTrackIdHandler.Handle)
+	e.GET("/orders/:id/history", getOrderHistoryHandler.Handle)
 	e.GET("/orders/tracking/:track_id", getOrderByIdOrTrackIdHandler.Handle)
+	e.GET("/orders/tracking/:track_id/events", trackEventsHandler.Handle)
 	e.GET("/orders/customer", getOrderByIdOrTrackIdHandler.Handle)
-	e.POST("/orders/:order_id/payment", sendToPaymentHandler.Handle)
+	e.POST("/orders/:order_id/payment", sendToPaymentHandler.Handle, idempotent)
 	e.PATCH("/orders/:id", updateOrderHandler.Handle)
 }
+
+func (s *Server) registerWebhookHandlers(e *echo.Group) {
+	createWebhookHandler := webhook_create.NewHandler(s.Dependency.CreateWebhookService)
+	getWebhooksHandler := webhook_get_all.NewHandler(s.Dependency.GetWebhooksService)
+	updateWebhookHandler := webhook_update.NewHandler(s.Dependency.UpdateWebhookService)
+	removeWebhookHandler := webhook_remove.NewHandler(s.Dependency.DeleteWebhookService)
+	getWebhookDeliveriesHandler := webhook_get_deliveries.NewHandler(s.Dependency.GetWebhookDeliveriesService)
+
+	webhooks := e.Group("/webhooks", token.RequireRole(auth.RoleAdmin))
+	webhooks.POST("", createWebhookHandler.Handle)
+	webhooks.GET("", getWebhooksHandler.Handle)
+	webhooks.PATCH("/:id", updateWebhookHandler.Handle)
+	webhooks.DELETE("/:id", removeWebhookHandler.Handle)
+	webhooks.GET("/:id/deliveries", getWebhookDeliveriesHandler.Handle)
+}
@@ -1,8 +1,15 @@
 package server
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/database"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,9 +21,53 @@ func TestNewServer(t *testing.T) {
 			ApiConfig: &environment.ApiConfig{
 				Port: 8080,
 			},
+			WorkerConfig: &environment.WorkerConfig{
+				HealthPort: 8081,
+			},
 			DbConfig: &environment.DatabaseConfig{
 				Url: "postgres://host:1234",
 			},
+			SecretConfig: &environment.SecretConfig{
+				Providers: []string{"aws"},
+			},
+			AuthConfig: &environment.AuthConfig{
+				HmacSecret: "secret",
+			},
+			OrderConfig: &environment.OrderConfig{
+				PaymentRules: "Approved:Received",
+			},
+			OutboxConfig: &environment.OutboxConfig{
+				BatchSize:   10,
+				MaxAttempts: 10,
+			},
+			InboxConfig: &environment.InboxConfig{
+				Retention: time.Hour,
+			},
+			IdempotencyConfig: &environment.IdempotencyConfig{
+				Ttl: time.Hour,
+			},
+			TrackingConfig: &environment.TrackingConfig{
+				Channel:   "order_tracking",
+				Heartbeat: 15 * time.Second,
+			},
+			WebhookConfig: &environment.WebhookConfig{
+				Timeout: 10 * time.Second,
+			},
+			QueueConfig: &environment.QueueConfig{
+				MaxReceives: 5,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver: "aws",
+			},
+			CloudEventsConfig: &environment.CloudEventsConfig{
+				Source: "ms-order-management",
+			},
+			TracingConfig: &environment.TracingConfig{
+				Exporter:    "none",
+				File:        "traces.json",
+				ServiceName: "ms-order-management",
+				SampleRatio: 1,
+			},
 			CloudConfig: &environment.CloudConfig{
 				OrderPaymentTopicName: "order-payment-topic",
 				UpdateOrderQueueName:  "update-order-queue",
@@ -36,9 +87,53 @@ func TestNewServer(t *testing.T) {
 			ApiConfig: &environment.ApiConfig{
 				Port: 8080,
 			},
+			WorkerConfig: &environment.WorkerConfig{
+				HealthPort: 8081,
+			},
 			DbConfig: &environment.DatabaseConfig{
 				Url: "postgres://host:1234",
 			},
+			SecretConfig: &environment.SecretConfig{
+				Providers: []string{"aws"},
+			},
+			AuthConfig: &environment.AuthConfig{
+				HmacSecret: "secret",
+			},
+			OrderConfig: &environment.OrderConfig{
+				PaymentRules: "Approved:Received",
+			},
+			OutboxConfig: &environment.OutboxConfig{
+				BatchSize:   10,
+				MaxAttempts: 10,
+			},
+			InboxConfig: &environment.InboxConfig{
+				Retention: time.Hour,
+			},
+			IdempotencyConfig: &environment.IdempotencyConfig{
+				Ttl: time.Hour,
+			},
+			TrackingConfig: &environment.TrackingConfig{
+				Channel:   "order_tracking",
+				Heartbeat: 15 * time.Second,
+			},
+			WebhookConfig: &environment.WebhookConfig{
+				Timeout: 10 * time.Second,
+			},
+			QueueConfig: &environment.QueueConfig{
+				MaxReceives: 5,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver: "aws",
+			},
+			CloudEventsConfig: &environment.CloudEventsConfig{
+				Source: "ms-order-management",
+			},
+			TracingConfig: &environment.TracingConfig{
+				Exporter:    "none",
+				File:        "traces.json",
+				ServiceName: "ms-order-management",
+				SampleRatio: 1,
+			},
 			CloudConfig: &environment.CloudConfig{
 				OrderPaymentTopicName: "order-payment-topic",
 				UpdateOrderQueueName:  "update-order-queue",
@@ -59,9 +154,53 @@ func TestNewServer(t *testing.T) {
 			ApiConfig: &environment.ApiConfig{
 				Port: 8080,
 			},
+			WorkerConfig: &environment.WorkerConfig{
+				HealthPort: 8081,
+			},
 			DbConfig: &environment.DatabaseConfig{
 				Url: "postgres://host:1234",
 			},
+			SecretConfig: &environment.SecretConfig{
+				Providers: []string{"aws"},
+			},
+			AuthConfig: &environment.AuthConfig{
+				HmacSecret: "secret",
+			},
+			OrderConfig: &environment.OrderConfig{
+				PaymentRules: "Approved:Received",
+			},
+			OutboxConfig: &environment.OutboxConfig{
+				BatchSize:   10,
+				MaxAttempts: 10,
+			},
+			InboxConfig: &environment.InboxConfig{
+				Retention: time.Hour,
+			},
+			IdempotencyConfig: &environment.IdempotencyConfig{
+				Ttl: time.Hour,
+			},
+			TrackingConfig: &environment.TrackingConfig{
+				Channel:   "order_tracking",
+				Heartbeat: 15 * time.Second,
+			},
+			WebhookConfig: &environment.WebhookConfig{
+				Timeout: 10 * time.Second,
+			},
+			QueueConfig: &environment.QueueConfig{
+				MaxReceives: 5,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver: "aws",
+			},
+			CloudEventsConfig: &environment.CloudEventsConfig{
+				Source: "ms-order-management",
+			},
+			TracingConfig: &environment.TracingConfig{
+				Exporter:    "none",
+				File:        "traces.json",
+				ServiceName: "ms-order-management",
+				SampleRatio: 1,
+			},
 			CloudConfig: &environment.CloudConfig{
 				OrderPaymentTopicName: "order-payment-topic",
 				UpdateOrderQueueName:  "update-order-queue",
@@ -77,4 +216,170 @@ func TestNewServer(t *testing.T) {
 		assert.NotNil(t, httpServer)
 		assert.Equal(t, ":8080", httpServer.Addr)
 	})
+
+	t.Run("Should create a worker http server serving the health check", func(t *testing.T) {
+		// Arrange
+		config := &environment.Config{
+			ApiConfig: &environment.ApiConfig{
+				Port: 8080,
+			},
+			WorkerConfig: &environment.WorkerConfig{
+				HealthPort: 8081,
+			},
+			DbConfig: &environment.DatabaseConfig{
+				Url: "postgres://host:1234",
+			},
+			SecretConfig: &environment.SecretConfig{
+				Providers: []string{"aws"},
+			},
+			AuthConfig: &environment.AuthConfig{
+				HmacSecret: "secret",
+			},
+			OrderConfig: &environment.OrderConfig{
+				PaymentRules: "Approved:Received",
+			},
+			OutboxConfig: &environment.OutboxConfig{
+				BatchSize:   10,
+				MaxAttempts: 10,
+			},
+			InboxConfig: &environment.InboxConfig{
+				Retention: time.Hour,
+			},
+			IdempotencyConfig: &environment.IdempotencyConfig{
+				Ttl: time.Hour,
+			},
+			TrackingConfig: &environment.TrackingConfig{
+				Channel:   "order_tracking",
+				Heartbeat: 15 * time.Second,
+			},
+			WebhookConfig: &environment.WebhookConfig{
+				Timeout: 10 * time.Second,
+			},
+			QueueConfig: &environment.QueueConfig{
+				MaxReceives: 5,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver: "aws",
+			},
+			CloudEventsConfig: &environment.CloudEventsConfig{
+				Source: "ms-order-management",
+			},
+			TracingConfig: &environment.TracingConfig{
+				Exporter:    "none",
+				File:        "traces.json",
+				ServiceName: "ms-order-management",
+				SampleRatio: 1,
+			},
+			CloudConfig: &environment.CloudConfig{
+				OrderPaymentTopicName: "order-payment-topic",
+				UpdateOrderQueueName:  "update-order-queue",
+			},
+		}
+
+		server := NewServer(config)
+
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectPing()
+
+		server.DatabaseService.(*database.Service).Client = db
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		rec := httptest.NewRecorder()
+
+		// Act
+		httpServer := server.GetWorkerHttpServer()
+		httpServer.Handler.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Equal(t, ":8081", httpServer.Addr)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewBroker(t *testing.T) {
+	newConfig := func(driver string) *environment.Config {
+		return &environment.Config{
+			QueueConfig: &environment.QueueConfig{
+				MaxReceives: 5,
+			},
+			BrokerConfig: &environment.BrokerConfig{
+				Driver:    driver,
+				Directory: t.TempDir(),
+			},
+			CloudConfig: &environment.CloudConfig{
+				OrderPaymentTopicName: "order-payment-topic",
+				OrderEventsTopicName:  "order-events-topic",
+				UpdateOrderQueueName:  "update-order-queue",
+			},
+		}
+	}
+
+	t.Run("Should build the services on the in-memory broker", func(t *testing.T) {
+		// Arrange
+		config := newConfig("memory")
+
+		// Act
+		topic, eventTopic, queue, err := newBroker(config, nil, cloud.CloudEventsOptions{}, aws.Config{}, nil, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.IsType(t, &cloud.MemoryTopicService{}, topic)
+		assert.Equal(t, "order-events-topic", eventTopic.GetTopicName())
+		assert.IsType(t, &cloud.MemoryQueueService{}, queue)
+	})
+
+	t.Run("Should build the services on the file broker", func(t *testing.T) {
+		// Arrange
+		config := newConfig("file")
+
+		// Act
+		topic, eventTopic, queue, err := newBroker(config, nil, cloud.CloudEventsOptions{}, aws.Config{}, nil, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.IsType(t, &cloud.FileTopicService{}, topic)
+		assert.Equal(t, "order-events-topic", eventTopic.GetTopicName())
+		assert.IsType(t, &cloud.FileQueueService{}, queue)
+	})
+
+	t.Run("Should build the services on the postgres broker", func(t *testing.T) {
+		// Arrange
+		config := newConfig("postgres")
+
+		// Act
+		topic, eventTopic, queue, err := newBroker(config, nil, cloud.CloudEventsOptions{}, aws.Config{}, nil, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.IsType(t, &cloud.PostgresTopicService{}, topic)
+		assert.Equal(t, "order-events-topic", eventTopic.GetTopicName())
+		assert.IsType(t, &cloud.PostgresQueueService{}, queue)
+	})
+
+	t.Run("Should return error when the broker subscriptions are not valid", func(t *testing.T) {
+		// Arrange
+		config := newConfig("postgres")
+		config.BrokerConfig.Subscriptions = []string{"order-payment-topic"}
+
+		// Act
+		_, _, _, err := newBroker(config, nil, cloud.CloudEventsOptions{}, aws.Config{}, nil, nil)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Should return error when the driver is unknown", func(t *testing.T) {
+		// Arrange
+		config := newConfig("kafka")
+
+		// Act
+		_, _, _, err := newBroker(config, nil, cloud.CloudEventsOptions{}, aws.Config{}, nil, nil)
+
+		// Assert
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,38 @@
+package purge
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository   repository.IdempotencyRepository
+	timeProvider provider.TimeProvider
+}
+
+func NewService(
+	repository repository.IdempotencyRepository,
+	timeProvider provider.TimeProvider,
+) *Service {
+	return &Service{
+		repository:   repository,
+		timeProvider: timeProvider,
+	}
+}
+
+// Handle deletes the expired idempotency keys, returning how many were removed
+func (s *Service) Handle(ctx context.Context) (int64, error) {
+	now := s.timeProvider.GetTime()
+
+	deleted, err := s.repository.DeleteExpiredBefore(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	slog.InfoContext(ctx, "idempotency keys purged", "before", now, "deleted", deleted)
+
+	return deleted, nil
+}
@@ -0,0 +1,71 @@
+package purge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	provider_mock "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should delete the expired keys", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockIdempotencyRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		repository.On("DeleteExpiredBefore", ctx, now).
+			Return(int64(2), nil).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		// Act
+		deleted, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the delete fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockIdempotencyRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		repository.On("DeleteExpiredBefore", ctx, now).
+			Return(int64(0), assert.AnError).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		// Act
+		deleted, err := service.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, int64(0), deleted)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+}
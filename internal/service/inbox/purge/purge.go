@@ -0,0 +1,43 @@
+package purge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository   repository.InboxRepository
+	timeProvider provider.TimeProvider
+	retention    time.Duration
+}
+
+func NewService(
+	repository repository.InboxRepository,
+	timeProvider provider.TimeProvider,
+	retention time.Duration,
+) *Service {
+	return &Service{
+		repository:   repository,
+		timeProvider: timeProvider,
+		retention:    retention,
+	}
+}
+
+// Handle deletes the processed messages older than the retention, returning how
+// many were removed
+func (s *Service) Handle(ctx context.Context) (int64, error) {
+	before := s.timeProvider.GetTime().Add(-s.retention)
+
+	deleted, err := s.repository.DeleteProcessedBefore(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	slog.InfoContext(ctx, "inbox purged", "before", before, "deleted", deleted)
+
+	return deleted, nil
+}
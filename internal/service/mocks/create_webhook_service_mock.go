@@ -0,0 +1,60 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	webhook_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+)
+
+// MockCreateWebhookService is an autogenerated mock type for the CreateWebhookService type
+type MockCreateWebhookService[T interface{}] struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: ctx, request
+func (_m *MockCreateWebhookService[T]) Handle(ctx context.Context, request T) (*webhook_entity.Subscription, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 *webhook_entity.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) (*webhook_entity.Subscription, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, T) *webhook_entity.Subscription); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*webhook_entity.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, T) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockCreateWebhookService creates a new instance of MockCreateWebhookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCreateWebhookService[T interface{}](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCreateWebhookService[T] {
+	mock := &MockCreateWebhookService[T]{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
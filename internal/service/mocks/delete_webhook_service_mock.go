@@ -0,0 +1,46 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockDeleteWebhookService is an autogenerated mock type for the DeleteWebhookService type
+type MockDeleteWebhookService[T interface{}] struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: ctx, request
+func (_m *MockDeleteWebhookService[T]) Handle(ctx context.Context, request T) error {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMockDeleteWebhookService creates a new instance of MockDeleteWebhookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDeleteWebhookService[T interface{}](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDeleteWebhookService[T] {
+	mock := &MockDeleteWebhookService[T]{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,67 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	webhook_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+)
+
+// MockGetWebhookDeliveriesService is an autogenerated mock type for the GetWebhookDeliveriesService type
+type MockGetWebhookDeliveriesService[T interface{}] struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: ctx, request
+func (_m *MockGetWebhookDeliveriesService[T]) Handle(ctx context.Context, request T) (int, []webhook_entity.Attempt, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 int
+	var r1 []webhook_entity.Attempt
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) (int, []webhook_entity.Attempt, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, T) int); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, T) []webhook_entity.Attempt); ok {
+		r1 = rf(ctx, request)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]webhook_entity.Attempt)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, T) error); ok {
+		r2 = rf(ctx, request)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewMockGetWebhookDeliveriesService creates a new instance of MockGetWebhookDeliveriesService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockGetWebhookDeliveriesService[T interface{}](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockGetWebhookDeliveriesService[T] {
+	mock := &MockGetWebhookDeliveriesService[T]{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
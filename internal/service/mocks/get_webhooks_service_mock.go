@@ -0,0 +1,60 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	webhook_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+)
+
+// MockGetWebhooksService is an autogenerated mock type for the GetWebhooksService type
+type MockGetWebhooksService struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: ctx
+func (_m *MockGetWebhooksService) Handle(ctx context.Context) ([]webhook_entity.Subscription, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 []webhook_entity.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]webhook_entity.Subscription, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []webhook_entity.Subscription); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]webhook_entity.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockGetWebhooksService creates a new instance of MockGetWebhooksService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockGetWebhooksService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockGetWebhooksService {
+	mock := &MockGetWebhooksService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
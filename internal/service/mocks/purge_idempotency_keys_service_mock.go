@@ -0,0 +1,56 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockPurgeIdempotencyKeysService is an autogenerated mock type for the PurgeIdempotencyKeysService type
+type MockPurgeIdempotencyKeysService struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: ctx
+func (_m *MockPurgeIdempotencyKeysService) Handle(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockPurgeIdempotencyKeysService creates a new instance of MockPurgeIdempotencyKeysService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPurgeIdempotencyKeysService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPurgeIdempotencyKeysService {
+	mock := &MockPurgeIdempotencyKeysService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
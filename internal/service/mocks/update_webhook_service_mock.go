@@ -0,0 +1,58 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	webhook_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+)
+
+// MockUpdateWebhookService is an autogenerated mock type for the UpdateWebhookService type
+type MockUpdateWebhookService[T interface{}] struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: ctx, request
+func (_m *MockUpdateWebhookService[T]) Handle(ctx context.Context, request T) (webhook_entity.Subscription, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 webhook_entity.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) (webhook_entity.Subscription, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, T) webhook_entity.Subscription); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Get(0).(webhook_entity.Subscription)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, T) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockUpdateWebhookService creates a new instance of MockUpdateWebhookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUpdateWebhookService[T interface{}](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUpdateWebhookService[T] {
+	mock := &MockUpdateWebhookService[T]{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
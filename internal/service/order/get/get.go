@@ -5,6 +5,7 @@ import (
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
 )
 
 type Service struct {
@@ -22,28 +23,26 @@ func (s *Service) Handle(ctx context.Context, request GetOrderDto) (order_entity
 		return order_entity.Order{}, err
 	}
 
-	if request.FindViaID() {
-		order, err := s.repository.GetByID(ctx, request.OrderId)
-		if err != nil {
-			return order_entity.Order{}, err
-		}
+	order, err := s.find(ctx, request)
+	if err != nil {
+		return order_entity.Order{}, err
+	}
 
-		return order, nil
+	if err := auth.AuthorizeCustomer(ctx, order.CustomerId); err != nil {
+		return order_entity.Order{}, err
 	}
 
-	if request.FindViaCustomerID() {
-		order, err := s.repository.GetByCustomerID(ctx, request.CustomerId)
-		if err != nil {
-			return order_entity.Order{}, err
-		}
+	return order, nil
+}
 
-		return order, nil
+func (s *Service) find(ctx context.Context, request GetOrderDto) (order_entity.Order, error) {
+	if request.FindViaID() {
+		return s.repository.GetByID(ctx, request.OrderId)
 	}
 
-	order, err := s.repository.GetByTrackID(ctx, request.TrackId)
-	if err != nil {
-		return order_entity.Order{}, err
+	if request.FindViaCustomerID() {
+		return s.repository.GetByCustomerID(ctx, request.CustomerId)
 	}
 
-	return order, nil
+	return s.repository.GetByTrackID(ctx, request.TrackId)
 }
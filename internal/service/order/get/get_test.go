@@ -7,13 +7,17 @@ import (
 	"github.com/google/uuid"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHandle(t *testing.T) {
 	t.Run("Should return order when find via id", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		customerId := uuid.NewString()
+
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(customerId, nil))
 
 		id := uuid.NewString()
 
@@ -21,7 +25,8 @@ func TestHandle(t *testing.T) {
 
 		repository.On("GetByID", ctx, id).
 			Return(order_entity.Order{
-				Id: id,
+				Id:         id,
+				CustomerId: customerId,
 			}, nil).
 			Once()
 
@@ -42,7 +47,7 @@ func TestHandle(t *testing.T) {
 
 	t.Run("Should return order when find via track id", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		trackId := "ABC-123"
 
@@ -50,7 +55,8 @@ func TestHandle(t *testing.T) {
 
 		repository.On("GetByTrackID", ctx, trackId).
 			Return(order_entity.Order{
-				TrackId: order_entity.NewTrackIdFrom(trackId),
+				CustomerId: uuid.NewString(),
+				TrackId:    order_entity.NewTrackIdFrom(trackId),
 			}, nil).
 			Once()
 
@@ -69,6 +75,36 @@ func TestHandle(t *testing.T) {
 		repository.AssertExpectations(t)
 	})
 
+	t.Run("Should return error when the order belongs to another customer", func(t *testing.T) {
+		// Arrange
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), nil))
+
+		id := uuid.NewString()
+
+		repository := mocks.NewMockOrderRepository(t)
+
+		repository.On("GetByID", ctx, id).
+			Return(order_entity.Order{
+				Id:         id,
+				CustomerId: uuid.NewString(),
+			}, nil).
+			Once()
+
+		service := NewService(repository)
+
+		req := GetOrderDto{
+			OrderId: id,
+		}
+
+		// Act
+		resp, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrForbidden)
+		assert.Empty(t, resp)
+		repository.AssertExpectations(t)
+	})
+
 	t.Run("Should return error when request is invalid", func(t *testing.T) {
 		// Arrange
 		ctx := context.Background()
@@ -1,6 +1,7 @@
 package get_all
 
 import (
+	"github.com/go-playground/validator/v10"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
@@ -13,10 +14,20 @@ type GetOrdersDto struct {
 	common.Pagination
 }
 
+func (dto *GetOrdersDto) FilterByState() bool {
+	return dto.State != int(order_entity.None)
+}
+
 func (dto *GetOrdersDto) Validate() error {
-	if order_entity.IsValidState(order_entity.OrderState(dto.State)) {
-		return nil
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	if dto.FilterByState() && !order_entity.IsValidState(order_entity.OrderState(dto.State)) {
+		return custom_error.ErrRequestNotValid
 	}
 
-	return custom_error.ErrRequestNotValid
+	return nil
 }
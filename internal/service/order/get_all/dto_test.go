@@ -31,6 +31,29 @@ func TestValidate(t *testing.T) {
 		// Act
 		err := dto.Validate()
 
+		// Assert
+		assert.NoError(t, err)
+	})
+	t.Run("Should return nil when state is not informed", func(t *testing.T) {
+		// Arrange
+		dto := GetOrdersDto{}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return nil when customer id is not a uuid", func(t *testing.T) {
+		// Arrange
+		dto := GetOrdersDto{
+			CustomerID: "auth0|5f7c8ec7c33c6c004bbafe82",
+		}
+
+		// Act
+		err := dto.Validate()
+
 		// Assert
 		assert.NoError(t, err)
 	})
@@ -26,8 +26,11 @@ func (s *Service) Handle(ctx context.Context, request GetOrdersDto) (int, []orde
 
 	filter := repository.GetAllOrdersFilter{
 		CustomerID: request.CustomerID,
-		StateFrom:  order_entity.OrderState(request.State),
-		StateTo:    order_entity.OrderState(request.State),
+	}
+
+	if request.FilterByState() {
+		filter.StateFrom = order_entity.OrderState(request.State)
+		filter.StateTo = order_entity.OrderState(request.State + 1)
 	}
 
 	count, orders, err := s.repository.GetAll(ctx, request.Pagination, filter)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	repo "github.com/jfelipearaujo-org/ms-order-management/internal/repository"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -37,12 +39,49 @@ func TestHandle(t *testing.T) {
 		repository.AssertExpectations(t)
 	})
 
-	t.Run("Should return an error when request is invalid", func(t *testing.T) {
+	t.Run("Should filter by the requested state only", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := mocks.NewMockOrderRepository(t)
+
+		expectedFilter := repo.GetAllOrdersFilter{
+			CustomerID: "c3fdab1b-3c06-4db2-9edc-4760a2429462",
+			StateFrom:  order_entity.Received,
+			StateTo:    order_entity.Processing,
+		}
+
+		repository.On("GetAll", ctx, mock.Anything, expectedFilter).
+			Return(1, []order_entity.Order{{}}, nil).
+			Once()
+
+		service := NewService(repository)
+
+		req := GetOrdersDto{
+			CustomerID: "c3fdab1b-3c06-4db2-9edc-4760a2429462",
+			State:      int(order_entity.Received),
+		}
+
+		// Act
+		count, res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Len(t, res, 1)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should not filter by state when state is not informed", func(t *testing.T) {
 		// Arrange
 		ctx := context.Background()
 
 		repository := mocks.NewMockOrderRepository(t)
 
+		repository.On("GetAll", ctx, common.Pagination{Page: 1, Size: 10}, repo.GetAllOrdersFilter{}).
+			Return(0, []order_entity.Order{}, nil).
+			Once()
+
 		service := NewService(repository)
 
 		req := GetOrdersDto{}
@@ -50,6 +89,28 @@ func TestHandle(t *testing.T) {
 		// Act
 		count, res, err := service.Handle(ctx, req)
 
+		// Assert
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		assert.Empty(t, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return an error when request is invalid", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := mocks.NewMockOrderRepository(t)
+
+		service := NewService(repository)
+
+		req := GetOrdersDto{
+			State: 10,
+		}
+
+		// Act
+		count, res, err := service.Handle(ctx, req)
+
 		// Assert
 		assert.Error(t, err)
 		assert.Zero(t, count)
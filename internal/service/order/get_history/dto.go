@@ -0,0 +1,20 @@
+package get_history
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type GetOrderHistoryDto struct {
+	OrderId string `param:"id" validate:"required,uuid4"`
+}
+
+func (dto *GetOrderHistoryDto) Validate() error {
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	return nil
+}
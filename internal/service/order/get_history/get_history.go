@@ -0,0 +1,41 @@
+package get_history
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+)
+
+type Service struct {
+	repository repository.OrderRepository
+}
+
+func NewService(repository repository.OrderRepository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context, request GetOrderHistoryDto) ([]order_entity.StateTransition, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	order, err := s.repository.GetByID(ctx, request.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.AuthorizeCustomer(ctx, order.CustomerId); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repository.GetStateHistory(ctx, request.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
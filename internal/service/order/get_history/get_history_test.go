@@ -0,0 +1,162 @@
+package get_history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return the state history of the order", func(t *testing.T) {
+		// Arrange
+		customerId := uuid.NewString()
+
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(customerId, nil))
+
+		now := time.Now()
+
+		orderId := uuid.NewString()
+
+		history := []order_entity.StateTransition{
+			order_entity.NewStateTransition(orderId, order_entity.None, order_entity.Created, now),
+			order_entity.NewStateTransition(orderId, order_entity.Created, order_entity.Received, now),
+		}
+
+		repository := repository_mock.NewMockOrderRepository(t)
+
+		repository.On("GetByID", ctx, orderId).
+			Return(order_entity.Order{Id: orderId, CustomerId: customerId}, nil).
+			Once()
+
+		repository.On("GetStateHistory", ctx, orderId).
+			Return(history, nil).
+			Once()
+
+		service := NewService(repository)
+
+		req := GetOrderHistoryDto{
+			OrderId: orderId,
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, history, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the order belongs to another customer", func(t *testing.T) {
+		// Arrange
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), nil))
+
+		orderId := uuid.NewString()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+
+		repository.On("GetByID", ctx, orderId).
+			Return(order_entity.Order{Id: orderId, CustomerId: uuid.NewString()}, nil).
+			Once()
+
+		service := NewService(repository)
+
+		req := GetOrderHistoryDto{
+			OrderId: orderId,
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrForbidden)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when request is invalid", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+
+		service := NewService(repository)
+
+		req := GetOrderHistoryDto{}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when order is not found", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		orderId := uuid.NewString()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+
+		repository.On("GetByID", ctx, orderId).
+			Return(order_entity.Order{}, custom_error.ErrOrderNotFound).
+			Once()
+
+		service := NewService(repository)
+
+		req := GetOrderHistoryDto{
+			OrderId: orderId,
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderNotFound)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when something got wrong while getting the history", func(t *testing.T) {
+		// Arrange
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleAdmin)}))
+
+		orderId := uuid.NewString()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+
+		repository.On("GetByID", ctx, orderId).
+			Return(order_entity.Order{Id: orderId, CustomerId: uuid.NewString()}, nil).
+			Once()
+
+		repository.On("GetStateHistory", ctx, orderId).
+			Return(nil, assert.AnError).
+			Once()
+
+		service := NewService(repository)
+
+		req := GetOrderHistoryDto{
+			OrderId: orderId,
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+	})
+}
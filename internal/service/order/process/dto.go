@@ -1,7 +1,8 @@
 package process
 
 type ProcessMessageDto struct {
-	OrderId string `json:"order_id"`
+	MessageId string `json:"-"`
+	OrderId   string `json:"order_id"`
 
 	PaymentResponse *PaymentResponse `json:"payment"`
 	OrderResponse   *OrderResponse   `json:"order"`
@@ -2,33 +2,73 @@ package process
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/inbox"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
 )
 
 type Service struct {
 	orderRepository   repository.OrderRepository
 	paymentRepository repository.PaymentRepository
+	inboxRepository   repository.InboxRepository
+	tracking          tracking.Publisher
+	paymentRules      PaymentRules
 	timeProvider      provider.TimeProvider
 }
 
 func NewService(
 	orderRepository repository.OrderRepository,
 	paymentRepository repository.PaymentRepository,
+	inboxRepository repository.InboxRepository,
+	trackingPublisher tracking.Publisher,
+	paymentRules PaymentRules,
 	timeProvider provider.TimeProvider,
 ) *Service {
 	return &Service{
 		orderRepository:   orderRepository,
 		paymentRepository: paymentRepository,
+		inboxRepository:   inboxRepository,
+		tracking:          trackingPublisher,
+		paymentRules:      paymentRules,
 		timeProvider:      timeProvider,
 	}
 }
 
 func (s *Service) Handle(ctx context.Context, message ProcessMessageDto) error {
+	if message.MessageId != "" {
+		processed, err := s.inboxRepository.Exists(ctx, message.MessageId)
+		if err != nil {
+			return err
+		}
+
+		if processed {
+			slog.InfoContext(ctx, "message already processed", "message_id", message.MessageId)
+			return nil
+		}
+
+		ctx = inbox.WithMessage(ctx, message.MessageId)
+	}
+
+	err := s.handle(ctx, message)
+	if errors.Is(err, custom_error.ErrQueueMessageAlreadyProcessed) {
+		slog.InfoContext(ctx, "message already processed", "message_id", message.MessageId)
+		return nil
+	}
+
+	return err
+}
+
+// handle applies the order and payment responses to the order in memory and
+// writes them in a single transaction, the one recording the message in the
+// inbox, so a failed write leaves the message to be processed again as a whole
+func (s *Service) handle(ctx context.Context, message ProcessMessageDto) error {
 	if message.OrderResponse == nil &&
 		message.PaymentResponse == nil {
 		return custom_error.ErrQueueMessageNotValid
@@ -39,6 +79,10 @@ func (s *Service) Handle(ctx context.Context, message ProcessMessageDto) error {
 		return err
 	}
 
+	var events []tracking.Event
+
+	orderChanged := false
+
 	if message.OrderResponse != nil {
 		newState := order_entity.NewOrderState(message.OrderResponse.State)
 
@@ -50,13 +94,15 @@ func (s *Service) Handle(ctx context.Context, message ProcessMessageDto) error {
 			return err
 		}
 
-		if err := s.orderRepository.Update(ctx, &order, false); err != nil {
-			return err
-		}
+		orderChanged = true
+
+		events = append(events, tracking.NewStateChangedEvent(order))
 	}
 
+	var payment *payment_entity.Payment
+
 	if message.PaymentResponse != nil {
-		payment := order.GetPaymentByID(message.PaymentResponse.PaymentId)
+		payment = order.GetPaymentByID(message.PaymentResponse.PaymentId)
 
 		if payment == nil {
 			return custom_error.ErrPaymentNotFound
@@ -68,27 +114,48 @@ func (s *Service) Handle(ctx context.Context, message ProcessMessageDto) error {
 			return custom_error.ErrPaymentInvalidStateTransition
 		}
 
-		payment.UpdateState(newState, s.timeProvider.GetTime())
+		now := s.timeProvider.GetTime()
 
-		if err := s.paymentRepository.Update(ctx, payment); err != nil {
-			return err
-		}
+		payment.UpdateState(newState, now)
 
-		order, err = s.orderRepository.GetByID(ctx, message.OrderId)
-		if err != nil {
-			return err
-		}
-
-		if order.ShouldCancel() {
-			if err := order.UpdateState(order_entity.Cancelled, s.timeProvider.GetTime()); err != nil {
+		if rule, ok := s.paymentRules.Match(&order, payment); ok {
+			if err := order.UpdateState(rule.OrderState, now); err != nil {
 				return err
 			}
 
-			if err := s.orderRepository.Update(ctx, &order, false); err != nil {
-				return err
-			}
+			orderChanged = true
+
+			events = append(events, tracking.NewStateChangedEvent(order))
 		}
 	}
 
+	switch {
+	case payment == nil:
+		err = s.orderRepository.Update(ctx, &order, false)
+	case orderChanged:
+		err = s.orderRepository.UpdateWithPayment(ctx, &order, payment)
+	default:
+		err = s.paymentRepository.Update(ctx, payment)
+	}
+	if err != nil {
+		return err
+	}
+
+	if payment != nil {
+		events = append(events, tracking.NewPaymentChangedEvent(order, *payment))
+	}
+
+	for _, event := range events {
+		s.publishTracking(ctx, event)
+	}
+
 	return nil
 }
+
+// publishTracking is best-effort, the live tracking stream must not make the
+// message be redelivered as the order is already updated
+func (s *Service) publishTracking(ctx context.Context, event tracking.Event) {
+	if err := s.tracking.Publish(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "error publishing tracking event", "order_id", event.OrderId, "error", err)
+	}
+}
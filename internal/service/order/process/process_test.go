@@ -5,15 +5,24 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/event_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	provider_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/inbox"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+	tracking_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+var paymentRules = PaymentRules{
+	{PaymentState: payment_entity.Approved, OrderState: order_entity.Received, MinPayments: 1},
+	{PaymentState: payment_entity.Rejected, OrderState: order_entity.Cancelled, MinPayments: 3},
+}
+
 func TestHandle(t *testing.T) {
 	t.Run("Should return an error when the message is not valid", func(t *testing.T) {
 		// Arrange
@@ -22,8 +31,10 @@ func TestHandle(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{}
 
@@ -45,6 +56,8 @@ func TestHandleOrderResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		orderRepository.On("GetByID", ctx, mock.Anything).
 			Return(order_entity.Order{
@@ -60,7 +73,13 @@ func TestHandleOrderResponse(t *testing.T) {
 			Return(now).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -86,12 +105,14 @@ func TestHandleOrderResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		orderRepository.On("GetByID", ctx, mock.Anything).
 			Return(order_entity.Order{}, custom_error.ErrOrderNotFound).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -117,6 +138,8 @@ func TestHandleOrderResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		orderRepository.On("GetByID", ctx, mock.Anything).
 			Return(order_entity.Order{
@@ -124,7 +147,7 @@ func TestHandleOrderResponse(t *testing.T) {
 			}, nil).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -152,6 +175,8 @@ func TestHandleOrderResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		orderRepository.On("GetByID", ctx, mock.Anything).
 			Return(order_entity.Order{
@@ -167,7 +192,7 @@ func TestHandleOrderResponse(t *testing.T) {
 			Return(now).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -197,6 +222,8 @@ func TestHandlePaymentResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		order := order_entity.Order{
 			Payments: []payment_entity.Payment{
@@ -209,7 +236,7 @@ func TestHandlePaymentResponse(t *testing.T) {
 
 		orderRepository.On("GetByID", ctx, mock.Anything).
 			Return(order, nil).
-			Times(2)
+			Once()
 
 		paymentRepository.On("Update", ctx, mock.Anything).
 			Return(nil).
@@ -219,7 +246,13 @@ func TestHandlePaymentResponse(t *testing.T) {
 			Return(now).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventPaymentChanged
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -246,6 +279,8 @@ func TestHandlePaymentResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		order := order_entity.Order{
 			Payments: []payment_entity.Payment{
@@ -260,7 +295,7 @@ func TestHandlePaymentResponse(t *testing.T) {
 			Return(order, nil).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -287,6 +322,8 @@ func TestHandlePaymentResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		order := order_entity.Order{
 			Payments: []payment_entity.Payment{
@@ -301,7 +338,7 @@ func TestHandlePaymentResponse(t *testing.T) {
 			Return(order, nil).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -330,6 +367,8 @@ func TestHandlePaymentResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		order := order_entity.Order{
 			Payments: []payment_entity.Payment{
@@ -352,7 +391,7 @@ func TestHandlePaymentResponse(t *testing.T) {
 			Return(now).
 			Once()
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		message := ProcessMessageDto{
 			OrderId: "order-id",
@@ -379,6 +418,8 @@ func TestHandlePaymentResponse(t *testing.T) {
 		orderRepository := mocks.NewMockOrderRepository(t)
 		paymentRepository := mocks.NewMockPaymentRepository(t)
 		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
 
 		message := ProcessMessageDto{
 			OrderId: "order_id",
@@ -397,12 +438,28 @@ func TestHandlePaymentResponse(t *testing.T) {
 
 		orderRepository.On("GetByID", ctx, "order_id").Return(order, nil)
 
-		paymentRepository.On("Update", ctx, mock.Anything).Return(nil)
-		orderRepository.On("Update", ctx, mock.Anything, mock.Anything).Return(nil)
+		orderRepository.On("UpdateWithPayment", ctx, mock.MatchedBy(func(order *order_entity.Order) bool {
+			return order.State == order_entity.Cancelled &&
+				hasEvent(order.PendingEvents(), event_entity.OrderCancelled)
+		}), mock.MatchedBy(func(payment *payment_entity.Payment) bool {
+			return hasEvent(payment.PendingEvents(), event_entity.PaymentRejected)
+		})).Return(nil)
 
 		timeProvider.On("GetTime").Return(time.Now())
 
-		service := NewService(orderRepository, paymentRepository, timeProvider)
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged && event.State == order_entity.Cancelled
+		})).
+			Return(nil).
+			Once()
+
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventPaymentChanged
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
 
 		// Act
 		err := service.Handle(ctx, message)
@@ -413,4 +470,431 @@ func TestHandlePaymentResponse(t *testing.T) {
 		paymentRepository.AssertExpectations(t)
 		timeProvider.AssertExpectations(t)
 	})
+	t.Run("Should move the order to received when the payment is approved", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		order := order_entity.NewOrder("customer_id", now)
+		order.Payments = []payment_entity.Payment{
+			{PaymentId: "payment-id", State: payment_entity.WaitingForApproval},
+		}
+
+		orderRepository.On("GetByID", ctx, "order-id").
+			Return(order, nil).
+			Once()
+
+		orderRepository.On("UpdateWithPayment", ctx, mock.MatchedBy(func(order *order_entity.Order) bool {
+			return order.State == order_entity.Received &&
+				hasEvent(order.PendingEvents(), event_entity.OrderStateChanged)
+		}), mock.MatchedBy(func(payment *payment_entity.Payment) bool {
+			return payment.State == payment_entity.Approved &&
+				hasEvent(payment.PendingEvents(), event_entity.PaymentApproved)
+		})).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged && event.State == order_entity.Received
+		})).
+			Return(nil).
+			Once()
+
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventPaymentChanged
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			OrderId: "order-id",
+			PaymentResponse: &PaymentResponse{
+				PaymentId: "payment-id",
+				State:     "Approved",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		orderRepository.AssertExpectations(t)
+		paymentRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should only update the payment when no rule matches", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		order := order_entity.NewOrder("customer_id", now)
+		order.Payments = []payment_entity.Payment{
+			{PaymentId: "payment-id", State: payment_entity.WaitingForApproval},
+		}
+
+		orderRepository.On("GetByID", ctx, "order-id").
+			Return(order, nil).
+			Once()
+
+		paymentRepository.On("Update", ctx, mock.Anything).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		trackingPublisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventPaymentChanged
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, PaymentRules{}, timeProvider)
+
+		message := ProcessMessageDto{
+			OrderId: "order-id",
+			PaymentResponse: &PaymentResponse{
+				PaymentId: "payment-id",
+				State:     "Approved",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		orderRepository.AssertExpectations(t)
+		paymentRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return an error when the order and payment update fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		order := order_entity.NewOrder("customer_id", now)
+		order.Payments = []payment_entity.Payment{
+			{PaymentId: "payment-id", State: payment_entity.WaitingForApproval},
+		}
+
+		orderRepository.On("GetByID", ctx, "order-id").
+			Return(order, nil).
+			Once()
+
+		orderRepository.On("UpdateWithPayment", ctx, mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			OrderId: "order-id",
+			PaymentResponse: &PaymentResponse{
+				PaymentId: "payment-id",
+				State:     "Approved",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.Error(t, err)
+		orderRepository.AssertExpectations(t)
+		paymentRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+}
+
+func TestHandleInbox(t *testing.T) {
+	t.Run("Should skip a message already processed", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		inboxRepository.On("Exists", ctx, "message-id").
+			Return(true, nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			MessageId: "message-id",
+			OrderId:   "order-id",
+			OrderResponse: &OrderResponse{
+				State: "Received",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		inboxRepository.AssertExpectations(t)
+		orderRepository.AssertExpectations(t)
+	})
+
+	t.Run("Should return an error when the inbox check fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		inboxRepository.On("Exists", ctx, "message-id").
+			Return(false, assert.AnError).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			MessageId: "message-id",
+			OrderId:   "order-id",
+			OrderResponse: &OrderResponse{
+				State: "Received",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, assert.AnError)
+		inboxRepository.AssertExpectations(t)
+	})
+
+	t.Run("Should record the message within the update", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		inboxRepository.On("Exists", ctx, "message-id").
+			Return(false, nil).
+			Once()
+
+		orderRepository.On("GetByID", mock.Anything, "order-id").
+			Return(order_entity.Order{
+				State: order_entity.Created,
+			}, nil).
+			Once()
+
+		hasMessage := mock.MatchedBy(func(ctx context.Context) bool {
+			messageId, ok := inbox.Claim(ctx)
+			return ok && messageId == "message-id"
+		})
+
+		orderRepository.On("Update", hasMessage, mock.Anything, false).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		trackingPublisher.On("Publish", mock.Anything, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			MessageId: "message-id",
+			OrderId:   "order-id",
+			OrderResponse: &OrderResponse{
+				State: "Received",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		inboxRepository.AssertExpectations(t)
+		orderRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should not record the order response alone when the payment write fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		order := order_entity.NewOrder("customer_id", now)
+		order.Payments = []payment_entity.Payment{
+			{PaymentId: "payment-id", State: payment_entity.WaitingForApproval},
+		}
+
+		inboxRepository.On("Exists", ctx, "message-id").
+			Return(false, nil).
+			Once()
+
+		orderRepository.On("GetByID", mock.Anything, "order-id").
+			Return(order, nil).
+			Once()
+
+		hasMessage := mock.MatchedBy(func(ctx context.Context) bool {
+			messageId, ok := inbox.Claim(ctx)
+			return ok && messageId == "message-id"
+		})
+
+		orderRepository.On("UpdateWithPayment", hasMessage, mock.MatchedBy(func(order *order_entity.Order) bool {
+			return order.State == order_entity.Received &&
+				len(order.PendingStateTransitions()) == 1
+		}), mock.MatchedBy(func(payment *payment_entity.Payment) bool {
+			return payment.State == payment_entity.Rejected
+		})).
+			Return(assert.AnError).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Twice()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			MessageId: "message-id",
+			OrderId:   "order-id",
+			OrderResponse: &OrderResponse{
+				State: "Received",
+			},
+			PaymentResponse: &PaymentResponse{
+				PaymentId: "payment-id",
+				State:     "Rejected",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		orderRepository.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		inboxRepository.AssertExpectations(t)
+		orderRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should not return an error when the message was processed concurrently", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		orderRepository := mocks.NewMockOrderRepository(t)
+		paymentRepository := mocks.NewMockPaymentRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+		inboxRepository := mocks.NewMockInboxRepository(t)
+		trackingPublisher := tracking_mocks.NewMockPublisher(t)
+
+		inboxRepository.On("Exists", ctx, "message-id").
+			Return(false, nil).
+			Once()
+
+		orderRepository.On("GetByID", mock.Anything, "order-id").
+			Return(order_entity.Order{
+				State: order_entity.Created,
+			}, nil).
+			Once()
+
+		orderRepository.On("Update", mock.Anything, mock.Anything, false).
+			Return(custom_error.ErrQueueMessageAlreadyProcessed).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		service := NewService(orderRepository, paymentRepository, inboxRepository, trackingPublisher, paymentRules, timeProvider)
+
+		message := ProcessMessageDto{
+			MessageId: "message-id",
+			OrderId:   "order-id",
+			OrderResponse: &OrderResponse{
+				State: "Received",
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, message)
+
+		// Assert
+		assert.NoError(t, err)
+		inboxRepository.AssertExpectations(t)
+		orderRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+}
+
+func hasEvent(events []event_entity.Event, eventType event_entity.EventType) bool {
+	for _, event := range events {
+		if event.EventType == eventType {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,87 @@
+package process
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+)
+
+// PaymentRule moves the order to OrderState once at least MinPayments of its
+// payments are in PaymentState
+type PaymentRule struct {
+	PaymentState payment_entity.PaymentState
+	OrderState   order_entity.OrderState
+	MinPayments  int
+}
+
+type PaymentRules []PaymentRule
+
+// ParsePaymentRules reads a comma separated list of rules written as
+// "<payment state>:<order state>[:<min payments>]", e.g. "Approved:Received"
+func ParsePaymentRules(value string) (PaymentRules, error) {
+	rules := PaymentRules{}
+
+	for _, text := range strings.Split(value, ",") {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		parts := strings.Split(text, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid payment rule: %s", text)
+		}
+
+		rule := PaymentRule{
+			PaymentState: payment_entity.NewPaymentState(strings.TrimSpace(parts[0])),
+			OrderState:   order_entity.NewOrderState(strings.TrimSpace(parts[1])),
+			MinPayments:  1,
+		}
+
+		if rule.PaymentState == payment_entity.None {
+			return nil, fmt.Errorf("invalid payment state in rule: %s", text)
+		}
+
+		if rule.OrderState == order_entity.None {
+			return nil, fmt.Errorf("invalid order state in rule: %s", text)
+		}
+
+		if len(parts) == 3 {
+			minPayments, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+			if err != nil || minPayments < 1 {
+				return nil, fmt.Errorf("invalid number of payments in rule: %s", text)
+			}
+
+			rule.MinPayments = minPayments
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Match returns the first rule triggered by the payment that leads the order
+// to a valid next state
+func (r PaymentRules) Match(order *order_entity.Order, payment *payment_entity.Payment) (PaymentRule, bool) {
+	for _, rule := range r {
+		if !payment.IsInState(rule.PaymentState) {
+			continue
+		}
+
+		if order.CountPaymentsInState(rule.PaymentState) < rule.MinPayments {
+			continue
+		}
+
+		if !order.State.CanTransitionTo(rule.OrderState) {
+			continue
+		}
+
+		return rule, true
+	}
+
+	return PaymentRule{}, false
+}
@@ -0,0 +1,110 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePaymentRules(t *testing.T) {
+	t.Run("Should parse the payment rules", func(t *testing.T) {
+		// Arrange
+		value := "Approved:Received, Rejected:Cancelled:3"
+
+		// Act
+		rules, err := ParsePaymentRules(value)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, PaymentRules{
+			{PaymentState: payment_entity.Approved, OrderState: order_entity.Received, MinPayments: 1},
+			{PaymentState: payment_entity.Rejected, OrderState: order_entity.Cancelled, MinPayments: 3},
+		}, rules)
+	})
+
+	t.Run("Should return no rules when value is empty", func(t *testing.T) {
+		// Arrange
+		value := ""
+
+		// Act
+		rules, err := ParsePaymentRules(value)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("Should return error when rules are invalid", func(t *testing.T) {
+		cases := []string{
+			"Approved",
+			"Approved:Received:1:2",
+			"Unknown:Received",
+			"Approved:Unknown",
+			"Rejected:Cancelled:zero",
+			"Rejected:Cancelled:0",
+		}
+
+		for _, value := range cases {
+			// Act
+			rules, err := ParsePaymentRules(value)
+
+			// Assert
+			assert.Error(t, err, value)
+			assert.Nil(t, rules)
+		}
+	})
+}
+
+func TestMatch(t *testing.T) {
+	t.Run("Should match the rule of the payment state", func(t *testing.T) {
+		// Arrange
+		order := &order_entity.Order{
+			State: order_entity.Created,
+			Payments: []payment_entity.Payment{
+				{PaymentId: "1", State: payment_entity.Approved},
+			},
+		}
+
+		// Act
+		rule, ok := paymentRules.Match(order, &order.Payments[0])
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, order_entity.Received, rule.OrderState)
+	})
+
+	t.Run("Should not match when there are not enough payments in the state", func(t *testing.T) {
+		// Arrange
+		order := &order_entity.Order{
+			State: order_entity.Created,
+			Payments: []payment_entity.Payment{
+				{PaymentId: "1", State: payment_entity.Rejected},
+				{PaymentId: "2", State: payment_entity.Rejected},
+			},
+		}
+
+		// Act
+		_, ok := paymentRules.Match(order, &order.Payments[1])
+
+		// Assert
+		assert.False(t, ok)
+	})
+
+	t.Run("Should not match when the order can not transition to the state", func(t *testing.T) {
+		// Arrange
+		order := &order_entity.Order{
+			State: order_entity.Received,
+			Payments: []payment_entity.Payment{
+				{PaymentId: "1", State: payment_entity.Approved},
+			},
+		}
+
+		// Act
+		_, ok := paymentRules.Match(order, &order.Payments[0])
+
+		// Assert
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,21 @@
+package remove_item
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type RemoveOrderItemDto struct {
+	OrderId string `param:"id" validate:"required,uuid4"`
+	ItemId  string `param:"item_id" validate:"required,uuid4"`
+}
+
+func (dto *RemoveOrderItemDto) Validate() error {
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	return nil
+}
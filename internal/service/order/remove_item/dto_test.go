@@ -0,0 +1,40 @@
+package remove_item
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Should return nil when dto is valid", func(t *testing.T) {
+		// Arrange
+		dto := RemoveOrderItemDto{
+			OrderId: uuid.NewString(),
+			ItemId:  uuid.NewString(),
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error when dto is invalid", func(t *testing.T) {
+		// Arrange
+		dto := RemoveOrderItemDto{
+			OrderId: uuid.NewString(),
+			ItemId:  "invalid",
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+}
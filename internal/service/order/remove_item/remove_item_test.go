@@ -0,0 +1,157 @@
+package remove_item
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	provider_mock "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOrderWithItem(itemId string, now time.Time) *order_entity.Order {
+	order := order_entity.NewOrder(uuid.NewString(), now)
+	order.Items = append(order.Items, order_entity.NewItem(itemId, "name", common.NewMoney(1000, common.DefaultCurrency), 1))
+
+	return &order
+}
+
+func TestHandle(t *testing.T) {
+	t.Run("Should remove the item and recalculate the totals", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		repository.On("Update", ctx, mock.Anything, true).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		itemId := uuid.NewString()
+		order := newOrderWithItem(itemId, now)
+
+		req := RemoveOrderItemDto{
+			OrderId: uuid.NewString(),
+			ItemId:  itemId,
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, order.Items)
+		assert.Equal(t, 0, order.TotalItems)
+		assert.Equal(t, common.NewMoney(0, common.DefaultCurrency), order.TotalPrice)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when request is invalid", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		service := NewService(repository, timeProvider)
+
+		order := &order_entity.Order{}
+
+		req := RemoveOrderItemDto{}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the item does not exist", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		order := newOrderWithItem(uuid.NewString(), now)
+
+		itemId := uuid.NewString()
+
+		req := RemoveOrderItemDto{
+			OrderId: uuid.NewString(),
+			ItemId:  itemId,
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrOrderItemNotFound)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when try to update the order", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		repository.On("Update", ctx, mock.Anything, true).
+			Return(assert.AnError).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		itemId := uuid.NewString()
+		order := newOrderWithItem(itemId, now)
+
+		req := RemoveOrderItemDto{
+			OrderId: uuid.NewString(),
+			ItemId:  itemId,
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.Error(t, err)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+}
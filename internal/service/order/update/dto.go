@@ -2,14 +2,20 @@ package update
 
 import (
 	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 )
 
+const (
+	minUnitPrice int64 = 1      // 0.01 in minor units
+	maxUnitPrice int64 = 100000 // 1000.00 in minor units
+)
+
 type UpdateOrderItemDto struct {
-	ItemId    string  `json:"id" validate:"required,uuid4"`
-	Name      string  `json:"name" validate:"required"`
-	UnitPrice float64 `json:"unit_price" validate:"required,min=0.01,max=1000"`
-	Quantity  int     `json:"quantity" validate:"required,min=1,max=100"`
+	ItemId    string       `json:"id" validate:"required,uuid4"`
+	Name      string       `json:"name" validate:"required"`
+	UnitPrice common.Money `json:"unit_price"`
+	Quantity  int          `json:"quantity" validate:"required,min=1,max=100"`
 }
 
 type UpdateOrderDto struct {
@@ -26,5 +32,11 @@ func (dto *UpdateOrderDto) Validate() error {
 		return custom_error.ErrRequestNotValid
 	}
 
+	for _, item := range dto.Items {
+		if !item.UnitPrice.IsBetween(minUnitPrice, maxUnitPrice) {
+			return custom_error.ErrRequestNotValid
+		}
+	}
+
 	return nil
 }
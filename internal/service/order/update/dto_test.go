@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +33,7 @@ func TestValidate(t *testing.T) {
 				{
 					ItemId:    uuid.NewString(),
 					Name:      "name",
-					UnitPrice: 10,
+					UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -52,6 +53,27 @@ func TestValidate(t *testing.T) {
 		// Act
 		err := dto.Validate()
 
+		// Assert
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+	t.Run("Should return error when item price is out of range", func(t *testing.T) {
+		// Arrange
+		dto := UpdateOrderDto{
+			OrderId: uuid.NewString(),
+			Items: []UpdateOrderItemDto{
+				{
+					ItemId:    uuid.NewString(),
+					Name:      "name",
+					UnitPrice: common.NewMoney(100001, common.DefaultCurrency),
+					Quantity:  1,
+				},
+			},
+		}
+
+		// Act
+		err := dto.Validate()
+
 		// Assert
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
@@ -2,23 +2,29 @@ package update
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
 )
 
 type Service struct {
 	repository   repository.OrderRepository
+	publisher    tracking.Publisher
 	timeProvider provider.TimeProvider
 }
 
 func NewService(
 	repository repository.OrderRepository,
+	publisher tracking.Publisher,
 	timeProvider provider.TimeProvider,
 ) *Service {
 	return &Service{
 		repository:   repository,
+		publisher:    publisher,
 		timeProvider: timeProvider,
 	}
 }
@@ -28,6 +34,12 @@ func (s *Service) Handle(ctx context.Context, order *order_entity.Order, request
 		return err
 	}
 
+	if err := auth.AuthorizeCustomer(ctx, order.CustomerId); err != nil {
+		return err
+	}
+
+	previousState := order.State
+
 	if err := order.UpdateState(order_entity.OrderState(request.State), s.timeProvider.GetTime()); err != nil {
 		return err
 	}
@@ -51,5 +63,13 @@ func (s *Service) Handle(ctx context.Context, order *order_entity.Order, request
 	order.RefreshStateTitle()
 	order.CalculateTotals()
 
+	// only a new state is told to the tracking stream, which is best-effort as
+	// the order is already updated
+	if order.State != previousState {
+		if err := s.publisher.Publish(ctx, tracking.NewStateChangedEvent(*order)); err != nil {
+			slog.ErrorContext(ctx, "error publishing tracking event", "order_id", order.Id, "error", err)
+		}
+	}
+
 	return nil
 }
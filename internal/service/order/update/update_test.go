@@ -6,9 +6,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
 	provider_mock "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
 	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+	tracking_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,11 +21,12 @@ import (
 func TestHandle(t *testing.T) {
 	t.Run("Should update order without items", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		now := time.Now()
 
 		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		repository.On("Update", ctx, mock.Anything, false).
@@ -31,7 +37,13 @@ func TestHandle(t *testing.T) {
 			Return(now).
 			Once()
 
-		service := NewService(repository, timeProvider)
+		publisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged && event.State == order_entity.Created
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, publisher, timeProvider)
 
 		order := &order_entity.Order{}
 
@@ -49,13 +61,57 @@ func TestHandle(t *testing.T) {
 		timeProvider.AssertExpectations(t)
 	})
 
+	t.Run("Should not return error when the tracking event can not be published", func(t *testing.T) {
+		// Arrange
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		repository.On("Update", ctx, mock.Anything, false).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		publisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged && event.State == order_entity.Created
+		})).
+			Return(assert.AnError).
+			Once()
+
+		service := NewService(repository, publisher, timeProvider)
+
+		order := &order_entity.Order{}
+
+		req := UpdateOrderDto{
+			OrderId: uuid.NewString(),
+			State:   1,
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.NoError(t, err)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+	})
+
 	t.Run("Should update order with items", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		now := time.Now()
 
 		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		repository.On("Update", ctx, mock.Anything, true).
@@ -66,7 +122,13 @@ func TestHandle(t *testing.T) {
 			Return(now).
 			Times(2)
 
-		service := NewService(repository, timeProvider)
+		publisher.On("Publish", ctx, mock.MatchedBy(func(event tracking.Event) bool {
+			return event.Type == tracking.EventStateChanged && event.State == order_entity.Created
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, publisher, timeProvider)
 
 		order := &order_entity.Order{}
 
@@ -77,7 +139,7 @@ func TestHandle(t *testing.T) {
 				{
 					ItemId:    uuid.NewString(),
 					Name:      "name",
-					UnitPrice: 10.0,
+					UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
@@ -92,14 +154,62 @@ func TestHandle(t *testing.T) {
 		timeProvider.AssertExpectations(t)
 	})
 
+	t.Run("Should not publish a state change when only the items are updated", func(t *testing.T) {
+		// Arrange
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		repository.On("Update", ctx, mock.Anything, true).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Times(2)
+
+		service := NewService(repository, publisher, timeProvider)
+
+		order := &order_entity.Order{
+			State: order_entity.Created,
+		}
+
+		req := UpdateOrderDto{
+			OrderId: uuid.NewString(),
+			State:   1,
+			Items: []UpdateOrderItemDto{
+				{
+					ItemId:    uuid.NewString(),
+					Name:      "name",
+					UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
+					Quantity:  1,
+				},
+			},
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.NoError(t, err)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+
 	t.Run("Should return error when request is invalid", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
-		service := NewService(repository, timeProvider)
+		service := NewService(repository, publisher, timeProvider)
 
 		order := &order_entity.Order{}
 
@@ -114,13 +224,88 @@ func TestHandle(t *testing.T) {
 		timeProvider.AssertExpectations(t)
 	})
 
+	t.Run("Should update the order of the customer itself", func(t *testing.T) {
+		// Arrange
+		customerId := uuid.NewString()
+
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(customerId, nil))
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		repository.On("Update", ctx, mock.Anything, false).
+			Return(nil).
+			Once()
+
+		publisher.On("Publish", ctx, mock.Anything).
+			Return(nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		service := NewService(repository, publisher, timeProvider)
+
+		order := &order_entity.Order{
+			CustomerId: customerId,
+		}
+
+		req := UpdateOrderDto{
+			OrderId: uuid.NewString(),
+			State:   1,
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.NoError(t, err)
+		repository.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return forbidden when the order belongs to another customer", func(t *testing.T) {
+		// Arrange
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), nil))
+
+		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		service := NewService(repository, publisher, timeProvider)
+
+		order := &order_entity.Order{
+			CustomerId: uuid.NewString(),
+		}
+
+		req := UpdateOrderDto{
+			OrderId: uuid.NewString(),
+			State:   1,
+		}
+
+		// Act
+		err := service.Handle(ctx, order, req)
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrForbidden)
+		repository.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
 	t.Run("Should return error when try to update the order", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		now := time.Now()
 
 		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		repository.On("Update", ctx, mock.Anything, false).
@@ -131,7 +316,7 @@ func TestHandle(t *testing.T) {
 			Return(now).
 			Once()
 
-		service := NewService(repository, timeProvider)
+		service := NewService(repository, publisher, timeProvider)
 
 		order := &order_entity.Order{}
 
@@ -151,18 +336,19 @@ func TestHandle(t *testing.T) {
 
 	t.Run("Should return error when cannot update the state", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		now := time.Now()
 
 		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		timeProvider.On("GetTime").
 			Return(now).
 			Once()
 
-		service := NewService(repository, timeProvider)
+		service := NewService(repository, publisher, timeProvider)
 
 		order := &order_entity.Order{
 			State: order_entity.Received,
@@ -184,18 +370,19 @@ func TestHandle(t *testing.T) {
 
 	t.Run("Should return error when cannot add an item", func(t *testing.T) {
 		// Arrange
-		ctx := context.Background()
+		ctx := auth.WithPrincipal(context.Background(), auth.NewPrincipal(uuid.NewString(), []string{string(auth.RoleStaff)}))
 
 		now := time.Now()
 
 		repository := repository_mock.NewMockOrderRepository(t)
+		publisher := tracking_mocks.NewMockPublisher(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		timeProvider.On("GetTime").
 			Return(now).
 			Times(2)
 
-		service := NewService(repository, timeProvider)
+		service := NewService(repository, publisher, timeProvider)
 
 		itemId := uuid.NewString()
 
@@ -215,7 +402,7 @@ func TestHandle(t *testing.T) {
 				{
 					ItemId:    itemId,
 					Name:      "name",
-					UnitPrice: 10.0,
+					UnitPrice: common.NewMoney(1000, common.DefaultCurrency),
 					Quantity:  1,
 				},
 			},
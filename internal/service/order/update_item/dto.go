@@ -0,0 +1,23 @@
+package update_item
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type UpdateOrderItemDto struct {
+	OrderId string `param:"id" validate:"required,uuid4"`
+	ItemId  string `param:"item_id" validate:"required,uuid4"`
+
+	Quantity int `json:"quantity" validate:"required,min=1,max=100"`
+}
+
+func (dto *UpdateOrderItemDto) Validate() error {
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package update_item
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository   repository.OrderRepository
+	timeProvider provider.TimeProvider
+}
+
+func NewService(
+	repository repository.OrderRepository,
+	timeProvider provider.TimeProvider,
+) *Service {
+	return &Service{
+		repository:   repository,
+		timeProvider: timeProvider,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context, order *order_entity.Order, request UpdateOrderItemDto) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	if err := order.SetItemQuantity(request.ItemId, request.Quantity, s.timeProvider.GetTime()); err != nil {
+		return err
+	}
+
+	if err := s.repository.Update(ctx, order, true); err != nil {
+		return err
+	}
+
+	order.RefreshStateTitle()
+	order.CalculateTotals()
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracing"
+)
+
+// leaseDuration is how long a claimed message stays hidden from other relays
+// while it is being published
+const leaseDuration = time.Minute
+
+type Service struct {
+	repository   repository.OutboxRepository
+	topics       map[string]cloud.TopicService
+	timeProvider provider.TimeProvider
+
+	batchSize   int
+	maxAttempts int
+}
+
+func NewService(
+	repository repository.OutboxRepository,
+	topics []cloud.TopicService,
+	timeProvider provider.TimeProvider,
+	batchSize int,
+	maxAttempts int,
+) *Service {
+	topicsByName := make(map[string]cloud.TopicService, len(topics))
+	for _, topic := range topics {
+		topicsByName[topic.GetTopicName()] = topic
+	}
+
+	return &Service{
+		repository:   repository,
+		topics:       topicsByName,
+		timeProvider: timeProvider,
+
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Handle publishes one batch of pending messages, returning how many were sent
+func (s *Service) Handle(ctx context.Context) (int, error) {
+	now := s.timeProvider.GetTime()
+
+	messages, err := s.repository.ClaimPending(ctx, s.batchSize, now, now.Add(leaseDuration))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+
+	for i := range messages {
+		message := &messages[i]
+
+		if err := s.publish(ctx, message); err != nil {
+			message.MarkAsFailed(err, s.maxAttempts, s.timeProvider.GetTime())
+
+			slog.ErrorContext(ctx, "error publishing outbox message", "outbox_id", message.Id, "topic", message.Topic, "attempts", message.Attempts, "state", message.State.String(), "error", err)
+		} else {
+			sent++
+		}
+
+		if err := s.repository.Update(ctx, message); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *Service) publish(ctx context.Context, message *outbox_entity.Message) error {
+	topic, ok := s.topics[message.Topic]
+	if !ok {
+		return fmt.Errorf("unknown topic: %s", message.Topic)
+	}
+
+	// the message is published within the trace of the operation that stored it
+	ctx = tracing.Extract(ctx, message.TraceContext)
+
+	messageId, err := topic.PublishMessage(ctx, cloud.Event{
+		Id:      message.Id,
+		Type:    message.EventType,
+		Subject: message.Subject,
+		Time:    message.CreatedAt,
+		Data:    json.RawMessage(message.Payload),
+	})
+	if err != nil {
+		return err
+	}
+
+	message.MarkAsSent(*messageId, s.timeProvider.GetTime())
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
+	provider_mock "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTopic(t *testing.T) *mocks.MockTopicService {
+	topic := mocks.NewMockTopicService(t)
+
+	topic.On("GetTopicName").
+		Return("topic-name").
+		Once()
+
+	return topic
+}
+
+func TestHandle(t *testing.T) {
+	t.Run("Should publish the pending messages and mark them as sent", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOutboxRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+		topic := newTopic(t)
+
+		message, err := outbox_entity.NewEventMessage("topic-name", "OrderCreated", "123", map[string]string{"order_id": "123"}, now)
+		assert.NoError(t, err)
+
+		messageId := "message-id"
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPending", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]outbox_entity.Message{message}, nil).
+			Once()
+
+		topic.On("PublishMessage", ctx, cloud.Event{
+			Id:      message.Id,
+			Type:    "OrderCreated",
+			Subject: "123",
+			Time:    now,
+			Data:    json.RawMessage(`{"order_id":"123"}`),
+		}).
+			Return(&messageId, nil).
+			Once()
+
+		repository.On("Update", ctx, mock.MatchedBy(func(message *outbox_entity.Message) bool {
+			return message.State == outbox_entity.Sent && message.MessageId == messageId
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, []cloud.TopicService{topic}, timeProvider, 10, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		repository.AssertExpectations(t)
+		topic.AssertExpectations(t)
+	})
+
+	t.Run("Should schedule a retry when the publish fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOutboxRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+		topic := newTopic(t)
+
+		message, err := outbox_entity.NewMessage("topic-name", "payload", now)
+		assert.NoError(t, err)
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPending", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]outbox_entity.Message{message}, nil).
+			Once()
+
+		topic.On("PublishMessage", ctx, mock.Anything).
+			Return(nil, assert.AnError).
+			Once()
+
+		repository.On("Update", ctx, mock.MatchedBy(func(message *outbox_entity.Message) bool {
+			return message.State == outbox_entity.Pending && message.Attempts == 1 && message.NextAttemptAt.After(now)
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, []cloud.TopicService{topic}, timeProvider, 10, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+		topic.AssertExpectations(t)
+	})
+
+	t.Run("Should fail the message when the topic is unknown", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOutboxRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+		topic := newTopic(t)
+
+		message, err := outbox_entity.NewMessage("unknown-topic", "payload", now)
+		assert.NoError(t, err)
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPending", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]outbox_entity.Message{message}, nil).
+			Once()
+
+		repository.On("Update", ctx, mock.MatchedBy(func(message *outbox_entity.Message) bool {
+			return message.State == outbox_entity.Failed
+		})).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, []cloud.TopicService{topic}, timeProvider, 10, 1)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+		topic.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the messages can not be claimed", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOutboxRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+		topic := newTopic(t)
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		repository.On("ClaimPending", ctx, 10, now, now.Add(leaseDuration)).
+			Return(nil, assert.AnError).
+			Once()
+
+		service := NewService(repository, []cloud.TopicService{topic}, timeProvider, 10, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the message can not be updated", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockOutboxRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+		topic := newTopic(t)
+
+		message, err := outbox_entity.NewMessage("topic-name", "payload", now)
+		assert.NoError(t, err)
+
+		messageId := "message-id"
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPending", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]outbox_entity.Message{message}, nil).
+			Once()
+
+		topic.On("PublishMessage", ctx, mock.Anything).
+			Return(&messageId, nil).
+			Once()
+
+		repository.On("Update", ctx, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		service := NewService(repository, []cloud.TopicService{topic}, timeProvider, 10, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, 1, sent)
+		repository.AssertExpectations(t)
+		topic.AssertExpectations(t)
+	})
+}
@@ -2,6 +2,7 @@ package send_to_pay
 
 import (
 	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
 )
 
@@ -18,8 +19,8 @@ type SendToPayDto struct {
 
 	Items []SendToPayItemDto `json:"items" validate:"required,dive"`
 
-	TotalItems int     `json:"total_items"`
-	Amount     float64 `json:"amount"`
+	TotalItems int          `json:"total_items"`
+	Amount     common.Money `json:"amount"`
 }
 
 func (dto *SendToPayDto) Validate() error {
@@ -6,26 +6,34 @@ import (
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
 )
 
+// PaymentRequestEventType is the type of the request sent to the payment
+// service, when published with an envelope
+const PaymentRequestEventType = "PaymentRequest"
+
 type Service struct {
-	topic        cloud.TopicService
-	repository   repository.PaymentRepository
-	timeProvider provider.TimeProvider
+	topic            cloud.TopicService
+	repository       repository.PaymentRepository
+	outboxRepository repository.OutboxRepository
+	timeProvider     provider.TimeProvider
 }
 
 func NewService(
 	topic cloud.TopicService,
 	repository repository.PaymentRepository,
+	outboxRepository repository.OutboxRepository,
 	timeProvider provider.TimeProvider,
 ) *Service {
 	return &Service{
-		topic:        topic,
-		repository:   repository,
-		timeProvider: timeProvider,
+		topic:            topic,
+		repository:       repository,
+		outboxRepository: outboxRepository,
+		timeProvider:     timeProvider,
 	}
 }
 
@@ -39,25 +47,32 @@ func (s *Service) Handle(ctx context.Context, order *order_entity.Order, request
 	request.TotalItems = order.TotalItems
 	request.Amount = order.TotalPrice
 
-	messageId, err := s.topic.PublishMessage(ctx, request)
+	now := s.timeProvider.GetTime()
+
+	message, err := outbox_entity.NewEventMessage(s.topic.GetTopicName(), PaymentRequestEventType, order.Id, request, now)
 	if err != nil {
 		return err
 	}
 
-	slog.InfoContext(ctx, "message sent to topic", "topic", s.topic.GetTopicName(), "message_id", *messageId)
-
-	payment := payment_entity.NewPayment(
-		order.Id,
-		request.PaymentId,
-		order.TotalItems,
-		order.TotalPrice,
-		s.timeProvider.GetTime(),
-	)
+	if request.Resend {
+		if err := s.outboxRepository.Create(ctx, &message); err != nil {
+			return err
+		}
+	} else {
+		payment := payment_entity.NewPayment(
+			order.Id,
+			request.PaymentId,
+			order.TotalItems,
+			order.TotalPrice,
+			now,
+		)
 
-	if !request.Resend {
-		if err := s.repository.Create(ctx, &payment); err != nil {
+		if err := s.repository.CreateWithMessage(ctx, &payment, &message, order.Version); err != nil {
 			return err
 		}
 	}
+
+	slog.InfoContext(ctx, "message stored in the outbox", "topic", message.Topic, "outbox_id", message.Id)
+
 	return nil
 }
@@ -7,30 +7,101 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/adapter/cloud/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/outbox_entity"
 	provider_mock "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
 	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func newOrder() *order_entity.Order {
+	return &order_entity.Order{
+		Id: uuid.NewString(),
+		Items: []order_entity.Item{
+			{
+				Id:        uuid.NewString(),
+				Name:      "name",
+				UnitPrice: common.NewMoney(1050, common.DefaultCurrency),
+				Quantity:  1,
+			},
+		},
+	}
+}
+
+func newRequest() SendToPayDto {
+	return SendToPayDto{
+		OrderID:   uuid.NewString(),
+		PaymentId: uuid.NewString(),
+		Items: []SendToPayItemDto{
+			{
+				Id:       uuid.NewString(),
+				Name:     "name",
+				Quantity: 1,
+			},
+		},
+		TotalItems: 1,
+		Amount:     common.NewMoney(1050, common.DefaultCurrency),
+	}
+}
+
 func TestHandle(t *testing.T) {
-	t.Run("Should return nil when message is sent", func(t *testing.T) {
+	t.Run("Should store the payment and the message in the outbox", func(t *testing.T) {
 		// Arrange
 		ctx := context.Background()
 
-		messageId := "message-id"
-
 		topicService := mocks.NewMockTopicService(t)
 		repository := repository_mock.NewMockPaymentRepository(t)
+		outboxRepository := repository_mock.NewMockOutboxRepository(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		topicService.On("GetTopicName").
 			Return("topic-name").
 			Once()
 
-		topicService.On("PublishMessage", ctx, mock.Anything).
-			Return(&messageId, nil).
+		now := time.Now()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		order := newOrder()
+		order.Version = 3
+
+		repository.On("CreateWithMessage", ctx, mock.Anything, mock.MatchedBy(func(message *outbox_entity.Message) bool {
+			return message.Topic == "topic-name" &&
+				message.State == outbox_entity.Pending &&
+				message.EventType == PaymentRequestEventType &&
+				message.Subject == order.Id
+		}), 3).
+			Return(nil).
+			Once()
+
+		service := NewService(topicService, repository, outboxRepository, timeProvider)
+
+		// Act
+		err := service.Handle(ctx, order, newRequest())
+
+		// Assert
+		assert.NoError(t, err)
+		topicService.AssertExpectations(t)
+		repository.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should only store the message in the outbox when resending", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		topicService := mocks.NewMockTopicService(t)
+		repository := repository_mock.NewMockPaymentRepository(t)
+		outboxRepository := repository_mock.NewMockOutboxRepository(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		topicService.On("GetTopicName").
+			Return("topic-name").
 			Once()
 
 		now := time.Now()
@@ -39,45 +110,23 @@ func TestHandle(t *testing.T) {
 			Return(now).
 			Once()
 
-		repository.On("Create", ctx, mock.Anything).
+		outboxRepository.On("Create", ctx, mock.Anything).
 			Return(nil).
 			Once()
 
-		service := NewService(topicService, repository, timeProvider)
-
-		order := &order_entity.Order{
-			Id: uuid.NewString(),
-			Items: []order_entity.Item{
-				{
-					Id:        uuid.NewString(),
-					Name:      "name",
-					UnitPrice: 10.5,
-					Quantity:  1,
-				},
-			},
-		}
-
-		req := SendToPayDto{
-			OrderID:   uuid.NewString(),
-			PaymentId: uuid.NewString(),
-			Items: []SendToPayItemDto{
-				{
-					Id:       uuid.NewString(),
-					Name:     "name",
-					Quantity: 1,
-				},
-			},
-			TotalItems: 1,
-			Amount:     10.5,
-		}
+		service := NewService(topicService, repository, outboxRepository, timeProvider)
+
+		req := newRequest()
+		req.Resend = true
 
 		// Act
-		err := service.Handle(ctx, order, req)
+		err := service.Handle(ctx, newOrder(), req)
 
 		// Assert
 		assert.NoError(t, err)
 		topicService.AssertExpectations(t)
 		repository.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
 		timeProvider.AssertExpectations(t)
 	})
 
@@ -87,153 +136,97 @@ func TestHandle(t *testing.T) {
 
 		topicService := mocks.NewMockTopicService(t)
 		repository := repository_mock.NewMockPaymentRepository(t)
+		outboxRepository := repository_mock.NewMockOutboxRepository(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
-		service := NewService(topicService, repository, timeProvider)
+		service := NewService(topicService, repository, outboxRepository, timeProvider)
 
-		order := &order_entity.Order{
-			Id: uuid.NewString(),
-			Items: []order_entity.Item{
-				{
-					UnitPrice: 10.5,
-					Quantity:  1,
-				},
-			},
-		}
-
-		req := SendToPayDto{
-			OrderID:   "",
-			PaymentId: uuid.NewString(),
-			Items: []SendToPayItemDto{
-				{
-					Id:       uuid.NewString(),
-					Name:     "name",
-					Quantity: 1,
-				},
-			},
-			TotalItems: 1,
-			Amount:     10.5,
-		}
+		req := newRequest()
+		req.OrderID = ""
 
 		// Act
-		err := service.Handle(ctx, order, req)
+		err := service.Handle(ctx, newOrder(), req)
 
 		// Assert
 		assert.Error(t, err)
 		topicService.AssertExpectations(t)
 		repository.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
 		timeProvider.AssertExpectations(t)
 	})
 
-	t.Run("Should return error when message is not sent", func(t *testing.T) {
+	t.Run("Should return error when payment is not created", func(t *testing.T) {
 		// Arrange
 		ctx := context.Background()
 
 		topicService := mocks.NewMockTopicService(t)
 		repository := repository_mock.NewMockPaymentRepository(t)
+		outboxRepository := repository_mock.NewMockOutboxRepository(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
-		topicService.On("PublishMessage", ctx, mock.Anything).
-			Return(nil, assert.AnError).
+		topicService.On("GetTopicName").
+			Return("topic-name").
+			Once()
+
+		now := time.Now()
+
+		timeProvider.On("GetTime").
+			Return(now).
 			Once()
 
-		service := NewService(topicService, repository, timeProvider)
+		repository.On("CreateWithMessage", ctx, mock.Anything, mock.Anything, mock.Anything).
+			Return(assert.AnError).
+			Once()
 
-		order := &order_entity.Order{
-			Id: uuid.NewString(),
-			Items: []order_entity.Item{
-				{
-					UnitPrice: 10.5,
-					Quantity:  1,
-				},
-			},
-		}
-
-		req := SendToPayDto{
-			OrderID:   uuid.NewString(),
-			PaymentId: uuid.NewString(),
-			Items: []SendToPayItemDto{
-				{
-					Id:       uuid.NewString(),
-					Name:     "name",
-					Quantity: 1,
-				},
-			},
-			TotalItems: 1,
-			Amount:     10.5,
-		}
+		service := NewService(topicService, repository, outboxRepository, timeProvider)
 
 		// Act
-		err := service.Handle(ctx, order, req)
+		err := service.Handle(ctx, newOrder(), newRequest())
 
 		// Assert
 		assert.Error(t, err)
 		topicService.AssertExpectations(t)
 		repository.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
 		timeProvider.AssertExpectations(t)
 	})
 
-	t.Run("Should return error when payment is not created", func(t *testing.T) {
+	t.Run("Should return error when message is not stored when resending", func(t *testing.T) {
 		// Arrange
 		ctx := context.Background()
 
-		messageId := "message-id"
-
 		topicService := mocks.NewMockTopicService(t)
 		repository := repository_mock.NewMockPaymentRepository(t)
+		outboxRepository := repository_mock.NewMockOutboxRepository(t)
 		timeProvider := provider_mock.NewMockTimeProvider(t)
 
 		topicService.On("GetTopicName").
 			Return("topic-name").
 			Once()
 
-		topicService.On("PublishMessage", ctx, mock.Anything).
-			Return(&messageId, nil).
-			Once()
-
 		now := time.Now()
 
 		timeProvider.On("GetTime").
 			Return(now).
 			Once()
 
-		repository.On("Create", ctx, mock.Anything).
+		outboxRepository.On("Create", ctx, mock.Anything).
 			Return(assert.AnError).
 			Once()
 
-		service := NewService(topicService, repository, timeProvider)
+		service := NewService(topicService, repository, outboxRepository, timeProvider)
 
-		order := &order_entity.Order{
-			Id: uuid.NewString(),
-			Items: []order_entity.Item{
-				{
-					UnitPrice: 10.5,
-					Quantity:  1,
-				},
-			},
-		}
-
-		req := SendToPayDto{
-			OrderID:   uuid.NewString(),
-			PaymentId: uuid.NewString(),
-			Items: []SendToPayItemDto{
-				{
-					Id:       uuid.NewString(),
-					Name:     "name",
-					Quantity: 1,
-				},
-			},
-			TotalItems: 1,
-			Amount:     10.5,
-		}
+		req := newRequest()
+		req.Resend = true
 
 		// Act
-		err := service.Handle(ctx, order, req)
+		err := service.Handle(ctx, newOrder(), req)
 
 		// Assert
 		assert.Error(t, err)
 		topicService.AssertExpectations(t)
 		repository.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
 		timeProvider.AssertExpectations(t)
 	})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
 )
 
 type CreateOrderService[T any] interface {
@@ -18,6 +19,10 @@ type GetOrdersService[T any] interface {
 	Handle(ctx context.Context, request T) (int, []order_entity.Order, error)
 }
 
+type GetOrderHistoryService[T any] interface {
+	Handle(ctx context.Context, request T) ([]order_entity.StateTransition, error)
+}
+
 type UpdateOrderService[T any] interface {
 	Handle(ctx context.Context, order *order_entity.Order, request T) error
 }
@@ -30,6 +35,46 @@ type SendToPayService[T any] interface {
 
 // ---
 
+type CreateWebhookService[T any] interface {
+	Handle(ctx context.Context, request T) (*webhook_entity.Subscription, error)
+}
+
+type GetWebhooksService interface {
+	Handle(ctx context.Context) ([]webhook_entity.Subscription, error)
+}
+
+type UpdateWebhookService[T any] interface {
+	Handle(ctx context.Context, request T) (webhook_entity.Subscription, error)
+}
+
+type DeleteWebhookService[T any] interface {
+	Handle(ctx context.Context, request T) error
+}
+
+type GetWebhookDeliveriesService[T any] interface {
+	Handle(ctx context.Context, request T) (int, []webhook_entity.Attempt, error)
+}
+
+// ---
+
+type RelayOutboxService interface {
+	Handle(ctx context.Context) (int, error)
+}
+
+type DispatchWebhooksService interface {
+	Handle(ctx context.Context) (int, error)
+}
+
+type PurgeInboxService interface {
+	Handle(ctx context.Context) (int64, error)
+}
+
+type PurgeIdempotencyKeysService interface {
+	Handle(ctx context.Context) (int64, error)
+}
+
+// ---
+
 type ProcessMessageService[T any] interface {
 	Handle(ctx context.Context, message T) error
 }
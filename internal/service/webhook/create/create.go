@@ -0,0 +1,38 @@
+package create
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository   repository.WebhookRepository
+	timeProvider provider.TimeProvider
+}
+
+func NewService(
+	repository repository.WebhookRepository,
+	timeProvider provider.TimeProvider,
+) *Service {
+	return &Service{
+		repository:   repository,
+		timeProvider: timeProvider,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context, request CreateWebhookDto) (*webhook_entity.Subscription, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	subscription := webhook_entity.NewSubscription(request.Url, request.Secret, request.GetEventTypes(), s.timeProvider.GetTime())
+
+	if err := s.repository.CreateSubscription(ctx, &subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
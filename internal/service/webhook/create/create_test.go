@@ -0,0 +1,106 @@
+package create
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	provider_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	repository_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should create an active subscription", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		repository.On("CreateSubscription", ctx, mock.Anything).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		req := CreateWebhookDto{
+			Url:        "https://partner.com/hook",
+			Secret:     "0123456789abcdef",
+			EventTypes: []string{"order.state_changed"},
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Id)
+		assert.True(t, res.Active)
+		assert.Equal(t, []webhook_entity.EventType{webhook_entity.OrderStateChanged}, res.EventTypes)
+		assert.Equal(t, now, res.CreatedAt)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when request is invalid", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		service := NewService(repository, timeProvider)
+
+		// Act
+		res, err := service.Handle(ctx, CreateWebhookDto{})
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the subscription can not be created", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(time.Now()).
+			Once()
+
+		repository.On("CreateSubscription", ctx, mock.Anything).
+			Return(assert.AnError).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		req := CreateWebhookDto{
+			Url:        "https://partner.com/hook",
+			Secret:     "0123456789abcdef",
+			EventTypes: []string{"order.state_changed"},
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,32 @@
+package create
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type CreateWebhookDto struct {
+	Url        string   `json:"url" validate:"required,http_url,max=2048"`
+	Secret     string   `json:"secret" validate:"required,min=16,max=255"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=order.state_changed payment.state_changed"`
+}
+
+func (dto *CreateWebhookDto) GetEventTypes() []webhook_entity.EventType {
+	eventTypes := make([]webhook_entity.EventType, 0, len(dto.EventTypes))
+	for _, eventType := range dto.EventTypes {
+		eventTypes = append(eventTypes, webhook_entity.EventType(eventType))
+	}
+
+	return eventTypes
+}
+
+func (dto *CreateWebhookDto) Validate() error {
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package create
+
+import (
+	"testing"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Should return nil when dto is valid", func(t *testing.T) {
+		// Arrange
+		dto := CreateWebhookDto{
+			Url:        "https://partner.com/hook",
+			Secret:     "0123456789abcdef",
+			EventTypes: []string{"order.state_changed", "payment.state_changed"},
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error when the url is not valid", func(t *testing.T) {
+		// Arrange
+		dto := CreateWebhookDto{
+			Url:        "partner.com",
+			Secret:     "0123456789abcdef",
+			EventTypes: []string{"order.state_changed"},
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+
+	t.Run("Should return error when the secret is too short", func(t *testing.T) {
+		// Arrange
+		dto := CreateWebhookDto{
+			Url:        "https://partner.com/hook",
+			Secret:     "secret",
+			EventTypes: []string{"order.state_changed"},
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+
+	t.Run("Should return error when an event type is unknown", func(t *testing.T) {
+		// Arrange
+		dto := CreateWebhookDto{
+			Url:        "https://partner.com/hook",
+			Secret:     "0123456789abcdef",
+			EventTypes: []string{"order.deleted"},
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+
+	t.Run("Should return error when dto is empty", func(t *testing.T) {
+		// Arrange
+		dto := CreateWebhookDto{}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+}
@@ -0,0 +1,114 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/webhook"
+)
+
+// leaseDuration is how long a claimed delivery stays hidden from other
+// dispatchers while it is being sent
+const leaseDuration = 5 * time.Minute
+
+type Service struct {
+	repository   repository.WebhookRepository
+	sender       webhook.Sender
+	timeProvider provider.TimeProvider
+
+	batchSize    int
+	maxAttempts  int
+	disableAfter int
+}
+
+func NewService(
+	repository repository.WebhookRepository,
+	sender webhook.Sender,
+	timeProvider provider.TimeProvider,
+	batchSize int,
+	maxAttempts int,
+	disableAfter int,
+) *Service {
+	return &Service{
+		repository:   repository,
+		sender:       sender,
+		timeProvider: timeProvider,
+
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+		disableAfter: disableAfter,
+	}
+}
+
+// Handle sends one batch of pending deliveries, returning how many were
+// acknowledged by the subscribers
+func (s *Service) Handle(ctx context.Context) (int, error) {
+	now := s.timeProvider.GetTime()
+
+	deliveries, err := s.repository.ClaimPendingDeliveries(ctx, s.batchSize, now, now.Add(leaseDuration))
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[string]*webhook_entity.Subscription)
+
+	sent := 0
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		subscription, ok := subscriptions[delivery.SubscriptionId]
+		if !ok {
+			found, err := s.repository.GetSubscriptionByID(ctx, delivery.SubscriptionId)
+			if errors.Is(err, custom_error.ErrWebhookNotFound) {
+				// deleted meanwhile, its deliveries are gone along with it
+				continue
+			}
+
+			if err != nil {
+				return sent, err
+			}
+
+			subscription = &found
+			subscriptions[delivery.SubscriptionId] = subscription
+		}
+
+		// disabled by a failure of this batch, the rest waits for it to be enabled
+		if !subscription.Active {
+			continue
+		}
+
+		startedAt := s.timeProvider.GetTime()
+
+		statusCode, err := s.sender.Send(ctx, *subscription, *delivery)
+
+		finishedAt := s.timeProvider.GetTime()
+
+		if err != nil {
+			delivery.MarkAsFailed(statusCode, err, s.maxAttempts, finishedAt)
+
+			slog.ErrorContext(ctx, "error sending webhook", "delivery_id", delivery.Id, "subscription_id", subscription.Id, "status_code", statusCode, "attempts", delivery.Attempts, "state", delivery.State.String(), "error", err)
+		} else {
+			delivery.MarkAsDelivered(statusCode, finishedAt)
+			sent++
+		}
+
+		attempt := webhook_entity.NewAttempt(*delivery, finishedAt.Sub(startedAt), finishedAt)
+
+		if err := s.repository.RecordAttempt(ctx, delivery, attempt, subscription, s.disableAfter); err != nil {
+			return sent, err
+		}
+
+		if !subscription.Active {
+			slog.WarnContext(ctx, "webhook subscription disabled after consecutive failures", "subscription_id", subscription.Id, "consecutive_failures", subscription.ConsecutiveFailures)
+		}
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,247 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	provider_mock "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	repository_mock "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	webhook_mock "github.com/jfelipearaujo-org/ms-order-management/internal/shared/webhook/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newDelivery(subscriptionId string) webhook_entity.Delivery {
+	return webhook_entity.Delivery{
+		Id:             "delivery-id",
+		SubscriptionId: subscriptionId,
+		EventId:        "event-id",
+		EventType:      webhook_entity.OrderStateChanged,
+		Payload:        `{"id":"event-id"}`,
+		State:          webhook_entity.Pending,
+	}
+}
+
+func TestHandle(t *testing.T) {
+	t.Run("Should send the pending deliveries and log the attempts", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockWebhookRepository(t)
+		sender := webhook_mock.NewMockSender(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+		subscription.ConsecutiveFailures = 2
+
+		delivery := newDelivery(subscription.Id)
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPendingDeliveries", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]webhook_entity.Delivery{delivery}, nil).
+			Once()
+
+		repository.On("GetSubscriptionByID", ctx, subscription.Id).
+			Return(subscription, nil).
+			Once()
+
+		sender.On("Send", ctx, subscription, delivery).
+			Return(200, nil).
+			Once()
+
+		repository.On("RecordAttempt", ctx,
+			mock.MatchedBy(func(delivery *webhook_entity.Delivery) bool {
+				return delivery.State == webhook_entity.Delivered && delivery.Attempts == 1
+			}),
+			mock.MatchedBy(func(attempt webhook_entity.Attempt) bool {
+				return attempt.StatusCode == 200 && attempt.Number == 1
+			}),
+			mock.AnythingOfType("*webhook_entity.Subscription"),
+			3).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, sender, timeProvider, 10, 5, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		repository.AssertExpectations(t)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("Should schedule a retry when the subscriber fails", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockWebhookRepository(t)
+		sender := webhook_mock.NewMockSender(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := newDelivery(subscription.Id)
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPendingDeliveries", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]webhook_entity.Delivery{delivery}, nil).
+			Once()
+
+		repository.On("GetSubscriptionByID", ctx, subscription.Id).
+			Return(subscription, nil).
+			Once()
+
+		sender.On("Send", ctx, subscription, delivery).
+			Return(503, assert.AnError).
+			Once()
+
+		repository.On("RecordAttempt", ctx,
+			mock.MatchedBy(func(delivery *webhook_entity.Delivery) bool {
+				return delivery.State == webhook_entity.Pending && delivery.LastStatusCode == 503 && delivery.NextAttemptAt.After(now)
+			}),
+			mock.MatchedBy(func(attempt webhook_entity.Attempt) bool {
+				return attempt.StatusCode == 503 && attempt.Error == assert.AnError.Error()
+			}),
+			mock.AnythingOfType("*webhook_entity.Subscription"),
+			3).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, sender, timeProvider, 10, 5, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("Should disable the subscription and hold its other deliveries", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockWebhookRepository(t)
+		sender := webhook_mock.NewMockSender(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+		subscription.ConsecutiveFailures = 2
+
+		first := newDelivery(subscription.Id)
+		second := newDelivery(subscription.Id)
+		second.Id = "other-delivery-id"
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPendingDeliveries", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]webhook_entity.Delivery{first, second}, nil).
+			Once()
+
+		repository.On("GetSubscriptionByID", ctx, subscription.Id).
+			Return(subscription, nil).
+			Once()
+
+		sender.On("Send", ctx, subscription, first).
+			Return(0, assert.AnError).
+			Once()
+
+		repository.On("RecordAttempt", ctx, mock.Anything, mock.Anything, mock.AnythingOfType("*webhook_entity.Subscription"), 3).
+			Run(func(args mock.Arguments) {
+				args.Get(3).(*webhook_entity.Subscription).Disable(now)
+			}).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, sender, timeProvider, 10, 5, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("Should skip the deliveries of a deleted subscription", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockWebhookRepository(t)
+		sender := webhook_mock.NewMockSender(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPendingDeliveries", ctx, 10, now, now.Add(leaseDuration)).
+			Return([]webhook_entity.Delivery{newDelivery("subscription-id")}, nil).
+			Once()
+
+		repository.On("GetSubscriptionByID", ctx, "subscription-id").
+			Return(webhook_entity.Subscription{}, custom_error.ErrWebhookNotFound).
+			Once()
+
+		service := NewService(repository, sender, timeProvider, 10, 5, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the deliveries can not be claimed", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		repository := repository_mock.NewMockWebhookRepository(t)
+		sender := webhook_mock.NewMockSender(t)
+		timeProvider := provider_mock.NewMockTimeProvider(t)
+
+		timeProvider.On("GetTime").
+			Return(now)
+
+		repository.On("ClaimPendingDeliveries", ctx, 10, now, now.Add(leaseDuration)).
+			Return(nil, assert.AnError).
+			Once()
+
+		service := NewService(repository, sender, timeProvider, 10, 5, 3)
+
+		// Act
+		sent, err := service.Handle(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 0, sent)
+		repository.AssertExpectations(t)
+	})
+}
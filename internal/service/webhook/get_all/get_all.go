@@ -0,0 +1,22 @@
+package get_all
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository repository.WebhookRepository
+}
+
+func NewService(repository repository.WebhookRepository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context) ([]webhook_entity.Subscription, error) {
+	return s.repository.GetAllSubscriptions(ctx)
+}
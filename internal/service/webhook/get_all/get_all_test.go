@@ -0,0 +1,59 @@
+package get_all
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return all the subscriptions", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		subscriptions := []webhook_entity.Subscription{
+			webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now()),
+		}
+
+		repository := mocks.NewMockWebhookRepository(t)
+
+		repository.On("GetAllSubscriptions", ctx).
+			Return(subscriptions, nil).
+			Once()
+
+		service := NewService(repository)
+
+		// Act
+		res, err := service.Handle(ctx)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, subscriptions, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the subscriptions can not be listed", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := mocks.NewMockWebhookRepository(t)
+
+		repository.On("GetAllSubscriptions", ctx).
+			Return(nil, assert.AnError).
+			Once()
+
+		service := NewService(repository)
+
+		// Act
+		res, err := service.Handle(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+	})
+}
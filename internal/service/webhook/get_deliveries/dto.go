@@ -0,0 +1,23 @@
+package get_deliveries
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type GetWebhookDeliveriesDto struct {
+	Id string `param:"id" validate:"required,uuid4"`
+
+	common.Pagination
+}
+
+func (dto *GetWebhookDeliveriesDto) Validate() error {
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	return nil
+}
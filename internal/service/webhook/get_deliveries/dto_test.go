@@ -0,0 +1,35 @@
+package get_deliveries
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Should return nil when dto is valid", func(t *testing.T) {
+		// Arrange
+		dto := GetWebhookDeliveriesDto{
+			Id: uuid.NewString(),
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error when dto is invalid", func(t *testing.T) {
+		// Arrange
+		dto := GetWebhookDeliveriesDto{}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+}
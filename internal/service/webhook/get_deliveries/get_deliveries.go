@@ -0,0 +1,32 @@
+package get_deliveries
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository repository.WebhookRepository
+}
+
+func NewService(repository repository.WebhookRepository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context, request GetWebhookDeliveriesDto) (int, []webhook_entity.Attempt, error) {
+	request.SetDefaults()
+
+	if err := request.Validate(); err != nil {
+		return 0, nil, err
+	}
+
+	if _, err := s.repository.GetSubscriptionByID(ctx, request.Id); err != nil {
+		return 0, nil, err
+	}
+
+	return s.repository.GetAttempts(ctx, request.Id, request.Pagination)
+}
@@ -0,0 +1,72 @@
+package get_deliveries
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/common"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should return a page of the delivery log", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		id := uuid.NewString()
+
+		attempts := []webhook_entity.Attempt{
+			{Id: uuid.NewString(), SubscriptionId: id, StatusCode: 200, AttemptedAt: time.Now()},
+		}
+
+		repository := mocks.NewMockWebhookRepository(t)
+
+		repository.On("GetSubscriptionByID", ctx, id).
+			Return(webhook_entity.Subscription{Id: id}, nil).
+			Once()
+
+		repository.On("GetAttempts", ctx, id, common.Pagination{Page: 1, Size: 10}).
+			Return(1, attempts, nil).
+			Once()
+
+		service := NewService(repository)
+
+		// Act
+		count, res, err := service.Handle(ctx, GetWebhookDeliveriesDto{Id: id})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, attempts, res)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the subscription is not found", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		id := uuid.NewString()
+
+		repository := mocks.NewMockWebhookRepository(t)
+
+		repository.On("GetSubscriptionByID", ctx, id).
+			Return(webhook_entity.Subscription{}, custom_error.ErrWebhookNotFound).
+			Once()
+
+		service := NewService(repository)
+
+		// Act
+		count, res, err := service.Handle(ctx, GetWebhookDeliveriesDto{Id: id})
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrWebhookNotFound)
+		assert.Equal(t, 0, count)
+		assert.Nil(t, res)
+		repository.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,25 @@
+package remove
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository repository.WebhookRepository
+}
+
+func NewService(repository repository.WebhookRepository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context, request DeleteWebhookDto) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteSubscription(ctx, request.Id)
+}
@@ -0,0 +1,51 @@
+package remove
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should delete the subscription", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		id := uuid.NewString()
+
+		repository := mocks.NewMockWebhookRepository(t)
+
+		repository.On("DeleteSubscription", ctx, id).
+			Return(nil).
+			Once()
+
+		service := NewService(repository)
+
+		// Act
+		err := service.Handle(ctx, DeleteWebhookDto{Id: id})
+
+		// Assert
+		assert.NoError(t, err)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when request is invalid", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := mocks.NewMockWebhookRepository(t)
+
+		service := NewService(repository)
+
+		// Act
+		err := service.Handle(ctx, DeleteWebhookDto{})
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+		repository.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,35 @@
+package update
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+// UpdateWebhookDto only changes the fields that were sent
+type UpdateWebhookDto struct {
+	Id         string   `param:"id" validate:"required,uuid4"`
+	Url        string   `json:"url" validate:"omitempty,http_url,max=2048"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	EventTypes []string `json:"event_types" validate:"omitempty,min=1,dive,oneof=order.state_changed payment.state_changed"`
+	Active     *bool    `json:"active"`
+}
+
+func (dto *UpdateWebhookDto) GetEventTypes() []webhook_entity.EventType {
+	eventTypes := make([]webhook_entity.EventType, 0, len(dto.EventTypes))
+	for _, eventType := range dto.EventTypes {
+		eventTypes = append(eventTypes, webhook_entity.EventType(eventType))
+	}
+
+	return eventTypes
+}
+
+func (dto *UpdateWebhookDto) Validate() error {
+	validator := validator.New()
+
+	if err := validator.Struct(dto); err != nil {
+		return custom_error.ErrRequestNotValid
+	}
+
+	return nil
+}
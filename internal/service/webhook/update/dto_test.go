@@ -0,0 +1,51 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Should return nil when only the id is set", func(t *testing.T) {
+		// Arrange
+		dto := UpdateWebhookDto{
+			Id: uuid.NewString(),
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error when the id is not valid", func(t *testing.T) {
+		// Arrange
+		dto := UpdateWebhookDto{
+			Id: "abc",
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+
+	t.Run("Should return error when an event type is unknown", func(t *testing.T) {
+		// Arrange
+		dto := UpdateWebhookDto{
+			Id:         uuid.NewString(),
+			EventTypes: []string{"order.deleted"},
+		}
+
+		// Act
+		err := dto.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+	})
+}
@@ -0,0 +1,67 @@
+package update
+
+import (
+	"context"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+)
+
+type Service struct {
+	repository   repository.WebhookRepository
+	timeProvider provider.TimeProvider
+}
+
+func NewService(
+	repository repository.WebhookRepository,
+	timeProvider provider.TimeProvider,
+) *Service {
+	return &Service{
+		repository:   repository,
+		timeProvider: timeProvider,
+	}
+}
+
+func (s *Service) Handle(ctx context.Context, request UpdateWebhookDto) (webhook_entity.Subscription, error) {
+	if err := request.Validate(); err != nil {
+		return webhook_entity.Subscription{}, err
+	}
+
+	subscription, err := s.repository.GetSubscriptionByID(ctx, request.Id)
+	if err != nil {
+		return webhook_entity.Subscription{}, err
+	}
+
+	now := s.timeProvider.GetTime()
+
+	if request.Url != "" {
+		subscription.Url = request.Url
+	}
+
+	if request.Secret != "" {
+		subscription.Secret = request.Secret
+	}
+
+	if len(request.EventTypes) > 0 {
+		subscription.EventTypes = request.GetEventTypes()
+	}
+
+	// enabling again is how a subscription disabled by its failures comes back,
+	// its pending deliveries are sent from then on
+	if request.Active != nil && *request.Active {
+		subscription.Enable(now)
+	}
+
+	if request.Active != nil && !*request.Active {
+		subscription.Disable(now)
+	}
+
+	subscription.UpdatedAt = now
+
+	if err := s.repository.UpdateSubscription(ctx, &subscription); err != nil {
+		return webhook_entity.Subscription{}, err
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,147 @@
+package update
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	provider_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	repository_mocks "github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandle(t *testing.T) {
+	t.Run("Should update the sent fields", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now.Add(-time.Hour))
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		repository.On("GetSubscriptionByID", ctx, subscription.Id).
+			Return(subscription, nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		repository.On("UpdateSubscription", ctx, mock.Anything).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		req := UpdateWebhookDto{
+			Id:         subscription.Id,
+			EventTypes: []string{"payment.state_changed"},
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "https://partner.com/hook", res.Url)
+		assert.Equal(t, []webhook_entity.EventType{webhook_entity.PaymentStateChanged}, res.EventTypes)
+		assert.Equal(t, now, res.UpdatedAt)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should enable a subscription disabled by its failures", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		now := time.Now()
+
+		subscription := webhook_entity.NewSubscription("https://partner.com/hook", "0123456789abcdef", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+		subscription.ConsecutiveFailures = 20
+		subscription.Disable(now)
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		repository.On("GetSubscriptionByID", ctx, subscription.Id).
+			Return(subscription, nil).
+			Once()
+
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		repository.On("UpdateSubscription", ctx, mock.Anything).
+			Return(nil).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		active := true
+
+		req := UpdateWebhookDto{
+			Id:     subscription.Id,
+			Active: &active,
+		}
+
+		// Act
+		res, err := service.Handle(ctx, req)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, res.Active)
+		assert.Equal(t, 0, res.ConsecutiveFailures)
+		assert.Nil(t, res.DisabledAt)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when request is invalid", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		service := NewService(repository, timeProvider)
+
+		// Act
+		_, err := service.Handle(ctx, UpdateWebhookDto{})
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrRequestNotValid)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the subscription is not found", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		id := uuid.NewString()
+
+		repository := repository_mocks.NewMockWebhookRepository(t)
+		timeProvider := provider_mocks.NewMockTimeProvider(t)
+
+		repository.On("GetSubscriptionByID", ctx, id).
+			Return(webhook_entity.Subscription{}, custom_error.ErrWebhookNotFound).
+			Once()
+
+		service := NewService(repository, timeProvider)
+
+		// Act
+		_, err := service.Handle(ctx, UpdateWebhookDto{Id: id})
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrWebhookNotFound)
+		repository.AssertExpectations(t)
+		timeProvider.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,34 @@
+package audit
+
+import "context"
+
+const (
+	SourceHttp   = "http"
+	SourceQueue  = "sqs"
+	SourceSystem = "system"
+)
+
+type ctxKey struct{}
+
+// Origin identifies who triggered a change and through which channel
+type Origin struct {
+	Actor    string
+	Source   string
+	SourceId string
+}
+
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, ctxKey{}, origin)
+}
+
+func FromContext(ctx context.Context) Origin {
+	origin, ok := ctx.Value(ctxKey{}).(Origin)
+	if !ok {
+		return Origin{
+			Actor:  SourceSystem,
+			Source: SourceSystem,
+		}
+	}
+
+	return origin
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("Should return the origin stored in the context", func(t *testing.T) {
+		// Arrange
+		origin := Origin{
+			Actor:    "user_id",
+			Source:   SourceHttp,
+			SourceId: "request_id",
+		}
+
+		ctx := WithOrigin(context.Background(), origin)
+
+		// Act
+		res := FromContext(ctx)
+
+		// Assert
+		assert.Equal(t, origin, res)
+	})
+
+	t.Run("Should return the system origin when the context has none", func(t *testing.T) {
+		// Act
+		res := FromContext(context.Background())
+
+		// Assert
+		assert.Equal(t, Origin{
+			Actor:  SourceSystem,
+			Source: SourceSystem,
+		}, res)
+	})
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+)
+
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleStaff    Role = "staff"
+	RoleAdmin    Role = "admin"
+)
+
+type ctxKey struct{}
+
+// Principal is the authenticated user of a request, a token without any known
+// role being treated as a customer
+type Principal struct {
+	UserId string
+	Roles  []Role
+}
+
+func NewPrincipal(userId string, roles []string) Principal {
+	principal := Principal{
+		UserId: userId,
+	}
+
+	for _, role := range roles {
+		switch Role(role) {
+		case RoleCustomer, RoleStaff, RoleAdmin:
+			principal.Roles = append(principal.Roles, Role(role))
+		}
+	}
+
+	if len(principal.Roles) == 0 {
+		principal.Roles = []Role{RoleCustomer}
+	}
+
+	return principal
+}
+
+func (p Principal) HasAnyRole(roles ...Role) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsStaff reports whether the principal may act on any customer order
+func (p Principal) IsStaff() bool {
+	return p.HasAnyRole(RoleStaff, RoleAdmin)
+}
+
+func (p Principal) CanAccessCustomer(customerId string) bool {
+	return p.IsStaff() || (p.UserId != "" && p.UserId == customerId)
+}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, principal)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(ctxKey{}).(Principal)
+	return principal, ok
+}
+
+// AuthorizeCustomer only lets the request principal reach the data of the given
+// customer when it is that customer or a staff member
+func AuthorizeCustomer(ctx context.Context, customerId string) error {
+	principal, ok := FromContext(ctx)
+	if !ok || !principal.CanAccessCustomer(customerId) {
+		return custom_error.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrincipal(t *testing.T) {
+	t.Run("Should keep the known roles", func(t *testing.T) {
+		// Act
+		principal := NewPrincipal("user-id", []string{"staff", "unknown", "admin"})
+
+		// Assert
+		assert.Equal(t, Principal{
+			UserId: "user-id",
+			Roles:  []Role{RoleStaff, RoleAdmin},
+		}, principal)
+	})
+
+	t.Run("Should default to the customer role", func(t *testing.T) {
+		// Act
+		principal := NewPrincipal("user-id", nil)
+
+		// Assert
+		assert.Equal(t, []Role{RoleCustomer}, principal.Roles)
+		assert.False(t, principal.IsStaff())
+	})
+}
+
+func TestAuthorizeCustomer(t *testing.T) {
+	t.Run("Should allow the customer to access its own data", func(t *testing.T) {
+		// Arrange
+		ctx := WithPrincipal(context.Background(), NewPrincipal("customer-id", nil))
+
+		// Act
+		err := AuthorizeCustomer(ctx, "customer-id")
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should not allow the customer to access another customer data", func(t *testing.T) {
+		// Arrange
+		ctx := WithPrincipal(context.Background(), NewPrincipal("customer-id", nil))
+
+		// Act
+		err := AuthorizeCustomer(ctx, "another-customer-id")
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrForbidden)
+	})
+
+	t.Run("Should allow staff and admin to access any customer data", func(t *testing.T) {
+		for _, role := range []string{"staff", "admin"} {
+			// Arrange
+			ctx := WithPrincipal(context.Background(), NewPrincipal("user-id", []string{role}))
+
+			// Act
+			err := AuthorizeCustomer(ctx, "customer-id")
+
+			// Assert
+			assert.NoError(t, err, role)
+		}
+	})
+
+	t.Run("Should not allow a request without principal", func(t *testing.T) {
+		// Act
+		err := AuthorizeCustomer(context.Background(), "customer-id")
+
+		// Assert
+		assert.ErrorIs(t, err, custom_error.ErrForbidden)
+	})
+}
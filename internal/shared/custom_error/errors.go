@@ -4,22 +4,40 @@ import "net/http"
 
 var (
 	ErrRequestNotValid BusinessError = New(http.StatusUnprocessableEntity, "validation error", "request not valid, please check the fields")
+	ErrForbidden       BusinessError = New(http.StatusForbidden, "access denied", "you are not allowed to access this resource")
 
 	ErrOrderInvalidStateTransition BusinessError = New(http.StatusBadRequest, "unable to update order state", "invalid state transition")
 	ErrOrderNotFound               BusinessError = New(http.StatusNotFound, "unable to find the order", "order not found")
 	ErrOrderAlreadyExists          BusinessError = New(http.StatusConflict, "unable to create the order", "order already exists")
 	ErrOrderItemAlreadyExists      BusinessError = New(http.StatusConflict, "unable to add an item", "order item already exists")
+	ErrOrderItemCurrencyMismatch   BusinessError = New(http.StatusBadRequest, "unable to add an item", "order item currency differs from the order currency")
+	ErrOrderItemNotFound           BusinessError = New(http.StatusNotFound, "unable to find the order item", "order item not found")
+	ErrOrderItemInvalidQuantity    BusinessError = New(http.StatusBadRequest, "unable to update the order item", "order item quantity must be greater than zero")
 	ErrOrderInProgress             BusinessError = New(http.StatusBadRequest, "unable to update/insert information to the order", "order is in progress")
+	ErrOrderVersionConflict        BusinessError = New(http.StatusConflict, "unable to update the order", "order was changed by another request, please reload it and try again")
+	ErrOrderPreconditionFailed     BusinessError = New(http.StatusPreconditionFailed, "unable to update the order", "order version does not match the If-Match header")
 	ErrOrderAlreadyCompleted       BusinessError = New(http.StatusBadRequest, "unable to update/insert information to the order", "order is already completed or cancelled")
 
 	ErrOrderHasNoItems           BusinessError = New(http.StatusBadRequest, "operation not allowed", "order has no items")
 	ErrOrderHasOnGoingPayments   BusinessError = New(http.StatusBadRequest, "operation not allowed", "order has on going payments or is already paid")
 	ErrOrderHasNoOnGoingPayments BusinessError = New(http.StatusBadRequest, "operation not allowed", "order has no on going payments")
 
-	ErrTopicNotFound BusinessError = New(http.StatusNotFound, "unable to find the topic", "topic not found")
+	ErrTopicNotFound  BusinessError = New(http.StatusNotFound, "unable to find the topic", "topic not found")
+	ErrSecretNotFound BusinessError = New(http.StatusNotFound, "unable to find the secret", "secret not found")
 
-	ErrQueueMessageNotValid BusinessError = New(http.StatusUnprocessableEntity, "unable to process the message", "message not valid")
+	ErrQueueMessageNotValid          BusinessError = New(http.StatusUnprocessableEntity, "unable to process the message", "message not valid")
+	ErrQueueMessageAlreadyProcessed  BusinessError = New(http.StatusConflict, "unable to process the message", "message already processed")
+	ErrQueueMessageSignatureNotValid BusinessError = New(http.StatusUnauthorized, "unable to process the message", "message signature not valid")
+	ErrQueueMessageTopicNotAllowed   BusinessError = New(http.StatusForbidden, "unable to process the message", "message topic not allowed")
+	ErrQueueMessageNotSigned         BusinessError = New(http.StatusUnauthorized, "unable to process the message", "message not signed")
+
+	ErrIdempotencyKeyNotFound   BusinessError = New(http.StatusNotFound, "unable to find the idempotency key", "idempotency key not found")
+	ErrIdempotencyKeyNotValid   BusinessError = New(http.StatusBadRequest, "invalid idempotency key", "idempotency key must have up to 255 characters")
+	ErrIdempotencyKeyReused     BusinessError = New(http.StatusUnprocessableEntity, "invalid idempotency key", "idempotency key was already used with another request")
+	ErrIdempotencyKeyInProgress BusinessError = New(http.StatusConflict, "request in progress", "a request with the same idempotency key is still being processed")
 
 	ErrPaymentNotFound               BusinessError = New(http.StatusNotFound, "unable to find the payment", "payment not found")
 	ErrPaymentInvalidStateTransition BusinessError = New(http.StatusBadRequest, "unable to update payment state", "invalid state transition")
+
+	ErrWebhookNotFound BusinessError = New(http.StatusNotFound, "unable to find the webhook", "webhook not found")
 )
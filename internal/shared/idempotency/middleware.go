@@ -0,0 +1,162 @@
+package idempotency
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/idempotency_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	headerETag = "ETag"
+
+	maxKeyLength = 255
+)
+
+// Middleware makes the requests carrying an Idempotency-Key safe to retry: the
+// first response of a key is stored and replayed to the next requests of the
+// same user, unless they have another payload. A request still in progress
+// after lockTimeout is considered lost and may be retried
+func Middleware(
+	repository repository.IdempotencyRepository,
+	timeProvider provider.TimeProvider,
+	ttl time.Duration,
+	lockTimeout time.Duration,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			value := c.Request().Header.Get(HeaderIdempotencyKey)
+			if value == "" {
+				return next(c)
+			}
+
+			if len(value) > maxKeyLength {
+				return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrIdempotencyKeyNotValid)
+			}
+
+			ctx := c.Request().Context()
+
+			principal, ok := auth.FromContext(ctx)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Token is required")
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return custom_error.NewHttpAppError(http.StatusBadRequest, "invalid request", err)
+			}
+
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			key := idempotency_entity.NewKey(
+				principal.UserId,
+				value,
+				idempotency_entity.Fingerprint(c.Request().Method, c.Request().URL.Path, body),
+				timeProvider.GetTime(),
+				ttl,
+				lockTimeout,
+			)
+
+			reserved, err := repository.Reserve(ctx, &key)
+			if err != nil {
+				return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+			}
+
+			if !reserved {
+				return replay(c, repository, key)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			handlerErr := next(c)
+			if handlerErr != nil {
+				// the error is written here so its response is stored as well
+				c.Error(handlerErr)
+			}
+
+			// server errors are not stored, the client is expected to retry them
+			if c.Response().Status >= http.StatusInternalServerError {
+				if err := repository.Release(ctx, key.UserId, key.Key); err != nil {
+					slog.ErrorContext(ctx, "error releasing idempotency key", "key", key.Key, "error", err)
+				}
+
+				return handlerErr
+			}
+
+			key.Complete(
+				c.Response().Status,
+				c.Response().Header().Get(echo.HeaderContentType),
+				c.Response().Header().Get(headerETag),
+				recorder.body.Bytes())
+
+			if err := repository.Complete(ctx, &key); err != nil {
+				slog.ErrorContext(ctx, "error storing idempotency key response", "key", key.Key, "error", err)
+			}
+
+			return handlerErr
+		}
+	}
+}
+
+func replay(c echo.Context, repository repository.IdempotencyRepository, key idempotency_entity.Key) error {
+	ctx := c.Request().Context()
+
+	stored, err := repository.Get(ctx, key.UserId, key.Key)
+	if err != nil {
+		// the key was released meanwhile by a request that failed
+		if errors.Is(err, custom_error.ErrIdempotencyKeyNotFound) {
+			return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrIdempotencyKeyInProgress)
+		}
+
+		return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", err)
+	}
+
+	if !stored.Matches(key.Fingerprint) {
+		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrIdempotencyKeyReused)
+	}
+
+	if stored.State != idempotency_entity.Completed {
+		return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrIdempotencyKeyInProgress)
+	}
+
+	slog.InfoContext(ctx, "replaying idempotent request", "key", stored.Key, "status", stored.StatusCode)
+
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+
+	if stored.ContentType != "" {
+		c.Response().Header().Set(echo.HeaderContentType, stored.ContentType)
+	}
+
+	if stored.ETag != "" {
+		c.Response().Header().Set(headerETag, stored.ETag)
+	}
+
+	c.Response().WriteHeader(stored.StatusCode)
+
+	_, err = c.Response().Write(stored.Response)
+
+	return err
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
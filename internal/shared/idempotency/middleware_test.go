@@ -0,0 +1,305 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/idempotency_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/time_provider"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/repository/mocks"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/auth"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/shared/custom_error"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	userId = "user_id"
+	body   = `{"customer_id":"123"}`
+)
+
+func newServer(repository *mocks.MockIdempotencyRepository, now time.Time, handler echo.HandlerFunc) *echo.Echo {
+	timeProvider := time_provider.NewTimeProvider(func() time.Time { return now })
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := auth.WithPrincipal(c.Request().Context(), auth.NewPrincipal(userId, nil))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.POST("/orders", handler, Middleware(repository, timeProvider, time.Hour, time.Minute))
+
+	return e
+}
+
+func newRequest(key string, payload string) *http.Request {
+	req := httptest.NewRequest(echo.POST, "/orders", strings.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+
+	return req
+}
+
+func TestMiddleware(t *testing.T) {
+	now := time.Now()
+
+	fingerprint := idempotency_entity.Fingerprint(http.MethodPost, "/orders", []byte(body))
+
+	t.Run("Should skip the requests without key", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("", body))
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, res.Code)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return bad request when the key is too long", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest(strings.Repeat("a", 256), body))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should store the response of the first request", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		key := idempotency_entity.NewKey(userId, "key", fingerprint, now, time.Hour, time.Minute)
+
+		repository.On("Reserve", mock.Anything, &key).
+			Return(true, nil).
+			Once()
+
+		completed := key
+		completed.Complete(http.StatusCreated, echo.MIMEApplicationJSON, `"1"`, []byte("{\"id\":\"1\"}\n"))
+
+		repository.On("Complete", mock.Anything, &completed).
+			Return(nil).
+			Once()
+
+		var receivedBody string
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			var payload map[string]string
+			if err := c.Bind(&payload); err != nil {
+				return err
+			}
+
+			receivedBody = payload["customer_id"]
+
+			c.Response().Header().Set(headerETag, `"1"`)
+
+			return c.JSON(http.StatusCreated, map[string]string{"id": "1"})
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", body))
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Equal(t, "123", receivedBody)
+		assert.Empty(t, res.Header().Get(HeaderIdempotentReplayed))
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should store the business errors", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		repository.On("Reserve", mock.Anything, mock.Anything).
+			Return(true, nil).
+			Once()
+
+		repository.On("Complete", mock.Anything, mock.MatchedBy(func(key *idempotency_entity.Key) bool {
+			return key.State == idempotency_entity.Completed && key.StatusCode == http.StatusConflict
+		})).
+			Return(nil).
+			Once()
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return custom_error.NewHttpAppErrorFromBusinessError(custom_error.ErrOrderItemAlreadyExists)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", body))
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, res.Code)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should release the key when the request fails", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		repository.On("Reserve", mock.Anything, mock.Anything).
+			Return(true, nil).
+			Once()
+
+		repository.On("Release", mock.Anything, userId, "key").
+			Return(nil).
+			Once()
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return custom_error.NewHttpAppError(http.StatusInternalServerError, "internal server error", assert.AnError)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", body))
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should replay the stored response", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		stored := idempotency_entity.NewKey(userId, "key", fingerprint, now, time.Hour, time.Minute)
+		stored.Complete(http.StatusCreated, echo.MIMEApplicationJSON, `"1"`, []byte(`{"id":"1"}`))
+
+		repository.On("Reserve", mock.Anything, mock.Anything).
+			Return(false, nil).
+			Once()
+
+		repository.On("Get", mock.Anything, userId, "key").
+			Return(stored, nil).
+			Once()
+
+		called := false
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			called = true
+			return c.NoContent(http.StatusCreated)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", body))
+
+		// Assert
+		assert.False(t, called)
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Equal(t, `{"id":"1"}`, res.Body.String())
+		assert.Equal(t, echo.MIMEApplicationJSON, res.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `"1"`, res.Header().Get(headerETag))
+		assert.Equal(t, "true", res.Header().Get(HeaderIdempotentReplayed))
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return unprocessable entity when the key was used with another payload", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		stored := idempotency_entity.NewKey(userId, "key", fingerprint, now, time.Hour, time.Minute)
+		stored.Complete(http.StatusCreated, echo.MIMEApplicationJSON, "", []byte(`{"id":"1"}`))
+
+		repository.On("Reserve", mock.Anything, mock.Anything).
+			Return(false, nil).
+			Once()
+
+		repository.On("Get", mock.Anything, userId, "key").
+			Return(stored, nil).
+			Once()
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", `{"customer_id":"456"}`))
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return conflict when the first request is still in progress", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		stored := idempotency_entity.NewKey(userId, "key", fingerprint, now, time.Hour, time.Minute)
+
+		repository.On("Reserve", mock.Anything, mock.Anything).
+			Return(false, nil).
+			Once()
+
+		repository.On("Get", mock.Anything, userId, "key").
+			Return(stored, nil).
+			Once()
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", body))
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, res.Code)
+		repository.AssertExpectations(t)
+	})
+
+	t.Run("Should return internal server error when the key can not be reserved", func(t *testing.T) {
+		// Arrange
+		repository := mocks.NewMockIdempotencyRepository(t)
+
+		repository.On("Reserve", mock.Anything, mock.Anything).
+			Return(false, assert.AnError).
+			Once()
+
+		e := newServer(repository, now, func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		res := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(res, newRequest("key", body))
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+		repository.AssertExpectations(t)
+	})
+}
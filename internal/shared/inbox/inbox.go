@@ -0,0 +1,29 @@
+package inbox
+
+import "context"
+
+type ctxKey struct{}
+
+type message struct {
+	id      string
+	claimed bool
+}
+
+// WithMessage marks the context as the processing of an inbound message, so
+// the transactional write done with it records the message as processed along
+// with the side effects of the message
+func WithMessage(ctx context.Context, messageId string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &message{id: messageId})
+}
+
+// Claim returns the message id only on the first call for the context
+func Claim(ctx context.Context) (string, bool) {
+	msg, ok := ctx.Value(ctxKey{}).(*message)
+	if !ok || msg.claimed {
+		return "", false
+	}
+
+	msg.claimed = true
+
+	return msg.id, true
+}
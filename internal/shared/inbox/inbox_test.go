@@ -0,0 +1,35 @@
+package inbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaim(t *testing.T) {
+	t.Run("Should claim the message only once", func(t *testing.T) {
+		// Arrange
+		ctx := WithMessage(context.Background(), "message_id")
+
+		// Act
+		first, firstOk := Claim(ctx)
+		_, secondOk := Claim(ctx)
+
+		// Assert
+		assert.True(t, firstOk)
+		assert.Equal(t, "message_id", first)
+		assert.False(t, secondOk)
+	})
+
+	t.Run("Should not claim when there is no message", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+
+		// Act
+		_, ok := Claim(ctx)
+
+		// Assert
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,169 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Group runs goroutines until it is stopped, Stop cancels their context and
+// waits for them to return
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	waitGroup sync.WaitGroup
+}
+
+func NewGroup(parent context.Context) *Group {
+	ctx, cancel := context.WithCancel(parent)
+
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs fn with a context cancelled when the group is stopped
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.waitGroup.Add(1)
+
+	go func() {
+		defer g.waitGroup.Done()
+
+		fn(g.ctx)
+
+		slog.Debug("goroutine stopped", "name", name)
+	}()
+}
+
+// Loop runs fn until the group is stopped, waiting for the interval between
+// runs unless fn reports there is more work to do. A run is never cut by the
+// stop, fn gets a context that is not cancelled with the group
+func (g *Group) Loop(name string, interval time.Duration, fn func(ctx context.Context) bool) {
+	g.Go(name, func(ctx context.Context) {
+		for ctx.Err() == nil {
+			if fn(context.WithoutCancel(ctx)) {
+				continue
+			}
+
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+			}
+		}
+	})
+}
+
+// Stop cancels the goroutines and waits for them until ctx is done
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	return Wait(ctx, g.waitGroup.Wait)
+}
+
+// Wait calls the blocking wait, giving up once ctx is done
+func Wait(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type phase struct {
+	name    string
+	timeout time.Duration
+	stop    func(ctx context.Context) error
+}
+
+// Manager stops the parts of the process in the order they were added, every
+// phase runs even when a previous one failed or the deadline passed, so the
+// last ones, such as closing the database, always happen. The phases share the
+// timeout, except the ones added with their own budget
+type Manager struct {
+	timeout time.Duration
+	phases  []phase
+}
+
+func NewManager(timeout time.Duration) *Manager {
+	return &Manager{
+		timeout: timeout,
+	}
+}
+
+func (m *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	m.phases = append(m.phases, phase{
+		name: name,
+		stop: stop,
+	})
+}
+
+// OnShutdownWithin adds a phase with its own timeout, not taken from the shared
+// one, so the phases before it can not leave it without time
+func (m *Manager) OnShutdownWithin(name string, timeout time.Duration, stop func(ctx context.Context) error) {
+	m.phases = append(m.phases, phase{
+		name:    name,
+		timeout: timeout,
+		stop:    stop,
+	})
+}
+
+// Shutdown runs the phases within the timeout and returns their errors, the
+// whole shutdown takes at most the shared timeout plus the budget of the
+// phases having their own
+func (m *Manager) Shutdown(ctx context.Context) error {
+	started := time.Now()
+	remaining := m.timeout
+
+	slog.InfoContext(ctx, "shutdown started", "timeout", m.timeout)
+
+	var errs []error
+
+	for _, phase := range m.phases {
+		phaseStarted := time.Now()
+
+		timeout := remaining
+		if phase.timeout > 0 {
+			timeout = phase.timeout
+		}
+
+		slog.InfoContext(ctx, "shutdown phase started", "phase", phase.name, "timeout", timeout)
+
+		err := m.stop(ctx, phase, timeout)
+
+		if phase.timeout == 0 {
+			remaining = max(remaining-time.Since(phaseStarted), 0)
+		}
+
+		if err != nil {
+			slog.ErrorContext(ctx, "shutdown phase failed", "phase", phase.name, "elapsed", time.Since(phaseStarted), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", phase.name, err))
+			continue
+		}
+
+		slog.InfoContext(ctx, "shutdown phase completed", "phase", phase.name, "elapsed", time.Since(phaseStarted))
+	}
+
+	slog.InfoContext(ctx, "shutdown completed", "elapsed", time.Since(started))
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stop(ctx context.Context, phase phase, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return phase.stop(ctx)
+}
@@ -0,0 +1,218 @@
+package lifecycle
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("Should cancel the goroutines and wait for them", func(t *testing.T) {
+		// Arrange
+		group := NewGroup(context.Background())
+
+		var stopped atomic.Bool
+
+		group.Go("test", func(ctx context.Context) {
+			<-ctx.Done()
+			stopped.Store(true)
+		})
+
+		// Act
+		err := group.Stop(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, stopped.Load())
+	})
+
+	t.Run("Should return error when the goroutines do not stop in time", func(t *testing.T) {
+		// Arrange
+		group := NewGroup(context.Background())
+
+		release := make(chan struct{})
+		defer close(release)
+
+		group.Go("test", func(ctx context.Context) {
+			<-release
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Act
+		err := group.Stop(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Should run the loop again right away while there is more work", func(t *testing.T) {
+		// Arrange
+		group := NewGroup(context.Background())
+
+		var runs atomic.Int32
+		done := make(chan struct{})
+
+		group.Loop("test", time.Hour, func(ctx context.Context) bool {
+			if runs.Add(1) == 3 {
+				close(done)
+				return false
+			}
+
+			return true
+		})
+
+		// Act
+		<-done
+		err := group.Stop(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), runs.Load())
+	})
+
+	t.Run("Should finish the current run of the loop when stopped", func(t *testing.T) {
+		// Arrange
+		group := NewGroup(context.Background())
+
+		started := make(chan struct{})
+		var uncancelled atomic.Value
+
+		group.Loop("test", time.Hour, func(ctx context.Context) bool {
+			close(started)
+			time.Sleep(10 * time.Millisecond)
+			uncancelled.Store(ctx.Err() == nil)
+
+			return false
+		})
+
+		<-started
+
+		// Act
+		err := group.Stop(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, true, uncancelled.Load())
+	})
+}
+
+func TestManager(t *testing.T) {
+	t.Run("Should run the phases in the order they were added", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(time.Second)
+
+		var order []string
+
+		for _, name := range []string{"http servers", "queue consumer", "database"} {
+			manager.OnShutdown(name, func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+
+		// Act
+		err := manager.Shutdown(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"http servers", "queue consumer", "database"}, order)
+	})
+
+	t.Run("Should run the next phases when one fails", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(time.Second)
+
+		closed := false
+
+		manager.OnShutdown("queue consumer", func(ctx context.Context) error {
+			return assert.AnError
+		})
+		manager.OnShutdown("database", func(ctx context.Context) error {
+			closed = true
+			return nil
+		})
+
+		// Act
+		err := manager.Shutdown(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "queue consumer")
+		assert.True(t, closed)
+	})
+
+	t.Run("Should give the phases the shutdown deadline", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(10 * time.Millisecond)
+
+		closed := false
+
+		manager.OnShutdown("queue consumer", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		manager.OnShutdown("database", func(ctx context.Context) error {
+			closed = true
+			return nil
+		})
+
+		// Act
+		err := manager.Shutdown(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, closed)
+	})
+
+	t.Run("Should give a phase its own budget after the shared deadline passed", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(10 * time.Millisecond)
+
+		var drained error
+
+		manager.OnShutdown("http servers", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		manager.OnShutdownWithin("queue consumer", time.Second, func(ctx context.Context) error {
+			drained = ctx.Err()
+			return nil
+		})
+
+		// Act
+		err := manager.Shutdown(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "http servers")
+		assert.NoError(t, drained)
+	})
+
+	t.Run("Should not take the budget of a phase from the shared timeout", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(50 * time.Millisecond)
+
+		var closed error
+
+		manager.OnShutdownWithin("queue consumer", time.Second, func(ctx context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+		manager.OnShutdown("database", func(ctx context.Context) error {
+			closed = ctx.Err()
+			return nil
+		})
+
+		// Act
+		err := manager.Shutdown(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, closed)
+	})
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request, continuing the trace of
+// the caller when it sends one. The span is named after the route instead of
+// the path, so the requests of an endpoint are grouped together
+func Middleware(skipPaths ...string) echo.MiddlewareFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := c.Path()
+			if skip[route] {
+				return next(c)
+			}
+
+			request := c.Request()
+
+			ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+			name := request.Method
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx, span := Tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(request.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(request.URL.Path),
+					semconv.UserAgentOriginal(request.UserAgent()),
+				))
+			defer span.End()
+
+			c.SetRequest(request.WithContext(ctx))
+
+			err := next(c)
+
+			// the error handler writes the response after the middlewares
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+
+				var httpError *echo.HTTPError
+				if errors.As(err, &httpError) {
+					status = httpError.Code
+				}
+			}
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+			if status >= http.StatusInternalServerError {
+				if err == nil {
+					err = errors.New(http.StatusText(status))
+				}
+
+				RecordError(span, err)
+			}
+
+			return err
+		}
+	}
+}
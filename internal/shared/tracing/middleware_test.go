@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestServer(t *testing.T) (*echo.Echo, *tracetest.SpanRecorder) {
+	resetTracing(t)
+
+	recorder := tracetest.NewSpanRecorder()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	e := echo.New()
+	e.Use(Middleware("/health"))
+
+	e.GET("/health", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/orders/:order_id", func(c echo.Context) error {
+		if !trace.SpanContextFromContext(c.Request().Context()).IsValid() {
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/orders", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "unavailable")
+	})
+
+	return e, recorder
+}
+
+func getAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attribute := range span.Attributes() {
+		if attribute.Key == key {
+			return attribute.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("Should start a span named after the route", func(t *testing.T) {
+		// Arrange
+		e, recorder := newTestServer(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/orders/123", nil)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "GET /orders/:order_id", spans[0].Name())
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+		assert.Equal(t, "/orders/123", getAttribute(spans[0], "url.path").AsString())
+		assert.Equal(t, int64(http.StatusOK), getAttribute(spans[0], "http.response.status_code").AsInt64())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+
+	t.Run("Should continue the trace of the caller", func(t *testing.T) {
+		// Arrange
+		e, recorder := newTestServer(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/orders/123", nil)
+		req.Header.Set("traceparent", traceparent)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spans[0].SpanContext().TraceID().String())
+		assert.Equal(t, "b7ad6b7169203331", spans[0].Parent().SpanID().String())
+	})
+
+	t.Run("Should mark the span as failed when the handler returns a server error", func(t *testing.T) {
+		// Arrange
+		e, recorder := newTestServer(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, int64(http.StatusServiceUnavailable), getAttribute(spans[0], "http.response.status_code").AsInt64())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+
+	t.Run("Should not start a span for the skipped paths", func(t *testing.T) {
+		// Arrange
+		e, recorder := newTestServer(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		rec := httptest.NewRecorder()
+
+		// Act
+		e.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, recorder.Ended())
+	})
+}
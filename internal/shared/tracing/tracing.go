@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+)
+
+const instrumentationName = "github.com/jfelipearaujo-org/ms-order-management"
+
+// Setup installs the W3C propagator and a tracer provider exporting the spans
+// as configured, the returned function flushes the spans left and stops the
+// exporter. Without an exporter the trace context of the callers and the
+// messages is still propagated, the spans are just not recorded
+func Setup(ctx context.Context, config *environment.Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !config.TracingConfig.IsEnabled() {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, config.TracingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(config.TracingConfig.ServiceName),
+			semconv.ServiceVersion(config.ApiConfig.ApiVersion),
+			semconv.DeploymentEnvironment(config.ApiConfig.EnvName),
+		))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TracingConfig.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, config *environment.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case environment.TracingExporterOtlp:
+		// without an endpoint the exporter reads the OTEL_EXPORTER_OTLP_* variables
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+
+		return otlptracehttp.New(ctx, options...)
+	case environment.TracingExporterStdout:
+		return stdouttrace.New()
+	case environment.TracingExporterFile:
+		file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		return &fileExporter{
+			Exporter: exporter,
+			file:     file,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown tracing exporter: %s", config.Exporter)
+}
+
+// fileExporter writes one JSON span per line to the file, closed along with
+// the exporter
+type fileExporter struct {
+	*stdouttrace.Exporter
+
+	file *os.File
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	if err := e.Exporter.Shutdown(ctx); err != nil {
+		e.file.Close()
+		return err
+	}
+
+	return e.file.Close()
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// RecordError marks the span as failed with the error
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject returns the trace context of ctx as a map, to be carried along with a
+// message or stored until the message is published
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	return carrier
+}
+
+// Extract returns ctx with the trace context found in the carrier, the spans
+// started from it continue the trace of whoever injected it
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
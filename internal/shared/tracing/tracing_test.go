@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/environment"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const traceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+func newConfig(tracingConfig *environment.TracingConfig) *environment.Config {
+	return &environment.Config{
+		ApiConfig: &environment.ApiConfig{
+			EnvName:    "development",
+			ApiVersion: "v1",
+		},
+		TracingConfig: tracingConfig,
+	}
+}
+
+func resetTracing(t *testing.T) {
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("Should propagate the trace context when there is no exporter", func(t *testing.T) {
+		// Arrange
+		resetTracing(t)
+
+		config := newConfig(&environment.TracingConfig{
+			Exporter: environment.TracingExporterNone,
+		})
+
+		// Act
+		shutdown, err := Setup(context.Background(), config)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+
+		ctx := Extract(context.Background(), map[string]string{"traceparent": traceparent})
+		assert.Equal(t, traceparent, Inject(ctx)["traceparent"])
+	})
+
+	t.Run("Should write the spans to the file", func(t *testing.T) {
+		// Arrange
+		resetTracing(t)
+
+		file := filepath.Join(t.TempDir(), "traces.json")
+
+		config := newConfig(&environment.TracingConfig{
+			Exporter:    environment.TracingExporterFile,
+			File:        file,
+			ServiceName: "ms-order-management",
+			SampleRatio: 1,
+		})
+
+		shutdown, err := Setup(context.Background(), config)
+		assert.NoError(t, err)
+
+		// Act
+		_, span := Tracer().Start(context.Background(), "test-span")
+		span.End()
+
+		err = shutdown(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), `"Name":"test-span"`)
+		assert.Contains(t, string(content), `"Value":"ms-order-management"`)
+	})
+
+	t.Run("Should return error when the exporter is unknown", func(t *testing.T) {
+		// Arrange
+		resetTracing(t)
+
+		config := newConfig(&environment.TracingConfig{
+			Exporter: "unknown",
+		})
+
+		// Act
+		shutdown, err := Setup(context.Background(), config)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, shutdown)
+	})
+
+	t.Run("Should return error when the file can not be opened", func(t *testing.T) {
+		// Arrange
+		resetTracing(t)
+
+		config := newConfig(&environment.TracingConfig{
+			Exporter: environment.TracingExporterFile,
+			File:     filepath.Join(t.TempDir(), "missing", "traces.json"),
+		})
+
+		// Act
+		shutdown, err := Setup(context.Background(), config)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, shutdown)
+	})
+}
+
+func TestInject(t *testing.T) {
+	t.Run("Should return nothing when there is no trace", func(t *testing.T) {
+		// Arrange
+		resetTracing(t)
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+
+		// Act
+		carrier := Inject(context.Background())
+
+		// Assert
+		assert.Empty(t, carrier)
+	})
+
+	t.Run("Should continue the trace extracted", func(t *testing.T) {
+		// Arrange
+		resetTracing(t)
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+
+		// Act
+		ctx := Extract(context.Background(), map[string]string{"traceparent": traceparent})
+
+		// Assert
+		spanContext := trace.SpanContextFromContext(ctx)
+		assert.True(t, spanContext.IsRemote())
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spanContext.TraceID().String())
+		assert.Equal(t, map[string]string{"traceparent": traceparent}, Inject(ctx))
+	})
+}
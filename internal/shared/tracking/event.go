@@ -0,0 +1,52 @@
+package tracking
+
+import (
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/order_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/payment_entity"
+)
+
+type EventType string
+
+const (
+	EventStateChanged   EventType = "state_changed"
+	EventPaymentChanged EventType = "payment_changed"
+)
+
+// Event is what a customer watching an order by its track id is told about
+type Event struct {
+	Type       EventType               `json:"type"`
+	OrderId    string                  `json:"order_id"`
+	TrackId    string                  `json:"track_id"`
+	State      order_entity.OrderState `json:"state"`
+	StateTitle string                  `json:"state_title"`
+
+	PaymentId         string                      `json:"payment_id,omitempty"`
+	PaymentState      payment_entity.PaymentState `json:"payment_state,omitempty"`
+	PaymentStateTitle string                      `json:"payment_state_title,omitempty"`
+
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func NewStateChangedEvent(order order_entity.Order) Event {
+	return Event{
+		Type:       EventStateChanged,
+		OrderId:    order.Id,
+		TrackId:    string(order.TrackId),
+		State:      order.State,
+		StateTitle: order.State.String(),
+		ChangedAt:  order.UpdatedAt,
+	}
+}
+
+func NewPaymentChangedEvent(order order_entity.Order, payment payment_entity.Payment) Event {
+	event := NewStateChangedEvent(order)
+	event.Type = EventPaymentChanged
+	event.PaymentId = payment.PaymentId
+	event.PaymentState = payment.State
+	event.PaymentStateTitle = payment.State.String()
+	event.ChangedAt = payment.UpdatedAt
+
+	return event
+}
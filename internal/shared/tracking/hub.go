@@ -0,0 +1,109 @@
+package tracking
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// subscriberBuffer is how many events a slow subscriber can fall behind
+// before the next ones are dropped for it
+const subscriberBuffer = 16
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type Subscriber interface {
+	Subscribe(trackId string) (<-chan Event, func())
+}
+
+// Hub delivers the events to the subscribers of this process, keyed by the
+// track id of the order
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+	closed      bool
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns the channel receiving the events of the track id and the
+// function that must be called once the caller stops reading from it. The
+// channel is closed when the hub is
+func (h *Hub) Subscribe(trackId string) (<-chan Event, func()) {
+	events := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(events)
+		return events, func() {}
+	}
+
+	if _, ok := h.subscribers[trackId]; !ok {
+		h.subscribers[trackId] = make(map[chan Event]struct{})
+	}
+	h.subscribers[trackId][events] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		// already closed along with the hub
+		if _, ok := h.subscribers[trackId][events]; !ok {
+			return
+		}
+
+		delete(h.subscribers[trackId], events)
+		if len(h.subscribers[trackId]) == 0 {
+			delete(h.subscribers, trackId)
+		}
+
+		close(events)
+	}
+
+	return events, unsubscribe
+}
+
+// Close closes the channels of the subscribers, ending the streams reading
+// from them, as the http server does not cancel the requests on shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for trackId, subscribers := range h.subscribers {
+		for events := range subscribers {
+			close(events)
+		}
+
+		delete(h.subscribers, trackId)
+	}
+}
+
+// Publish delivers the event to the local subscribers only, which is enough
+// when a single replica is running
+func (h *Hub) Publish(ctx context.Context, event Event) error {
+	h.Broadcast(event)
+	return nil
+}
+
+func (h *Hub) Broadcast(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for events := range h.subscribers[event.TrackId] {
+		select {
+		case events <- event:
+		default:
+			slog.Warn("dropping tracking event for a slow subscriber", "track_id", event.TrackId, "type", event.Type)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package tracking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("Should deliver the events of the subscribed track id", func(t *testing.T) {
+		// Arrange
+		hub := NewHub()
+
+		events, unsubscribe := hub.Subscribe("ABC-123")
+		defer unsubscribe()
+
+		others, unsubscribeOthers := hub.Subscribe("DEF-456")
+		defer unsubscribeOthers()
+
+		// Act
+		err := hub.Publish(context.Background(), Event{Type: EventStateChanged, TrackId: "ABC-123"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, Event{Type: EventStateChanged, TrackId: "ABC-123"}, <-events)
+		assert.Empty(t, others)
+	})
+
+	t.Run("Should drop the events of a subscriber that is not reading", func(t *testing.T) {
+		// Arrange
+		hub := NewHub()
+
+		events, unsubscribe := hub.Subscribe("ABC-123")
+		defer unsubscribe()
+
+		// Act
+		for i := 0; i < subscriberBuffer+5; i++ {
+			hub.Broadcast(Event{TrackId: "ABC-123"})
+		}
+
+		// Assert
+		assert.Len(t, events, subscriberBuffer)
+	})
+
+	t.Run("Should close the channel and forget the subscriber on unsubscribe", func(t *testing.T) {
+		// Arrange
+		hub := NewHub()
+
+		events, unsubscribe := hub.Subscribe("ABC-123")
+
+		// Act
+		unsubscribe()
+		unsubscribe()
+
+		// Assert
+		_, ok := <-events
+		assert.False(t, ok)
+		assert.Empty(t, hub.subscribers)
+	})
+
+	t.Run("Should close the channels of the subscribers when closed", func(t *testing.T) {
+		// Arrange
+		hub := NewHub()
+
+		events, unsubscribe := hub.Subscribe("ABC-123")
+
+		// Act
+		hub.Close()
+		unsubscribe()
+
+		// Assert
+		_, ok := <-events
+		assert.False(t, ok)
+		assert.Empty(t, hub.subscribers)
+
+		late, _ := hub.Subscribe("ABC-123")
+		_, ok = <-late
+		assert.False(t, ok)
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	t.Run("Should broadcast the notified event", func(t *testing.T) {
+		// Arrange
+		hub := NewHub()
+
+		events, unsubscribe := hub.Subscribe("ABC-123")
+		defer unsubscribe()
+
+		listener := NewPostgresListener(nil, "order_tracking", hub)
+
+		// Act
+		listener.dispatch(context.Background(), `{"type":"state_changed","track_id":"ABC-123","state":2}`)
+
+		// Assert
+		event := <-events
+		assert.Equal(t, EventStateChanged, event.Type)
+		assert.Equal(t, 2, int(event.State))
+	})
+
+	t.Run("Should ignore a payload that is not an event", func(t *testing.T) {
+		// Arrange
+		hub := NewHub()
+
+		events, unsubscribe := hub.Subscribe("ABC-123")
+		defer unsubscribe()
+
+		listener := NewPostgresListener(nil, "order_tracking", hub)
+
+		// Act
+		listener.dispatch(context.Background(), "not json")
+
+		// Assert
+		assert.Empty(t, events)
+	})
+}
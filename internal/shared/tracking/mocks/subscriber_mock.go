@@ -0,0 +1,59 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	tracking "github.com/jfelipearaujo-org/ms-order-management/internal/shared/tracking"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockSubscriber is an autogenerated mock type for the Subscriber type
+type MockSubscriber struct {
+	mock.Mock
+}
+
+// Subscribe provides a mock function with given fields: trackId
+func (_m *MockSubscriber) Subscribe(trackId string) (<-chan tracking.Event, func()) {
+	ret := _m.Called(trackId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan tracking.Event
+	var r1 func()
+	if rf, ok := ret.Get(0).(func(string) (<-chan tracking.Event, func())); ok {
+		return rf(trackId)
+	}
+	if rf, ok := ret.Get(0).(func(string) <-chan tracking.Event); ok {
+		r0 = rf(trackId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan tracking.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) func()); ok {
+		r1 = rf(trackId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	return r0, r1
+}
+
+// NewMockSubscriber creates a new instance of MockSubscriber. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSubscriber(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSubscriber {
+	mock := &MockSubscriber{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,142 @@
+package tracking
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const listenerPingInterval = 90 * time.Second
+
+// PostgresPublisher sends the events through NOTIFY, so the listener of every
+// replica, this one included, hands them to its local subscribers
+type PostgresPublisher struct {
+	conn    *sql.DB
+	channel string
+}
+
+func NewPostgresPublisher(conn *sql.DB, channel string) *PostgresPublisher {
+	return &PostgresPublisher{
+		conn:    conn,
+		channel: channel,
+	}
+}
+
+func (p *PostgresPublisher) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		SELECT pg_notify($1, $2);
+	`
+
+	if _, err := p.conn.ExecContext(ctx, query, p.channel, string(payload)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type PostgresListener struct {
+	dsn     func() string
+	channel string
+	hub     *Hub
+}
+
+// NewPostgresListener returns a listener connecting with the url returned by
+// dsn, read again whenever it has to reconnect so a rotated credential is used
+func NewPostgresListener(dsn func() string, channel string, hub *Hub) *PostgresListener {
+	return &PostgresListener{
+		dsn:     dsn,
+		channel: channel,
+		hub:     hub,
+	}
+}
+
+// Run listens to the channel until the context is done, reconnecting whenever
+// the connection is lost
+func (l *PostgresListener) Run(ctx context.Context) error {
+	for {
+		reconnect, err := l.listen(ctx)
+		if err != nil || !reconnect {
+			return err
+		}
+
+		slog.InfoContext(ctx, "tracking listener reconnecting with the updated url", "channel", l.channel)
+	}
+}
+
+// listen runs a listener with the current url, reporting it must be replaced
+// when it fails to reconnect after the url changed
+func (l *PostgresListener) listen(ctx context.Context) (bool, error) {
+	dsn := l.dsn()
+
+	failed := make(chan struct{}, 1)
+
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.ErrorContext(ctx, "tracking listener connection error", "channel", l.channel, "error", err)
+		}
+
+		if event == pq.ListenerEventConnectionAttemptFailed {
+			select {
+			case failed <- struct{}{}:
+			default:
+			}
+		}
+	})
+	defer listener.Close()
+
+	// the listen waits for the first connection, which may never come with an
+	// outdated url
+	listened := make(chan error, 1)
+	go func() {
+		listened <- listener.Listen(l.channel)
+	}()
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case err := <-listened:
+			if err != nil {
+				return false, err
+			}
+		case notification := <-listener.Notify:
+			// a nil notification tells the connection was re-established
+			if notification == nil {
+				continue
+			}
+
+			l.dispatch(ctx, notification.Extra)
+		case <-failed:
+			if l.dsn() != dsn {
+				return true, nil
+			}
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				slog.ErrorContext(ctx, "tracking listener ping error", "channel", l.channel, "error", err)
+			}
+		}
+	}
+}
+
+func (l *PostgresListener) dispatch(ctx context.Context, payload string) {
+	var event Event
+
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		slog.ErrorContext(ctx, "error decoding tracking event", "channel", l.channel, "error", err)
+		return
+	}
+
+	l.hub.Broadcast(event)
+}
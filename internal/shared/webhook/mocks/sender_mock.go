@@ -0,0 +1,58 @@
+// Code generated by mockery v2.42.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	webhook_entity "github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+)
+
+// MockSender is an autogenerated mock type for the Sender type
+type MockSender struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, subscription, delivery
+func (_m *MockSender) Send(ctx context.Context, subscription webhook_entity.Subscription, delivery webhook_entity.Delivery) (int, error) {
+	ret := _m.Called(ctx, subscription, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, webhook_entity.Subscription, webhook_entity.Delivery) (int, error)); ok {
+		return rf(ctx, subscription, delivery)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, webhook_entity.Subscription, webhook_entity.Delivery) int); ok {
+		r0 = rf(ctx, subscription, delivery)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, webhook_entity.Subscription, webhook_entity.Delivery) error); ok {
+		r1 = rf(ctx, subscription, delivery)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockSender creates a new instance of MockSender. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSender(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSender {
+	mock := &MockSender{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider"
+)
+
+type Sender interface {
+	Send(ctx context.Context, subscription webhook_entity.Subscription, delivery webhook_entity.Delivery) (int, error)
+}
+
+type HttpSender struct {
+	client       *http.Client
+	timeProvider provider.TimeProvider
+}
+
+func NewHttpSender(timeout time.Duration, timeProvider provider.TimeProvider) *HttpSender {
+	return &HttpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			// a redirect could take the signed payload somewhere else
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		timeProvider: timeProvider,
+	}
+}
+
+// Send posts the delivery to the subscription, returning the status code of
+// the response and an error unless it is a 2xx one
+func (s *HttpSender) Send(ctx context.Context, subscription webhook_entity.Subscription, delivery webhook_entity.Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventId, delivery.EventId)
+	req.Header.Set(HeaderEventType, string(delivery.EventType))
+	req.Header.Set(HeaderDeliveryId, delivery.Id)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, s.timeProvider.GetTime(), body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// draining the body lets the connection be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
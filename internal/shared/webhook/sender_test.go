@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jfelipearaujo-org/ms-order-management/internal/entity/webhook_entity"
+	"github.com/jfelipearaujo-org/ms-order-management/internal/provider/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	t.Run("Should post the signed delivery", func(t *testing.T) {
+		// Arrange
+		now := time.Now()
+
+		var received *http.Request
+		var body []byte
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		timeProvider := mocks.NewMockTimeProvider(t)
+		timeProvider.On("GetTime").
+			Return(now).
+			Once()
+
+		subscription := webhook_entity.NewSubscription(server.URL, "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, now)
+
+		delivery := webhook_entity.Delivery{
+			Id:        "delivery-id",
+			EventId:   "event-id",
+			EventType: webhook_entity.OrderStateChanged,
+			Payload:   `{"id":"event-id"}`,
+		}
+
+		sender := NewHttpSender(time.Second, timeProvider)
+
+		// Act
+		statusCode, err := sender.Send(context.Background(), subscription, delivery)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, statusCode)
+		assert.Equal(t, `{"id":"event-id"}`, string(body))
+		assert.Equal(t, "event-id", received.Header.Get(HeaderEventId))
+		assert.Equal(t, "order.state_changed", received.Header.Get(HeaderEventType))
+		assert.Equal(t, "delivery-id", received.Header.Get(HeaderDeliveryId))
+		assert.Equal(t, Sign("secret", now, body), received.Header.Get(HeaderSignature))
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should return error when the subscriber does not acknowledge the delivery", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		timeProvider := mocks.NewMockTimeProvider(t)
+		timeProvider.On("GetTime").
+			Return(time.Now()).
+			Once()
+
+		subscription := webhook_entity.NewSubscription(server.URL, "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		sender := NewHttpSender(time.Second, timeProvider)
+
+		// Act
+		statusCode, err := sender.Send(context.Background(), subscription, webhook_entity.Delivery{Payload: "{}"})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		timeProvider.AssertExpectations(t)
+	})
+
+	t.Run("Should not follow redirects", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "https://elsewhere.com", http.StatusFound)
+		}))
+		defer server.Close()
+
+		timeProvider := mocks.NewMockTimeProvider(t)
+		timeProvider.On("GetTime").
+			Return(time.Now()).
+			Once()
+
+		subscription := webhook_entity.NewSubscription(server.URL, "secret", []webhook_entity.EventType{webhook_entity.OrderStateChanged}, time.Now())
+
+		sender := NewHttpSender(time.Second, timeProvider)
+
+		// Act
+		statusCode, err := sender.Send(context.Background(), subscription, webhook_entity.Delivery{Payload: "{}"})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusFound, statusCode)
+		timeProvider.AssertExpectations(t)
+	})
+}
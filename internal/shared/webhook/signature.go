@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	HeaderSignature  = "X-Webhook-Signature"
+	HeaderEventId    = "X-Webhook-Id"
+	HeaderEventType  = "X-Webhook-Event"
+	HeaderDeliveryId = "X-Webhook-Delivery"
+)
+
+// Sign returns the signature header of the body, the HMAC-SHA256 of the
+// timestamp and the body joined by a dot, so a captured request can not be
+// replayed later with another timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := timestamp.Unix()
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", unix)
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", unix, hex.EncodeToString(mac.Sum(nil)))
+}
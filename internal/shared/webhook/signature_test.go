@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	t.Run("Should sign the timestamp and the body", func(t *testing.T) {
+		// Arrange
+		timestamp := time.Unix(1700000000, 0)
+
+		// Act
+		signature := Sign("secret", timestamp, []byte(`{"id":"123"}`))
+
+		// Assert
+		assert.Equal(t, "t=1700000000,v1=d628a5c5b122e7a658c1412c6488de21ba23b0fe8c377519d4e2387ffa28337e", signature)
+	})
+
+	t.Run("Should change the signature with the secret", func(t *testing.T) {
+		// Arrange
+		timestamp := time.Unix(1700000000, 0)
+
+		// Act
+		signature := Sign("secret", timestamp, []byte(`{"id":"123"}`))
+		other := Sign("other", timestamp, []byte(`{"id":"123"}`))
+
+		// Assert
+		assert.NotEqual(t, signature, other)
+	})
+}
@@ -3,4 +3,10 @@
 echo "Initializing SNS topics..."
 
 awslocal sns create-topic \
-    --name OrderPaymentTopic
\ No newline at end of file
+    --name OrderPaymentTopic
+
+awslocal sns create-topic \
+    --name OrderEventsTopic
+
+awslocal sns create-topic \
+    --name PaymentResponseTopic
\ No newline at end of file
@@ -3,4 +3,7 @@
 echo "Initializing SQS queues..."
 
 awslocal sqs create-queue \
-    --queue-name UpdateOrderQueue
\ No newline at end of file
+    --queue-name UpdateOrderQueue
+
+awslocal sqs create-queue \
+    --queue-name UpdateOrderDLQ
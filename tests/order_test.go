@@ -318,6 +318,8 @@ func createApiContainer(ctx context.Context, network *testcontainers.DockerNetwo
 				"API_VERSION":                  "v1",
 				"DB_URL":                       "todo",
 				"DB_URL_SECRET_NAME":           "db-secret-url",
+				"DB_AUTO_MIGRATE":              "true",
+				"AUTH_HMAC_SECRET":             "my-secret",
 				"AWS_ACCESS_KEY_ID":            "test",
 				"AWS_SECRET_ACCESS_KEY":        "test",
 				"AWS_REGION":                   "us-east-1",
@@ -454,16 +456,6 @@ func createLocalstackContainer(ctx context.Context, network *testcontainers.Dock
 }
 
 func createPostgresContainer(ctx context.Context, network *testcontainers.DockerNetwork) (testcontainers.Container, context.Context, error) {
-	dbScript, err := filepath.Abs(filepath.Join(".", "testdata", "init-db.sql"))
-	if err != nil {
-		return nil, ctx, err
-	}
-
-	dbScriptReader, err := os.Open(dbScript)
-	if err != nil {
-		return nil, ctx, err
-	}
-
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
 			Image: "postgres:16.0",
@@ -483,13 +475,6 @@ func createPostgresContainer(ctx context.Context, network *testcontainers.Docker
 					"test",
 				},
 			},
-			Files: []testcontainers.ContainerFile{
-				{
-					Reader:            dbScriptReader,
-					ContainerFilePath: "/docker-entrypoint-initdb.d/init.sql",
-					FileMode:          0644,
-				},
-			},
 			WaitingFor: wait.ForLog("PostgreSQL init process complete; ready for start up").WithStartupTimeout(120 * time.Second),
 		},
 		Started: true,
@@ -3,4 +3,7 @@
 echo "Initializing SNS topics..."
 
 awslocal sns create-topic \
-    --name OrderPaymentTopic
\ No newline at end of file
+    --name OrderPaymentTopic
+
+awslocal sns create-topic \
+    --name OrderEventsTopic
\ No newline at end of file